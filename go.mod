@@ -1,3 +1,10 @@
 module github.com/go-stuff/tiled
 
-go 1.12
+go 1.18
+
+require (
+	github.com/klauspost/compress v1.15.15
+	golang.org/x/image v0.18.0
+)
+
+require golang.org/x/text v0.16.0 // indirect
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Animation structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#animation
@@ -19,6 +20,43 @@ type Animation struct {
 	Frame []*Frame `xml:"frame"`
 }
 
+// Duration returns the time it takes to play every frame of the animation once.
+func (a *Animation) Duration() time.Duration {
+	var total time.Duration
+	for _, frame := range a.Frame {
+		total += frame.duration()
+	}
+	return total
+}
+
+// FrameAt returns the frame shown at a time since the animation started, the animation loops forever. Animations
+// without a duration show their first frame, and animations without frames return nil.
+func (a *Animation) FrameAt(t time.Duration) *Frame {
+	if len(a.Frame) == 0 {
+		return nil
+	}
+
+	total := a.Duration()
+	if total <= 0 {
+		return a.Frame[0]
+	}
+
+	t %= total
+	if t < 0 {
+		t += total
+	}
+
+	for _, frame := range a.Frame {
+		d := frame.duration()
+		if t < d {
+			return frame
+		}
+		t -= d
+	}
+
+	return a.Frame[len(a.Frame)-1]
+}
+
 func (a *Animation) String() string {
 	var b strings.Builder
 
@@ -0,0 +1,177 @@
+package tmx
+
+import (
+	"sort"
+	"time"
+)
+
+// AnimationClock plays the tile animations of a set of tilesets. Every animated tile is driven by the same clock, so
+// all the places a tile is used show the same frame, the way Tiled previews animations. Advance the clock once per
+// game frame, then look up the tile to draw for each tile with TileID or GID.
+type AnimationClock struct {
+	// The time played since the clock started.
+	elapsed time.Duration
+
+	// The animations of each tileset.
+	tilesets map[*Tileset]*tilesetAnimations
+
+	// The global tile ID shown for each global tile ID, 0 for tiles that are not animated, so GID does not search the
+	// tilesets.
+	gids []GID
+}
+
+// tilesetAnimations are the animated tiles of a tileset, with the tile shown by each of them.
+type tilesetAnimations struct {
+	// The animated tiles of the tileset.
+	tiles []*Tile
+
+	// The local tile ID shown for each local tile ID, -1 for tiles that are not animated.
+	current []int
+
+	// The first global tile ID of the tileset when the clock was made, 0 for tilesets that are not part of a map.
+	firstGID int
+}
+
+// NewAnimationClock returns a clock for the animated tiles of the tilesets, showing the first frame of each animation.
+func NewAnimationClock(tilesets ...*Tileset) *AnimationClock {
+	c := &AnimationClock{tilesets: make(map[*Tileset]*tilesetAnimations, len(tilesets))}
+
+	for _, tileset := range tilesets {
+		a := &tilesetAnimations{firstGID: tileset.FirstGID}
+
+		for _, tile := range tileset.Tile {
+			if tile.Animation == nil || len(tile.Animation.Frame) == 0 || tile.ID < 0 {
+				continue
+			}
+			a.tiles = append(a.tiles, tile)
+
+			for len(a.current) <= tile.ID {
+				a.current = append(a.current, -1)
+			}
+		}
+
+		if len(a.tiles) > 0 {
+			c.tilesets[tileset] = a
+
+			if a.firstGID > 0 && a.firstGID+len(a.current) > len(c.gids) {
+				c.gids = append(c.gids, make([]GID, a.firstGID+len(a.current)-len(c.gids))...)
+			}
+		}
+	}
+
+	c.update()
+
+	return c
+}
+
+// AnimationClock returns a clock for the animated tiles of every tileset of the map, including the tilesets of
+// object templates.
+func (m *Map) AnimationClock() *AnimationClock {
+	return NewAnimationClock(m.tilesets...)
+}
+
+// Advance moves the clock forward by d, every animation moves to the frame shown at the new time.
+func (c *AnimationClock) Advance(d time.Duration) {
+	c.elapsed += d
+	c.update()
+}
+
+// Reset moves the clock back to the start, showing the first frame of every animation.
+func (c *AnimationClock) Reset() {
+	c.elapsed = 0
+	c.update()
+}
+
+// Elapsed returns the time played since the clock started.
+func (c *AnimationClock) Elapsed() time.Duration {
+	return c.elapsed
+}
+
+// TileID returns the local tile ID shown for a tile of a tileset, the tile of the current frame for animated tiles and
+// the tile itself for tiles that are not animated.
+func (c *AnimationClock) TileID(tileset *Tileset, id int) int {
+	a := c.tilesets[tileset]
+	if a == nil || id < 0 || id >= len(a.current) || a.current[id] < 0 {
+		return id
+	}
+	return a.current[id]
+}
+
+// GID returns the global tile ID shown for a global tile ID of the map the tilesets of the clock belong to, the tile of
+// the current frame for animated tiles. The flip flags are kept. It is a single table lookup, so it can be called for
+// every tile drawn.
+func (c *AnimationClock) GID(gid GID) GID {
+	id := gid.ID()
+	if int(id) >= len(c.gids) || c.gids[id] == 0 {
+		return gid
+	}
+	return c.gids[id] | gid.Flags()
+}
+
+// TileIDs returns the local tile ID shown for every animated tile of a tileset, by the local tile ID of the animated
+// tile.
+func (c *AnimationClock) TileIDs(tileset *Tileset) map[int]int {
+	a := c.tilesets[tileset]
+	if a == nil {
+		return nil
+	}
+
+	ids := make(map[int]int, len(a.tiles))
+	for _, tile := range a.tiles {
+		ids[tile.ID] = a.current[tile.ID]
+	}
+
+	return ids
+}
+
+// update sets the tile shown by every animated tile to the frame at the elapsed time.
+func (c *AnimationClock) update() {
+	for _, a := range c.tilesets {
+		for _, tile := range a.tiles {
+			a.current[tile.ID] = tile.Animation.FrameAt(c.elapsed).TileID
+
+			if a.firstGID > 0 {
+				c.gids[a.firstGID+tile.ID] = GID(a.firstGID + a.current[tile.ID])
+			}
+		}
+	}
+}
+
+// Changes returns the times within start and end at which any animation of the clock moves to another frame, in
+// order.
+func (c *AnimationClock) Changes(start time.Duration, end time.Duration) []time.Duration {
+	seen := make(map[time.Duration]bool)
+	var times []time.Duration
+
+	for _, a := range c.tilesets {
+		for _, tile := range a.tiles {
+			total := tile.Animation.Duration()
+			if total <= 0 || len(tile.Animation.Frame) < 2 {
+				continue
+			}
+
+			// The start of the loop the window starts in.
+			loop := start - start%total
+			if start%total < 0 {
+				loop -= total
+			}
+
+			for ; loop < end; loop += total {
+				t := loop
+				for _, frame := range tile.Animation.Frame {
+					if t > start && t < end && !seen[t] {
+						seen[t] = true
+						times = append(times, t)
+					}
+					t += frame.duration()
+				}
+			}
+		}
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+
+	return times
+}
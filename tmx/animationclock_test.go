@@ -0,0 +1,164 @@
+package tmx
+
+import (
+	"testing"
+	"time"
+)
+
+// animatedTileset returns a tileset starting at global tile ID 5, where tile 0 shows tile 1 for 100ms and then tile 2
+// for 200ms, and tile 3 is not animated.
+func animatedTileset() *Tileset {
+	return &Tileset{
+		FirstGID:  5,
+		TileCount: 4,
+		Tile: []*Tile{
+			{ID: 0, Animation: &Animation{Frame: []*Frame{{TileID: 1, Duration: 100}, {TileID: 2, Duration: 200}}}},
+			{ID: 3},
+		},
+	}
+}
+
+func TestAnimationClock(t *testing.T) {
+	tileset := animatedTileset()
+	clock := NewAnimationClock(tileset)
+
+	tests := []struct {
+		advance time.Duration
+		tileID  int
+	}{
+		{0, 1},
+		{99 * time.Millisecond, 1},
+		{time.Millisecond, 2},
+		{199 * time.Millisecond, 2},
+		{time.Millisecond, 1},
+		{250 * time.Millisecond, 2},
+	}
+
+	for _, test := range tests {
+		clock.Advance(test.advance)
+
+		if got := clock.TileID(tileset, 0); got != test.tileID {
+			t.Errorf("at %v: TileID = %d, want %d", clock.Elapsed(), got, test.tileID)
+		}
+
+		want := GID(tileset.FirstGID+test.tileID) | FlippedHorizontallyFlag
+		if got := clock.GID(5 | FlippedHorizontallyFlag); got != want {
+			t.Errorf("at %v: GID = %#x, want %#x", clock.Elapsed(), uint32(got), uint32(want))
+		}
+	}
+
+	// Tiles that are not animated, and tiles of other tilesets, are shown as they are.
+	for _, gid := range []GID{0, 6, 8, 100, 8 | FlippedVerticallyFlag} {
+		if got := clock.GID(gid); got != gid {
+			t.Errorf("GID(%#x) = %#x, want it unchanged", uint32(gid), uint32(got))
+		}
+	}
+	if got := clock.TileID(tileset, 3); got != 3 {
+		t.Errorf("TileID of a tile that is not animated = %d, want 3", got)
+	}
+
+	clock.Reset()
+	if got := clock.TileIDs(tileset); len(got) != 1 || got[0] != 1 {
+		t.Errorf("TileIDs after Reset = %v, want map[0:1]", got)
+	}
+}
+
+func TestAnimationClockGIDAllocations(t *testing.T) {
+	clock := NewAnimationClock(animatedTileset())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		clock.GID(5)
+		clock.GID(7)
+	})
+	if allocs != 0 {
+		t.Errorf("GID allocates %v times per call, want 0", allocs)
+	}
+}
+
+func TestAnimationClockChanges(t *testing.T) {
+	clock := NewAnimationClock(animatedTileset())
+
+	got := clock.Changes(0, 600*time.Millisecond)
+	want := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 400 * time.Millisecond}
+	if len(got) != len(want) {
+		t.Fatalf("Changes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Changes = %v, want %v", got, want)
+		}
+	}
+}
+
+func BenchmarkAnimationClockGID(b *testing.B) {
+	clock := NewAnimationClock(animatedTileset())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		clock.GID(5 | FlippedDiagonallyFlag)
+	}
+}
+
+func TestMapAnimationClock(t *testing.T) {
+	files := memoryFiles()
+	files["tilesets/terrain.tsx"] = []byte(`<tileset name="terrain" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="terrain.png" width="32" height="32"/>
+ <tile id="0">
+  <animation>
+   <frame tileid="0" duration="100"/>
+   <frame tileid="3" duration="100"/>
+  </animation>
+ </tile>
+</tileset>`)
+	// The chest of the template is tile 1 of items, which opens after 300ms.
+	files["tilesets/items.tsx"] = []byte(`<tileset name="items" tilewidth="16" tileheight="16" tilecount="4" columns="4">
+ <image source="items.png" width="64" height="16"/>
+ <tile id="1">
+  <animation>
+   <frame tileid="1" duration="300"/>
+   <frame tileid="2" duration="300"/>
+  </animation>
+ </tile>
+</tileset>`)
+
+	tmx, err := LoadTMXResolver(files, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := tmx.Map.AnimationClock()
+
+	// The terrain tiles are global tile IDs 1 to 4, the template tileset follows them from 5.
+	tests := []struct {
+		advance time.Duration
+		elapsed time.Duration
+		terrain GID
+		chest   GID
+	}{
+		{0, 0, 1, 6},
+		{100 * time.Millisecond, 100 * time.Millisecond, 4, 6},
+		{250 * time.Millisecond, 350 * time.Millisecond, 4, 7},
+		{250 * time.Millisecond, 600 * time.Millisecond, 1, 6},
+	}
+
+	for _, test := range tests {
+		clock.Advance(test.advance)
+
+		if clock.Elapsed() != test.elapsed {
+			t.Errorf("Elapsed = %v, want %v", clock.Elapsed(), test.elapsed)
+		}
+		if got := clock.GID(1); got != test.terrain {
+			t.Errorf("at %v: GID(1) = %d, want %d", test.elapsed, got, test.terrain)
+		}
+		if got := clock.GID(6 | FlippedDiagonallyFlag); got != test.chest|FlippedDiagonallyFlag {
+			t.Errorf("at %v: GID(6) = %v, want %v", test.elapsed, got, test.chest|FlippedDiagonallyFlag)
+		}
+		if got := clock.GID(2); got != 2 {
+			t.Errorf("at %v: GID(2) = %d, want the tile that is not animated", test.elapsed, got)
+		}
+	}
+
+	clock.Reset()
+	if clock.Elapsed() != 0 || clock.GID(6) != 6 {
+		t.Errorf("after Reset: Elapsed = %v and GID(6) = %d, want 0 and 6", clock.Elapsed(), clock.GID(6))
+	}
+}
@@ -0,0 +1,127 @@
+package tmx
+
+import (
+	"math/rand"
+)
+
+// Autotile picks a Wang tile of the set for every cell of a grid, matching the colors of the corners and edges around
+// the cell. Corners are ignored by edge sets and edges by corner sets. When no tile matches exactly, the tiles with the
+// fewest wrong colors are used. Among the tiles that fit equally well, one is picked at random with rnd, weighted by
+// the probability of the tile multiplied by the probability of each of its colors, the same weights Tiled uses. A nil
+// rnd always picks the most likely tile, which makes the result repeatable.
+//
+// The global tile IDs are returned row by row, Width * Height in length, ready to be used as Layer.GID. The tileset
+// must be the tileset of the Wang set as used by a map, so its FirstGID is set. Cells get 0 when the set has no tiles.
+func (w *Wangset) Autotile(tileset *Tileset, g *WangGrid, rnd *rand.Rand) []GID {
+	gids := make([]GID, g.Width*g.Height)
+
+	weights := make([]float64, len(w.WangTile))
+	for i, tile := range w.WangTile {
+		weights[i] = w.weight(tileset, tile)
+	}
+
+	var candidates []int
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			want := w.mask(g.WangID(x, y))
+
+			candidates = candidates[:0]
+			best := -1
+			for i, tile := range w.WangTile {
+				n := wangMismatches(want, w.mask(tile.WangID))
+				if best >= 0 && n > best {
+					continue
+				}
+				if n < best || best < 0 {
+					best = n
+					candidates = candidates[:0]
+				}
+				candidates = append(candidates, i)
+			}
+
+			if len(candidates) == 0 {
+				continue
+			}
+
+			i := pickWeighted(candidates, weights, rnd)
+			gids[y*g.Width+x] = w.WangTile[i].GID(tileset)
+		}
+	}
+
+	return gids
+}
+
+// mask clears the indexes of a Wang ID that the type of the set does not use.
+func (w *Wangset) mask(id WangID) WangID {
+	for i := range id {
+		if (w.Type == WangsetCorner && IsEdge(i)) || (w.Type == WangsetEdge && !IsEdge(i)) {
+			id[i] = 0
+		}
+	}
+	return id
+}
+
+// weight returns the relative probability of a Wang tile, the probability of the tile multiplied by the probability
+// of each color it uses.
+func (w *Wangset) weight(tileset *Tileset, tile *WangTile) float64 {
+	p := 1.0
+	if t := tileset.TileByID(tile.TileID); t != nil {
+		p = float64(t.Probability)
+	}
+
+	for _, index := range w.mask(tile.WangID) {
+		if color := w.Color(index); color != nil {
+			p *= color.Probability
+		}
+	}
+
+	return p
+}
+
+// wangMismatches counts the colors of a Wang ID that differ from the wanted colors, wanted colors of 0 allow any.
+func wangMismatches(want WangID, id WangID) int {
+	n := 0
+	for i := range want {
+		if want[i] != 0 && id[i] != want[i] {
+			n++
+		}
+	}
+	return n
+}
+
+// pickWeighted picks one of the candidates at random, weighted by their weight. A nil rnd picks the first candidate
+// with the highest weight.
+func pickWeighted(candidates []int, weights []float64, rnd *rand.Rand) int {
+	if rnd == nil {
+		best := candidates[0]
+		for _, i := range candidates[1:] {
+			if weights[i] > weights[best] {
+				best = i
+			}
+		}
+		return best
+	}
+
+	total := 0.0
+	for _, i := range candidates {
+		if weights[i] > 0 {
+			total += weights[i]
+		}
+	}
+	if total <= 0 {
+		return candidates[rnd.Intn(len(candidates))]
+	}
+
+	r := rnd.Float64() * total
+	for _, i := range candidates {
+		if weights[i] <= 0 {
+			continue
+		}
+		r -= weights[i]
+		if r < 0 {
+			return i
+		}
+	}
+
+	return candidates[len(candidates)-1]
+}
@@ -0,0 +1,120 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"math/rand"
+	"testing"
+)
+
+// wangTileset has a corner set of grass and water and an edge set of roads through grass. Tile 3 is grass that is
+// three times as likely as tile 0, and tile 5 is stored flipped.
+const wangTileset = `<tileset firstgid="10" name="wang" tilewidth="16" tileheight="16" tilecount="6" columns="6">
+ <image source="wang.png" width="96" height="16"/>
+ <tile id="3" probability="3"/>
+ <wangsets>
+  <wangset name="terrain" type="corner" tile="-1">
+   <wangcolor name="grass" color="#00ff00" tile="-1" probability="1"/>
+   <wangcolor name="water" color="#0000ff" tile="-1" probability="1"/>
+   <wangtile tileid="0" wangid="0,1,0,1,0,1,0,1"/>
+   <wangtile tileid="1" wangid="0,2,0,2,0,2,0,2"/>
+   <wangtile tileid="2" wangid="0,1,0,2,0,1,0,1"/>
+   <wangtile tileid="3" wangid="0,1,0,1,0,1,0,1"/>
+  </wangset>
+  <wangset name="roads" type="edge" tile="-1">
+   <wangcolor name="road" color="#808080" tile="-1" probability="1"/>
+   <wangcolor name="grass" color="#00ff00" tile="-1" probability="1"/>
+   <wangtile tileid="4" wangid="1,0,1,0,1,0,1,0"/>
+   <wangtile tileid="5" wangid="2,0,1,0,2,0,1,0" hflip="1"/>
+  </wangset>
+ </wangsets>
+</tileset>`
+
+// loadWangTileset unmarshals wangTileset.
+func loadWangTileset(t *testing.T) *Tileset {
+	t.Helper()
+
+	tileset := &Tileset{}
+	err := xml.Unmarshal([]byte(wangTileset), tileset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tileset.Wangsets == nil || len(tileset.Wangsets.Wangset) != 2 {
+		t.Fatal("tileset does not have two wang sets")
+	}
+	return tileset
+}
+
+func TestAutotile(t *testing.T) {
+	tileset := loadWangTileset(t)
+	w := tileset.Wangsets.Wangset[0]
+
+	// Water on the bottom-right corner of the first cell, grass on every other corner.
+	g := NewWangGrid(2, 1)
+	g.SetCell(0, 0, 1)
+	g.SetCell(1, 0, 1)
+	g.SetCorner(1, 1, 2)
+
+	// The second cell has no exact match, the grass tiles are one corner off. The more likely of them is picked.
+	gids := w.Autotile(tileset, g, nil)
+	if want := []GID{12, 13}; !equalGIDs(gids, want) {
+		t.Errorf("Autotile = %v, want %v", gids, want)
+	}
+}
+
+func TestAutotileEdges(t *testing.T) {
+	tileset := loadWangTileset(t)
+	w := tileset.Wangsets.Wangset[1]
+
+	// A road from left to right through grass, the corners are ignored by an edge set.
+	g := NewWangGrid(1, 1)
+	g.SetCell(0, 0, 2)
+	g.SetVerticalEdge(0, 0, 1)
+	g.SetVerticalEdge(1, 0, 1)
+
+	gids := w.Autotile(tileset, g, nil)
+	if want := 15 | FlippedHorizontallyFlag; len(gids) != 1 || gids[0] != want {
+		t.Errorf("Autotile = %v, want %v", gids, want)
+	}
+}
+
+func TestAutotileProbability(t *testing.T) {
+	tileset := loadWangTileset(t)
+	w := tileset.Wangsets.Wangset[0]
+
+	g := NewWangGrid(40, 100)
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			g.SetCell(x, y, 1)
+		}
+	}
+
+	// The same seed fills the grid the same way.
+	gids := w.Autotile(tileset, g, rand.New(rand.NewSource(1)))
+	if again := w.Autotile(tileset, g, rand.New(rand.NewSource(1))); !equalGIDs(gids, again) {
+		t.Error("Autotile with the same seed returned different tiles")
+	}
+
+	counts := map[GID]int{}
+	for _, gid := range gids {
+		counts[gid]++
+	}
+	if counts[10]+counts[13] != len(gids) {
+		t.Fatalf("picked %v, want only the grass tiles 10 and 13", counts)
+	}
+
+	// Tile 3 is three times as likely as tile 0.
+	ratio := float64(counts[13]) / float64(counts[10])
+	if ratio < 2.5 || ratio > 3.5 {
+		t.Errorf("picked tile 3 %d times and tile 0 %d times, want about three times as often", counts[13],
+			counts[10])
+	}
+}
+
+func TestAutotileEmptySet(t *testing.T) {
+	tileset := loadWangTileset(t)
+
+	gids := (&Wangset{Type: WangsetCorner}).Autotile(tileset, NewWangGrid(2, 2), nil)
+	if want := []GID{0, 0, 0, 0}; !equalGIDs(gids, want) {
+		t.Errorf("Autotile of a set without tiles = %v, want %v", gids, want)
+	}
+}
@@ -3,12 +3,14 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"image"
 	"strings"
 )
 
 // Chunk structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#chunk
 type Chunk struct {
-	XMLName xml.Name `xml:"chunk"`
+	XMLName  xml.Name `xml:"chunk"`
+	InnerXML string   `xml:",innerxml"`
 
 	// The x coordinate of the chunk in tiles.
 	X int `xml:"x,attr"`
@@ -24,16 +26,46 @@ type Chunk struct {
 
 	// Can contain: <tile>
 	Tile []*LayerTile `xml:"tile"`
+
+	// The decoded global tile IDs of the chunk, row by row, Width * Height in length. Not part of the spec, it is
+	// populated from the chunk data when the layer is unmarshalled.
+	GID []GID `xml:"-"`
+}
+
+// MarshalXML is called by Marshal to produce the XML element, the raw InnerXML is written as the content.
+func (c *Chunk) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	return encoder.EncodeElement(struct {
+		X        int    `xml:"x,attr"`
+		Y        int    `xml:"y,attr"`
+		Width    int    `xml:"width,attr"`
+		Height   int    `xml:"height,attr"`
+		InnerXML string `xml:",innerxml"`
+	}{c.X, c.Y, c.Width, c.Height, c.InnerXML}, startElement("chunk", nil))
+}
+
+// Bounds returns the rectangle in tiles covered by the chunk.
+func (c *Chunk) Bounds() image.Rectangle {
+	return image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height)
+}
+
+// empty reports whether every tile of the chunk is empty.
+func (c *Chunk) empty() bool {
+	for _, gid := range c.GID {
+		if gid.ID() != 0 {
+			return false
+		}
+	}
+	return true
 }
 
 func (c *Chunk) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Chunk:\n")
-	fmt.Fprintf(&b, "\tX:      (%T) %q\n", c.X, c.X)
-	fmt.Fprintf(&b, "\tY:      (%T) %q\n", c.Y, c.Y)
-	fmt.Fprintf(&b, "\tWidth:  (%T) %q\n", c.Width, c.Width)
-	fmt.Fprintf(&b, "\tHeight: (%T) %q\n", c.Height, c.Height)
+	fmt.Fprintf(&b, "\tX:      (%T) %d\n", c.X, c.X)
+	fmt.Fprintf(&b, "\tY:      (%T) %d\n", c.Y, c.Y)
+	fmt.Fprintf(&b, "\tWidth:  (%T) %d\n", c.Width, c.Width)
+	fmt.Fprintf(&b, "\tHeight: (%T) %d\n", c.Height, c.Height)
 
 	for _, tile := range c.Tile {
 		fmt.Fprintf(&b, tile.String())
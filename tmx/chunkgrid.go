@@ -0,0 +1,190 @@
+package tmx
+
+import (
+	"fmt"
+	"image"
+	"sort"
+)
+
+// ChunkGrid is the sparse TileGrid of a tile layer of an infinite map, made of the chunks of the layer. Tiled saves
+// the chunks of a layer with the same size, aligned to multiples of that size, which lets a tile find its chunk with a
+// single map lookup. Chunks that are not aligned are found by searching every chunk.
+type ChunkGrid struct {
+	// The chunks that hold at least one tile, sorted row by row.
+	chunks []*Chunk
+
+	// The chunks by their position in chunks, when every chunk is aligned.
+	index map[image.Point]*Chunk
+
+	// The size of every chunk, used to find the position of a tile in chunks.
+	chunkWidth  int
+	chunkHeight int
+
+	// The rectangle in tiles that contains every chunk.
+	bounds image.Rectangle
+}
+
+// NewChunkGrid returns the grid of a set of chunks, with their GID already decoded. Empty chunks are left out.
+func NewChunkGrid(chunks []*Chunk) *ChunkGrid {
+	g := &ChunkGrid{}
+
+	for _, chunk := range chunks {
+		if chunk.Width <= 0 || chunk.Height <= 0 || chunk.empty() {
+			continue
+		}
+		g.chunks = append(g.chunks, chunk)
+	}
+
+	sort.SliceStable(g.chunks, func(i, j int) bool {
+		a, b := g.chunks[i], g.chunks[j]
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+
+	for i, chunk := range g.chunks {
+		r := chunk.Bounds()
+		if i == 0 {
+			g.bounds = r
+		}
+		g.bounds = g.bounds.Union(r)
+	}
+
+	if len(g.chunks) > 0 {
+		g.chunkWidth, g.chunkHeight = g.chunks[0].Width, g.chunks[0].Height
+		g.index = make(map[image.Point]*Chunk, len(g.chunks))
+
+		for _, chunk := range g.chunks {
+			aligned := chunk.Width == g.chunkWidth && chunk.Height == g.chunkHeight &&
+				chunk.X%g.chunkWidth == 0 && chunk.Y%g.chunkHeight == 0
+			if !aligned {
+				g.index = nil
+				break
+			}
+			g.index[image.Pt(chunk.X/g.chunkWidth, chunk.Y/g.chunkHeight)] = chunk
+		}
+	}
+
+	return g
+}
+
+// GID returns the global tile ID at a position in tiles, with its flip flags. Positions can be negative, and 0 is
+// returned for empty cells and positions outside every chunk.
+func (g *ChunkGrid) GID(x int, y int) GID {
+	chunk := g.Chunk(x, y)
+	if chunk == nil {
+		return 0
+	}
+
+	i := (y-chunk.Y)*chunk.Width + (x - chunk.X)
+	if i >= len(chunk.GID) {
+		return 0
+	}
+
+	return chunk.GID[i]
+}
+
+// Chunk returns the chunk that contains a position in tiles, nil when there is none.
+func (g *ChunkGrid) Chunk(x int, y int) *Chunk {
+	if g.index != nil {
+		return g.index[image.Pt(floorDiv(x, g.chunkWidth), floorDiv(y, g.chunkHeight))]
+	}
+
+	p := image.Pt(x, y)
+	for _, chunk := range g.chunks {
+		if p.In(chunk.Bounds()) {
+			return chunk
+		}
+	}
+
+	return nil
+}
+
+// Chunks returns the chunks that hold at least one tile, row by row.
+func (g *ChunkGrid) Chunks() []*Chunk {
+	return g.chunks
+}
+
+// Bounds returns the rectangle in tiles that contains every chunk holding a tile, the empty rectangle when there are
+// none.
+func (g *ChunkGrid) Bounds() image.Rectangle {
+	return g.bounds
+}
+
+// EachTile calls fn with the position in tiles and global tile ID of every tile that is not empty, row by row from the
+// top of the grid and from left to right within each row, across the chunks the row passes through. Iteration stops at
+// the first error returned by fn, which is returned.
+func (g *ChunkGrid) EachTile(fn func(x int, y int, gid GID) error) error {
+	// The chunks the row passes through, from left to right, and the next chunk below them.
+	var active []*Chunk
+	next := 0
+
+	for y := g.bounds.Min.Y; y < g.bounds.Max.Y; y++ {
+		row := active[:0]
+		for _, chunk := range active {
+			if y < chunk.Y+chunk.Height {
+				row = append(row, chunk)
+			}
+		}
+		active = row
+
+		added := false
+		for next < len(g.chunks) && g.chunks[next].Y <= y {
+			active = append(active, g.chunks[next])
+			next++
+			added = true
+		}
+
+		if len(active) == 0 {
+			// Skip the rows between chunks.
+			if next < len(g.chunks) {
+				y = g.chunks[next].Y - 1
+			}
+			continue
+		}
+		if added {
+			sort.SliceStable(active, func(i, j int) bool {
+				return active[i].X < active[j].X
+			})
+		}
+
+		for _, chunk := range active {
+			start := (y - chunk.Y) * chunk.Width
+			for x := 0; x < chunk.Width && start+x < len(chunk.GID); x++ {
+				gid := chunk.GID[start+x]
+				if gid.ID() == 0 {
+					continue
+				}
+				err := fn(chunk.X+x, y, gid)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeChunks decodes the data of every chunk of the layer into Chunk.GID.
+func (l *Layer) decodeChunks() error {
+	for _, chunk := range l.Data.Chunk {
+		var err error
+		chunk.GID, err = decodeGIDs(l.Data.Encoding, l.Data.Compression, chunk.InnerXML, chunk.Tile,
+			chunk.Width*chunk.Height)
+		if err != nil {
+			return fmt.Errorf("error decoding layer %q chunk %d,%d: %w", l.Name, chunk.X, chunk.Y, err)
+		}
+	}
+	return nil
+}
+
+// floorDiv divides rounding towards negative infinity, so negative tiles fall in the chunk to their left or above.
+func floorDiv(a int, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
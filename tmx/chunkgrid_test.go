@@ -0,0 +1,156 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"image"
+	"testing"
+)
+
+// infiniteLayer is a layer of an infinite map with 2x2 chunks, one of them above and left of the origin and one empty.
+const infiniteLayer = `<layer id="1" name="ground" width="4" height="4">
+ <data encoding="csv">
+  <chunk x="-2" y="-2" width="2" height="2">1,2,
+3,0</chunk>
+  <chunk x="0" y="-2" width="2" height="2">0,4,
+5,0</chunk>
+  <chunk x="0" y="0" width="2" height="2">0,0,
+0,0</chunk>
+  <chunk x="2" y="0" width="2" height="2">6,0,
+0,7</chunk>
+ </data>
+</layer>`
+
+// tilePosition is a tile reported by EachTile.
+type tilePosition struct {
+	x   int
+	y   int
+	gid GID
+}
+
+// eachTile returns every tile of a grid in the order EachTile reports them.
+func eachTile(t *testing.T, g TileGrid) []tilePosition {
+	t.Helper()
+
+	var tiles []tilePosition
+	err := g.EachTile(func(x int, y int, gid GID) error {
+		tiles = append(tiles, tilePosition{x, y, gid})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tiles
+}
+
+func TestChunkGrid(t *testing.T) {
+	layer := &Layer{}
+	err := xml.Unmarshal([]byte(infiniteLayer), layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, ok := layer.TileGrid().(*ChunkGrid)
+	if !ok {
+		t.Fatalf("grid is %T, want *ChunkGrid", layer.TileGrid())
+	}
+	if layer.TileGrid() != TileGrid(g) {
+		t.Error("TileGrid built a new grid instead of returning the cached one")
+	}
+
+	if got, want := g.Bounds(), image.Rect(-2, -2, 4, 2); got != want {
+		t.Errorf("bounds %v, want %v", got, want)
+	}
+	if len(g.Chunks()) != 3 {
+		t.Errorf("found %d chunks, want the 3 that are not empty", len(g.Chunks()))
+	}
+
+	gids := []struct {
+		x   int
+		y   int
+		gid GID
+	}{
+		{-2, -2, 1},
+		{-1, -2, 2},
+		{-2, -1, 3},
+		{-1, -1, 0},
+		{1, -2, 4},
+		{0, -1, 5},
+		{2, 0, 6},
+		{3, 1, 7},
+		{0, 0, 0},
+		{-3, -3, 0},
+		{10, 10, 0},
+	}
+	for _, test := range gids {
+		if got := g.GID(test.x, test.y); got != test.gid {
+			t.Errorf("GID(%d, %d) = %d, want %d", test.x, test.y, got, test.gid)
+		}
+	}
+
+	// Tiles are reported row by row across chunks, not chunk by chunk.
+	want := []tilePosition{{-2, -2, 1}, {-1, -2, 2}, {1, -2, 4}, {-2, -1, 3}, {0, -1, 5}, {2, 0, 6}, {3, 1, 7}}
+	got := eachTile(t, g)
+	if len(got) != len(want) {
+		t.Fatalf("EachTile found %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("EachTile found %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestChunkGridUnaligned(t *testing.T) {
+	chunks := []*Chunk{
+		{X: 3, Y: 1, Width: 2, Height: 3, GID: []GID{1, 0, 0, 2, 3, 0}},
+		{X: -1, Y: 0, Width: 3, Height: 2, GID: []GID{4, 0, 5, 0, 6, 0}},
+	}
+	g := NewChunkGrid(chunks)
+
+	if got, want := g.Bounds(), image.Rect(-1, 0, 5, 4); got != want {
+		t.Errorf("bounds %v, want %v", got, want)
+	}
+	if got := g.GID(4, 2); got != 2 {
+		t.Errorf("GID(4, 2) = %d, want 2", got)
+	}
+
+	want := []tilePosition{{-1, 0, 4}, {1, 0, 5}, {0, 1, 6}, {3, 1, 1}, {4, 2, 2}, {3, 3, 3}}
+	got := eachTile(t, g)
+	if len(got) != len(want) {
+		t.Fatalf("EachTile found %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("EachTile found %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestLayerGrid(t *testing.T) {
+	layer := &Layer{X: 1, Y: 2, Width: 2, Height: 2, GID: []GID{1, 0, 0, 2}}
+	g := layer.TileGrid()
+
+	if got, want := g.Bounds(), image.Rect(1, 2, 3, 4); got != want {
+		t.Errorf("bounds %v, want %v", got, want)
+	}
+
+	// The grid reads the tiles of the layer, so tiles changed in place show without rebuilding it.
+	layer.GID[1] = 3
+	if got := g.GID(2, 2); got != 3 {
+		t.Errorf("GID(2, 2) = %d, want 3", got)
+	}
+
+	want := []tilePosition{{1, 2, 1}, {2, 2, 3}, {2, 3, 2}}
+	got := eachTile(t, g)
+	if len(got) != len(want) {
+		t.Fatalf("EachTile found %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("EachTile found %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package tmx
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// CollisionShape is a collision shape of a tile placed on a tile layer, drawn in the collision editor of Tiled and
+// moved to where the tile is on the map.
+type CollisionShape struct {
+	// The kind of shape, merged tiles are rectangles. Ellipses keep their kind, their vertices approximate them.
+	Shape ShapeKind
+
+	// The vertices of the shape in screen pixels, clockwise for rectangles, ellipses and polygons. The flip flags of
+	// the tile, the tile offset of the tileset and the offsets of the layer are applied. Screen pixels are the pixel
+	// positions of the map on every orientation except isometric, use Map.ScreenToPixel to convert them there.
+	Vertices []Vertex
+
+	// The object of the tile the shape was made from, nil for rectangles merged from several shapes.
+	Object *Object
+
+	// The global tile ID of the tile, with its flip flags, 0 for rectangles merged from several shapes.
+	GID GID
+
+	// The cells of the tile layer the shape belongs to, in tiles. Merged rectangles cover the bounding rectangle of
+	// the cells they were merged from.
+	Cells image.Rectangle
+}
+
+// CollisionShapes returns the collision shapes of every tile placed on a tile layer of the map, in screen pixels, for
+// every orientation. When merge is set, rectangles that are not rotated are merged with the rectangles of their
+// neighbours when they share a whole side or overlap along it, first along rows and then along columns, so physics
+// engines get far fewer bodies. This merges solid tiles into large rectangles, and strips such as the top half of a
+// row of platform tiles into a single rectangle. Only rectangle objects are merged: polygons are never merged, not
+// even when they are drawn as rectangles or touch the polygons of neighbouring tiles, and neither are ellipses, points
+// and rotated rectangles. They are returned for each tile. Merged rectangles come after the shapes that are not
+// merged.
+func (m *Map) CollisionShapes(layer *Layer, merge bool) ([]*CollisionShape, error) {
+	offsetX, offsetY, ok := layerOffset(m.Content, layer, 0, 0)
+	if !ok {
+		offsetX, offsetY = float64(layer.OffsetX), float64(layer.OffsetY)
+	}
+
+	var shapes []*CollisionShape
+	var rectangles []*CollisionShape
+
+	err := layer.TileGrid().EachTile(func(x int, y int, gid GID) error {
+		tileShapes, err := m.tileCollisionShapes(x, y, gid, offsetX, offsetY)
+		if err != nil {
+			return err
+		}
+
+		for _, shape := range tileShapes {
+			if merge && isAxisAligned(shape) {
+				rectangles = append(rectangles, shape)
+				continue
+			}
+			shapes = append(shapes, shape)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error finding collision shapes of layer %q: %w", layer.Name, err)
+	}
+
+	rectangles = mergeRectangles(rectangles, false)
+	rectangles = mergeRectangles(rectangles, true)
+
+	return append(shapes, rectangles...), nil
+}
+
+// tileCollisionShapes returns the collision shapes of the tile with a global tile ID placed at x, y in tiles.
+func (m *Map) tileCollisionShapes(x int, y int, gid GID, offsetX float64, offsetY float64) ([]*CollisionShape, error) {
+	ref, err := m.ResolveGID(gid)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Tile == nil || len(ref.Tile.ObjectGroup) == 0 {
+		return nil, nil
+	}
+
+	// On hexagonal maps the diagonal flag rotates the tile by 60 degrees around its center, the same way it is drawn.
+	hexagonal := m.Orientation == OrientationHexagonal
+	diagonal := gid.FlippedDiagonally() && !hexagonal
+	rotation := 0.0
+	if hexagonal {
+		if gid.FlippedDiagonally() {
+			rotation += 60
+		}
+		if gid.RotatedHexagonal120() {
+			rotation += 120
+		}
+	}
+	sin, cos := math.Sincos(rotation * math.Pi / 180)
+
+	// Tiles are aligned to the bottom-left of their cell, diagonally flipped tiles are drawn with their width and
+	// height swapped.
+	width, height := float64(ref.Rect.Dx()), float64(ref.Rect.Dy())
+	if diagonal {
+		width, height = height, width
+	}
+
+	originX, originY := m.TileRenderOrigin(x, y)
+	originX += offsetX
+	originY += offsetY - height
+	if ref.Tileset.TileOffset != nil {
+		originX += float64(ref.Tileset.TileOffset.X)
+		originY += float64(ref.Tileset.TileOffset.Y)
+	}
+
+	// Each flip mirrors the shape, an odd number of them turns clockwise vertices counterclockwise.
+	flips := 0
+	for _, flipped := range []bool{gid.FlippedHorizontally(), gid.FlippedVertically(), diagonal} {
+		if flipped {
+			flips++
+		}
+	}
+
+	var shapes []*CollisionShape
+	for _, objectGroup := range ref.Tile.ObjectGroup {
+		for _, object := range objectGroup.Object {
+			// Collision objects are in the orthogonal space of the tile the way the collision editor of Tiled shows
+			// them, tile objects among them are aligned to the bottom-left whatever the orientation of the map.
+			vertices := object.WorldVertices(0, 0)
+			for i, v := range vertices {
+				// The diagonal flip is done first, followed by the horizontal and vertical flips.
+				if diagonal {
+					v.X, v.Y = v.Y, v.X
+				}
+				if gid.FlippedHorizontally() {
+					v.X = width - v.X
+				}
+				if gid.FlippedVertically() {
+					v.Y = height - v.Y
+				}
+				if rotation != 0 {
+					dx, dy := v.X-width/2, v.Y-height/2
+					v.X, v.Y = width/2+dx*cos-dy*sin, height/2+dx*sin+dy*cos
+				}
+				vertices[i] = Vertex{X: originX + v.X, Y: originY + v.Y}
+			}
+
+			if flips%2 == 1 {
+				for i, j := 0, len(vertices)-1; i < j; i, j = i+1, j-1 {
+					vertices[i], vertices[j] = vertices[j], vertices[i]
+				}
+			}
+
+			shapes = append(shapes, &CollisionShape{
+				Shape:    object.Shape(),
+				Vertices: vertices,
+				Object:   object,
+				GID:      gid,
+				Cells:    image.Rect(x, y, x+1, y+1),
+			})
+		}
+	}
+
+	return shapes, nil
+}
+
+// isAxisAligned reports whether a collision shape is a rectangle that is not rotated.
+func isAxisAligned(shape *CollisionShape) bool {
+	if shape.Shape != ShapeRectangle && shape.Shape != ShapeTile || len(shape.Vertices) != 4 {
+		return false
+	}
+
+	minX, minY, maxX, maxY := bounds(shape.Vertices)
+	if near(minX, maxX) || near(minY, maxY) {
+		return false
+	}
+	for _, v := range shape.Vertices {
+		// Rotated rectangles have corners that are not on the bounds.
+		if !near(v.X, minX) && !near(v.X, maxX) || !near(v.Y, minY) && !near(v.Y, maxY) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mergeRectangles merges rectangles that are not rotated along rows, or along columns when vertical is set. Two
+// rectangles are merged when they cover the same band across the row or column and touch or overlap along it, their
+// union is then a rectangle too.
+func mergeRectangles(rectangles []*CollisionShape, vertical bool) []*CollisionShape {
+	type span struct {
+		shape      *CollisionShape
+		bandMin    float64
+		bandMax    float64
+		min        float64
+		max        float64
+		minX, minY float64
+		maxX, maxY float64
+	}
+
+	spans := make([]span, len(rectangles))
+	for i, shape := range rectangles {
+		minX, minY, maxX, maxY := bounds(shape.Vertices)
+		s := span{shape: shape, minX: minX, minY: minY, maxX: maxX, maxY: maxY}
+		s.bandMin, s.bandMax, s.min, s.max = minY, maxY, minX, maxX
+		if vertical {
+			s.bandMin, s.bandMax, s.min, s.max = minX, maxX, minY, maxY
+		}
+		spans[i] = s
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		a, b := spans[i], spans[j]
+		if !near(a.bandMin, b.bandMin) {
+			return a.bandMin < b.bandMin
+		}
+		if !near(a.bandMax, b.bandMax) {
+			return a.bandMax < b.bandMax
+		}
+		return a.min < b.min
+	})
+
+	var merged []*CollisionShape
+	for i := 0; i < len(spans); {
+		first := spans[i]
+		shape := first.shape
+		minX, minY, maxX, maxY := first.minX, first.minY, first.maxX, first.maxY
+		end := first.max
+
+		j := i + 1
+		for ; j < len(spans); j++ {
+			next := spans[j]
+			sameBand := near(next.bandMin, first.bandMin) && near(next.bandMax, first.bandMax)
+			if !sameBand || next.min > end && !near(next.min, end) {
+				break
+			}
+
+			end = math.Max(end, next.max)
+			minX, minY = math.Min(minX, next.minX), math.Min(minY, next.minY)
+			maxX, maxY = math.Max(maxX, next.maxX), math.Max(maxY, next.maxY)
+			shape = &CollisionShape{
+				Shape:    ShapeRectangle,
+				Vertices: []Vertex{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}},
+				Cells:    shape.Cells.Union(next.shape.Cells),
+			}
+		}
+
+		merged = append(merged, shape)
+		i = j
+	}
+
+	return merged
+}
+
+// bounds returns the bounding box of vertices.
+func bounds(vertices []Vertex) (float64, float64, float64, float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, v := range vertices {
+		minX, maxX = math.Min(minX, v.X), math.Max(maxX, v.X)
+		minY, maxY = math.Min(minY, v.Y), math.Max(maxY, v.Y)
+	}
+	return minX, minY, maxX, maxY
+}
+
+// near reports whether two pixel positions are equal, allowing for rounding errors from rotation.
+func near(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
@@ -0,0 +1,206 @@
+package tmx
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+// collisionMap returns the xml of a map using a tileset with collision shapes:
+//
+//	1: a rectangle covering the tile
+//	2: a rectangle covering the top half of the tile
+//	3: a triangle
+//	4: a rectangle covering the left half of the tile
+//	5: a rectangle covering the top-left 8x4 pixels of the tile
+func collisionMap(orientation string, width int, height int, data string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="%s" renderorder="right-down" width="%d" height="%d" tilewidth="16" tileheight="16" hexsidelength="8" staggeraxis="y" staggerindex="odd">
+ <tileset firstgid="1" name="collision" tilewidth="16" tileheight="16" tilecount="5" columns="5">
+  <image source="collision.png" width="80" height="16"/>
+  <tile id="0"><objectgroup><object id="1" x="0" y="0" width="16" height="16"/></objectgroup></tile>
+  <tile id="1"><objectgroup><object id="1" x="0" y="0" width="16" height="8"/></objectgroup></tile>
+  <tile id="2"><objectgroup><object id="1" x="0" y="0"><polygon points="0,0 16,16 0,16"/></object></objectgroup></tile>
+  <tile id="3"><objectgroup><object id="1" x="0" y="0" width="8" height="16"/></objectgroup></tile>
+  <tile id="4"><objectgroup><object id="1" x="0" y="0" width="8" height="4"/></objectgroup></tile>
+ </tileset>
+ <layer id="1" name="collision" width="%d" height="%d">
+  <data encoding="csv">%s</data>
+ </layer>
+</map>`, orientation, width, height, width, height, data)
+}
+
+// loadCollisionMap loads a map made by collisionMap and returns it with its tile layer.
+func loadCollisionMap(t *testing.T, orientation string, width int, height int, data string) (*Map, *Layer) {
+	t.Helper()
+
+	tmx, err := LoadTMXBytes([]byte(collisionMap(orientation, width, height, data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range tmx.Map.Content {
+		if layer, ok := c.Value.(*Layer); ok {
+			return tmx.Map, layer
+		}
+	}
+	t.Fatal("map has no tile layer")
+	return nil, nil
+}
+
+// signedArea returns the area of a polygon, positive when its vertices are clockwise on screen.
+func signedArea(vertices []Vertex) float64 {
+	var area float64
+	for i, v := range vertices {
+		next := vertices[(i+1)%len(vertices)]
+		area += v.X*next.Y - next.X*v.Y
+	}
+	return area / 2
+}
+
+func TestCollisionShapesFlips(t *testing.T) {
+	tests := []struct {
+		name   string
+		flags  GID
+		bounds [4]float64
+	}{
+		{"none", 0, [4]float64{0, 0, 8, 4}},
+		{"horizontal", FlippedHorizontallyFlag, [4]float64{8, 0, 16, 4}},
+		{"vertical", FlippedVerticallyFlag, [4]float64{0, 12, 8, 16}},
+		{"diagonal", FlippedDiagonallyFlag, [4]float64{0, 0, 4, 8}},
+		{"diagonal horizontal", FlippedDiagonallyFlag | FlippedHorizontallyFlag, [4]float64{12, 0, 16, 8}},
+		{"diagonal vertical", FlippedDiagonallyFlag | FlippedVerticallyFlag, [4]float64{0, 8, 4, 16}},
+		{"rotated 180", FlippedHorizontallyFlag | FlippedVerticallyFlag, [4]float64{8, 12, 16, 16}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, layer := loadCollisionMap(t, "orthogonal", 1, 1, fmt.Sprint(uint32(5|test.flags)))
+
+			shapes, err := m.CollisionShapes(layer, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(shapes) != 1 {
+				t.Fatalf("found %d shapes, want 1", len(shapes))
+			}
+
+			minX, minY, maxX, maxY := bounds(shapes[0].Vertices)
+			if got := [4]float64{minX, minY, maxX, maxY}; got != test.bounds {
+				t.Errorf("bounds %v, want %v", got, test.bounds)
+			}
+			if area := signedArea(shapes[0].Vertices); area <= 0 {
+				t.Errorf("vertices %v are not clockwise", shapes[0].Vertices)
+			}
+			if shapes[0].GID.ID() != 5 {
+				t.Errorf("gid %d, want 5", shapes[0].GID.ID())
+			}
+		})
+	}
+}
+
+func TestCollisionShapesMerge(t *testing.T) {
+	// Two solid tiles and two top halves on the first row, two triangles and a flipped left half on the second.
+	flippedLeftHalf := uint32(4 | FlippedHorizontallyFlag)
+	m, layer := loadCollisionMap(t, "orthogonal", 4, 2, fmt.Sprintf("1,1,2,2,\n3,3,0,%d", flippedLeftHalf))
+
+	shapes, err := m.CollisionShapes(layer, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 7 {
+		t.Fatalf("found %d shapes without merging, want 7", len(shapes))
+	}
+
+	shapes, err = m.CollisionShapes(layer, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		shape  ShapeKind
+		bounds [4]float64
+		cells  image.Rectangle
+	}{
+		// Polygons are never merged.
+		{ShapePolygon, [4]float64{0, 16, 16, 32}, image.Rect(0, 1, 1, 2)},
+		{ShapePolygon, [4]float64{16, 16, 32, 32}, image.Rect(1, 1, 2, 2)},
+		{ShapeRectangle, [4]float64{0, 0, 32, 16}, image.Rect(0, 0, 2, 1)},
+		{ShapeRectangle, [4]float64{32, 0, 64, 8}, image.Rect(2, 0, 4, 1)},
+		{ShapeRectangle, [4]float64{56, 16, 64, 32}, image.Rect(3, 1, 4, 2)},
+	}
+	if len(shapes) != len(want) {
+		t.Fatalf("found %d shapes, want %d", len(shapes), len(want))
+	}
+
+	for i, w := range want {
+		shape := shapes[i]
+		minX, minY, maxX, maxY := bounds(shape.Vertices)
+		if shape.Shape != w.shape {
+			t.Errorf("shape %d: kind %v, want %v", i, shape.Shape, w.shape)
+		}
+		if got := [4]float64{minX, minY, maxX, maxY}; got != w.bounds {
+			t.Errorf("shape %d: bounds %v, want %v", i, got, w.bounds)
+		}
+		if shape.Cells != w.cells {
+			t.Errorf("shape %d: cells %v, want %v", i, shape.Cells, w.cells)
+		}
+		if area := signedArea(shape.Vertices); area <= 0 {
+			t.Errorf("shape %d: vertices %v are not clockwise", i, shape.Vertices)
+		}
+	}
+
+	// The merged rectangles are not from a single tile, the rectangle of the flipped tile is.
+	if shapes[2].Object != nil || shapes[2].GID != 0 {
+		t.Errorf("merged rectangle has object %v and gid %d", shapes[2].Object, shapes[2].GID)
+	}
+	if shapes[4].Object == nil || shapes[4].GID != GID(flippedLeftHalf) {
+		t.Errorf("single rectangle has object %v and gid %d", shapes[4].Object, shapes[4].GID)
+	}
+}
+
+func TestCollisionShapesOrientations(t *testing.T) {
+	tests := []struct {
+		orientation string
+		flags       GID
+		alignedAxis bool
+	}{
+		{"isometric", 0, true},
+		{"staggered", 0, true},
+		{"hexagonal", 0, true},
+		{"hexagonal", FlippedDiagonallyFlag, false},
+		{"hexagonal", RotatedHexagonal120Flag, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %#x", test.orientation, uint32(test.flags)), func(t *testing.T) {
+			m, layer := loadCollisionMap(t, test.orientation, 2, 2, fmt.Sprintf("0,0,\n0,%d", uint32(1|test.flags)))
+
+			shapes, err := m.CollisionShapes(layer, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(shapes) != 1 {
+				t.Fatalf("found %d shapes, want 1", len(shapes))
+			}
+			shape := shapes[0]
+
+			// The tile is drawn with its bottom-left corner at the render origin, rotations keep its center.
+			originX, originY := m.TileRenderOrigin(1, 1)
+			var centerX, centerY float64
+			for _, v := range shape.Vertices {
+				centerX += v.X / float64(len(shape.Vertices))
+				centerY += v.Y / float64(len(shape.Vertices))
+			}
+			if !near(centerX, originX+8) || !near(centerY, originY-8) {
+				t.Errorf("center %v, %v, want %v, %v", centerX, centerY, originX+8, originY-8)
+			}
+
+			if isAxisAligned(shape) != test.alignedAxis {
+				t.Errorf("vertices %v axis-aligned %v, want %v", shape.Vertices, !test.alignedAxis, test.alignedAxis)
+			}
+			if area := signedArea(shape.Vertices); !near(area, 256) {
+				t.Errorf("area %v, want 256", area)
+			}
+		})
+	}
+}
@@ -3,8 +3,6 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
-	"io/ioutil"
-	"path/filepath"
 	"strings"
 )
 
@@ -29,60 +27,6 @@ func (c *Content) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartEleme
 		c.Type = startElement.Name.Local
 		c.Value = tileset
 
-		// Update any image sources that are embedded the tmx file.
-		if tileset.Image != nil {
-
-			// Get image directory and filename and create a safe path.
-			imgDir, imgFile := filepath.Split(tileset.Image.Source)
-			imgPath := filepath.Join(tmxDir, imgDir, imgFile)
-
-			// Update image source with a safe path.
-			tileset.Image.Source = imgPath
-
-		}
-
-		// External Tileset
-		// Update any tileset and image sources that come from external tsx files.
-		if tileset.Source != "" {
-
-			// Get tsx directory and filename and create a safe path.
-			tsxDir, tsxFile := filepath.Split(tileset.Source)
-			tsxPath := filepath.Join(tmxDir, tsxDir, tsxFile)
-
-			// Update tileset source with a safe path.
-			tileset.Source = tsxPath
-
-			// fmt.Println(tsxPath)
-
-			// Unmarshal a tsx path.
-			tsxBytes, err := ioutil.ReadFile(tsxPath)
-			if err != nil {
-				return fmt.Errorf("error reading tsx file: %w", err)
-			}
-			err = xml.Unmarshal(tsxBytes, &tileset)
-			if err != nil {
-				return fmt.Errorf("error unmarshaling tsx bytes: %w", err)
-			}
-
-			// Get image directory and filename and create a safe path.
-			imgDir, imgFile := filepath.Split(tileset.Image.Source)
-			imgPath := filepath.Join(tmxDir, tsxDir, imgDir, imgFile)
-
-			// Update image source with a safe path.
-			tileset.Image.Source = imgPath
-
-		}
-
-	case "properties":
-
-		properties := &Properties{}
-		err := decoder.DecodeElement(properties, &startElement)
-		if err != nil {
-			return err
-		}
-		c.Type = startElement.Name.Local
-		c.Value = properties
-
 	case "layer":
 
 		layer := &Layer{}
@@ -131,6 +75,22 @@ func (c *Content) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartEleme
 
 }
 
+// MarshalXML is called by Marshal to produce the XML element of the value.
+func (c Content) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	return encoder.Encode(c.Value)
+}
+
+// encodeContent encodes Map.Content and Group.Content in order.
+func encodeContent(encoder *xml.Encoder, content []Content) error {
+	for _, c := range content {
+		err := encoder.Encode(c.Value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Content) String() string {
 	var b strings.Builder
 
@@ -138,8 +98,6 @@ func (c *Content) String() string {
 
 	case *Tileset:
 		fmt.Fprintf(&b, v.String())
-	case *Properties:
-		fmt.Fprintf(&b, v.String())
 	case *Layer:
 		fmt.Fprintf(&b, v.String())
 	case *ObjectGroup:
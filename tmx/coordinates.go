@@ -0,0 +1,354 @@
+package tmx
+
+import (
+	"image"
+	"math"
+)
+
+// Tiled uses two kinds of pixel positions. Screen positions are where things are drawn. Pixel positions are where
+// objects are saved, they are the same as screen positions on every orientation except isometric, where objects are
+// saved in a space with both axes measured in tile heights along the sides of the diamonds.
+
+// staggerParams are the sizes used to place the tiles of staggered and hexagonal maps, the same as the render
+// parameters of the hexagonal renderer of Tiled. Staggered maps are hexagonal maps with sides of length 0.
+type staggerParams struct {
+	tileWidth    int
+	tileHeight   int
+	sideLengthX  int
+	sideLengthY  int
+	sideOffsetX  int
+	sideOffsetY  int
+	columnWidth  int
+	rowHeight    int
+	staggerX     bool
+	staggerEven  bool
+	staggerIndex int
+}
+
+// staggerParams returns the sizes used to place the tiles of a staggered or hexagonal map.
+func (m *Map) staggerParams() staggerParams {
+	p := staggerParams{
+		// Odd sizes can not be split evenly, they are rounded down to the nearest even size.
+		tileWidth:   m.TileWidth &^ 1,
+		tileHeight:  m.TileHeight &^ 1,
+		staggerX:    m.StaggerAxis == StaggerAxisX,
+		staggerEven: m.StaggerIndex == StaggerIndexEven,
+	}
+
+	if m.Orientation == OrientationHexagonal {
+		if p.staggerX {
+			p.sideLengthX = m.HexSideLength
+		} else {
+			p.sideLengthY = m.HexSideLength
+		}
+	}
+
+	if p.staggerEven {
+		p.staggerIndex = 1
+	}
+
+	p.sideOffsetX = (p.tileWidth - p.sideLengthX) / 2
+	p.sideOffsetY = (p.tileHeight - p.sideLengthY) / 2
+	p.columnWidth = p.sideOffsetX + p.sideLengthX
+	p.rowHeight = p.sideOffsetY + p.sideLengthY
+
+	return p
+}
+
+// staggered reports whether a row or column index is shifted.
+func (p staggerParams) staggered(index int) bool {
+	return (index&1)^p.staggerIndex != 0
+}
+
+// TileToScreen returns the screen position of a tile, the top-left corner of its cell on orthogonal, staggered and
+// hexagonal maps, and the top corner of its diamond on isometric maps.
+func (m *Map) TileToScreen(x int, y int) (float64, float64) {
+	return m.tileToScreen(float64(x), float64(y))
+}
+
+// tileToScreen returns the screen position of a tile position, fractional positions are only meaningful on
+// orthogonal and isometric maps.
+func (m *Map) tileToScreen(x float64, y float64) (float64, float64) {
+	switch m.Orientation {
+	case OrientationIsometric:
+		originX := float64(m.Height * m.TileWidth / 2)
+		return (x-y)*float64(m.TileWidth)/2 + originX, (x + y) * float64(m.TileHeight) / 2
+
+	case OrientationStaggered, OrientationHexagonal:
+		p := m.staggerParams()
+		tileX, tileY := int(math.Floor(x)), int(math.Floor(y))
+
+		if p.staggerX {
+			pixelY := tileY * (p.tileHeight + p.sideLengthY)
+			if p.staggered(tileX) {
+				pixelY += p.rowHeight
+			}
+			return float64(tileX * p.columnWidth), float64(pixelY)
+		}
+
+		pixelX := tileX * (p.tileWidth + p.sideLengthX)
+		if p.staggered(tileY) {
+			pixelX += p.columnWidth
+		}
+		return float64(pixelX), float64(tileY * p.rowHeight)
+	}
+
+	return x * float64(m.TileWidth), y * float64(m.TileHeight)
+}
+
+// ScreenToTile returns the tile at a screen position. Tiles outside the map are returned too, including negative
+// ones.
+func (m *Map) ScreenToTile(x float64, y float64) (int, int) {
+	switch m.Orientation {
+	case OrientationIsometric:
+		x -= float64(m.Height * m.TileWidth / 2)
+		tileY := y / float64(m.TileHeight)
+		tileX := x / float64(m.TileWidth)
+		return int(math.Floor(tileY + tileX)), int(math.Floor(tileY - tileX))
+
+	case OrientationStaggered:
+		return m.staggeredScreenToTile(x, y)
+
+	case OrientationHexagonal:
+		return m.hexagonalScreenToTile(x, y)
+	}
+
+	return int(math.Floor(x / float64(m.TileWidth))), int(math.Floor(y / float64(m.TileHeight)))
+}
+
+// staggeredScreenToTile returns the tile of a staggered map at a screen position. Each rectangle of the grid holds a
+// whole diamond, and parts of the diamonds of its four neighbours in the corners.
+func (m *Map) staggeredScreenToTile(x float64, y float64) (int, int) {
+	p := m.staggerParams()
+
+	if p.staggerX {
+		if p.staggerEven {
+			x -= float64(p.sideOffsetX)
+		}
+	} else if p.staggerEven {
+		y -= float64(p.sideOffsetY)
+	}
+
+	// The grid-aligned rectangle, and the position within it.
+	refX := int(math.Floor(x / float64(p.tileWidth)))
+	refY := int(math.Floor(y / float64(p.tileHeight)))
+	relX := x - float64(refX*p.tileWidth)
+	relY := y - float64(refY*p.tileHeight)
+
+	if p.staggerX {
+		refX *= 2
+		if p.staggerEven {
+			refX++
+		}
+	} else {
+		refY *= 2
+		if p.staggerEven {
+			refY++
+		}
+	}
+
+	yPos := relX * float64(p.tileHeight) / float64(p.tileWidth)
+	sideOffsetY := float64(p.sideOffsetY)
+
+	switch {
+	case sideOffsetY-yPos > relY:
+		return m.staggerNeighbour(refX, refY, -1, -1)
+	case -sideOffsetY+yPos > relY:
+		return m.staggerNeighbour(refX, refY, 1, -1)
+	case sideOffsetY+yPos < relY:
+		return m.staggerNeighbour(refX, refY, -1, 1)
+	case sideOffsetY*3-yPos < relY:
+		return m.staggerNeighbour(refX, refY, 1, 1)
+	}
+
+	return refX, refY
+}
+
+// staggerNeighbour returns the diagonal neighbour of a tile on a staggered or hexagonal map, dx and dy are -1 or 1 for
+// the top-left, top-right, bottom-left and bottom-right neighbours.
+func (m *Map) staggerNeighbour(x int, y int, dx int, dy int) (int, int) {
+	p := m.staggerParams()
+
+	if p.staggerX {
+		// Shifted columns are half a row lower than their neighbours.
+		if p.staggered(x) == (dy > 0) {
+			return x + dx, y + dy
+		}
+		return x + dx, y
+	}
+
+	// Shifted rows are half a column further right than their neighbours.
+	if p.staggered(y) == (dx > 0) {
+		return x + dx, y + dy
+	}
+	return x, y + dy
+}
+
+// hexagonalScreenToTile returns the tile of a hexagonal map at a screen position, the tile with the nearest center
+// within a grid-aligned rectangle of two by two tiles.
+func (m *Map) hexagonalScreenToTile(x float64, y float64) (int, int) {
+	p := m.staggerParams()
+
+	if p.staggerX {
+		if p.staggerEven {
+			x -= float64(p.tileWidth)
+		} else {
+			x -= float64(p.sideOffsetX)
+		}
+	} else {
+		if p.staggerEven {
+			y -= float64(p.tileHeight)
+		} else {
+			y -= float64(p.sideOffsetY)
+		}
+	}
+
+	// The grid-aligned rectangle, and the position within it.
+	refX := int(math.Floor(x / float64(p.columnWidth*2)))
+	refY := int(math.Floor(y / float64(p.rowHeight*2)))
+	relX := x - float64(refX*p.columnWidth*2)
+	relY := y - float64(refY*p.rowHeight*2)
+
+	if p.staggerX {
+		refX *= 2
+		if p.staggerEven {
+			refX++
+		}
+	} else {
+		refY *= 2
+		if p.staggerEven {
+			refY++
+		}
+	}
+
+	// The centers of the tiles that overlap the rectangle, and the offset of each tile from the reference tile.
+	var centers [4]Vertex
+	var offsets [4]image.Point
+
+	if p.staggerX {
+		left := float64(p.sideLengthX / 2)
+		centerX := left + float64(p.columnWidth)
+		centerY := float64(p.tileHeight / 2)
+
+		centers = [4]Vertex{
+			{left, centerY},
+			{centerX, centerY - float64(p.rowHeight)},
+			{centerX, centerY + float64(p.rowHeight)},
+			{centerX + float64(p.columnWidth), centerY},
+		}
+		offsets = [4]image.Point{{0, 0}, {1, -1}, {1, 0}, {2, 0}}
+	} else {
+		top := float64(p.sideLengthY / 2)
+		centerX := float64(p.tileWidth / 2)
+		centerY := top + float64(p.rowHeight)
+
+		centers = [4]Vertex{
+			{centerX, top},
+			{centerX - float64(p.columnWidth), centerY},
+			{centerX + float64(p.columnWidth), centerY},
+			{centerX, centerY + float64(p.rowHeight)},
+		}
+		offsets = [4]image.Point{{0, 0}, {-1, 1}, {0, 1}, {0, 2}}
+	}
+
+	nearest := 0
+	minDist := math.Inf(1)
+	for i, center := range centers {
+		dx, dy := center.X-relX, center.Y-relY
+		if dist := dx*dx + dy*dy; dist < minDist {
+			minDist = dist
+			nearest = i
+		}
+	}
+
+	return refX + offsets[nearest].X, refY + offsets[nearest].Y
+}
+
+// TileCenter returns the screen position of the center of a tile.
+func (m *Map) TileCenter(x int, y int) (float64, float64) {
+	switch m.Orientation {
+	case OrientationIsometric:
+		sx, sy := m.TileToScreen(x, y)
+		return sx, sy + float64(m.TileHeight)/2
+
+	case OrientationStaggered, OrientationHexagonal:
+		p := m.staggerParams()
+		sx, sy := m.TileToScreen(x, y)
+		return sx + float64(p.tileWidth)/2, sy + float64(p.tileHeight)/2
+	}
+
+	return (float64(x) + 0.5) * float64(m.TileWidth), (float64(y) + 0.5) * float64(m.TileHeight)
+}
+
+// TileRenderOrigin returns the screen position Tiled draws the image of a tile from, the bottom-left corner of the
+// bounding box of its cell. Tile images are aligned to it by their bottom-left corner, so images taller or wider than
+// the map tiles extend up and to the right.
+func (m *Map) TileRenderOrigin(x int, y int) (float64, float64) {
+	sx, sy := m.TileToScreen(x, y)
+
+	switch m.Orientation {
+	case OrientationIsometric:
+		return sx - float64(m.TileWidth)/2, sy + float64(m.TileHeight)
+	case OrientationStaggered, OrientationHexagonal:
+		return sx, sy + float64(m.staggerParams().tileHeight)
+	}
+
+	return sx, sy + float64(m.TileHeight)
+}
+
+// PixelToScreen converts the pixel position of an object to a screen position. Only isometric maps save objects in
+// their own space, on other orientations the position is returned as it is.
+func (m *Map) PixelToScreen(x float64, y float64) (float64, float64) {
+	if m.Orientation != OrientationIsometric {
+		return x, y
+	}
+
+	tileX := x / float64(m.TileHeight)
+	tileY := y / float64(m.TileHeight)
+
+	return m.tileToScreen(tileX, tileY)
+}
+
+// ScreenToPixel converts a screen position to the pixel position objects are saved in, the inverse of PixelToScreen.
+func (m *Map) ScreenToPixel(x float64, y float64) (float64, float64) {
+	if m.Orientation != OrientationIsometric {
+		return x, y
+	}
+
+	x -= float64(m.Height * m.TileWidth / 2)
+	tileY := y / float64(m.TileHeight)
+	tileX := x / float64(m.TileWidth)
+
+	return (tileY + tileX) * float64(m.TileHeight), (tileY - tileX) * float64(m.TileHeight)
+}
+
+// ScreenSize returns the size of the map in screen pixels, the size of the image the map renders to. Infinite maps
+// return the size of their Width and Height.
+func (m *Map) ScreenSize() (int, int) {
+	switch m.Orientation {
+	case OrientationIsometric:
+		side := m.Width + m.Height
+		return side * m.TileWidth / 2, side * m.TileHeight / 2
+
+	case OrientationStaggered, OrientationHexagonal:
+		p := m.staggerParams()
+
+		if p.staggerX {
+			width := m.Width*p.columnWidth + p.sideOffsetX
+			height := m.Height * (p.tileHeight + p.sideLengthY)
+			if m.Width > 1 {
+				height += p.rowHeight
+			}
+			return width, height
+		}
+
+		width := m.Width * (p.tileWidth + p.sideLengthX)
+		height := m.Height*p.rowHeight + p.sideOffsetY
+		if m.Height > 1 {
+			width += p.columnWidth
+		}
+		return width, height
+	}
+
+	return m.Width * m.TileWidth, m.Height * m.TileHeight
+}
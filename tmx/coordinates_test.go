@@ -0,0 +1,199 @@
+package tmx
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// coordinateMap is a map orientation to test the coordinate conversions with.
+type coordinateMap struct {
+	orientation   string
+	staggerAxis   string
+	staggerIndex  string
+	tileWidth     int
+	tileHeight    int
+	hexSideLength int
+}
+
+func (c coordinateMap) String() string {
+	return fmt.Sprintf("%s %s %s %dx%d side %d", c.orientation, c.staggerAxis, c.staggerIndex, c.tileWidth,
+		c.tileHeight, c.hexSideLength)
+}
+
+// newMap returns a 10x8 map with the orientation.
+func (c coordinateMap) newMap() *Map {
+	return &Map{
+		Orientation:   c.orientation,
+		Width:         10,
+		Height:        8,
+		TileWidth:     c.tileWidth,
+		TileHeight:    c.tileHeight,
+		HexSideLength: c.hexSideLength,
+		StaggerAxis:   c.staggerAxis,
+		StaggerIndex:  c.staggerIndex,
+	}
+}
+
+// coordinateMaps returns every orientation with every stagger axis and index, with even and odd tile sizes.
+func coordinateMaps() []coordinateMap {
+	var maps []coordinateMap
+
+	sizes := [][3]int{{32, 32, 0}, {64, 32, 0}, {33, 17, 0}}
+	for _, orientation := range []string{OrientationOrthogonal, OrientationIsometric} {
+		for _, size := range sizes {
+			maps = append(maps, coordinateMap{orientation, "", "", size[0], size[1], 0})
+		}
+	}
+
+	staggered := map[string][][3]int{
+		OrientationStaggered: {{64, 32, 0}, {33, 17, 0}},
+		OrientationHexagonal: {{32, 32, 16}, {14, 12, 6}, {31, 27, 13}, {32, 28, 0}},
+	}
+	for _, orientation := range []string{OrientationStaggered, OrientationHexagonal} {
+		for _, axis := range []string{StaggerAxisX, StaggerAxisY} {
+			for _, index := range []string{StaggerIndexOdd, StaggerIndexEven} {
+				for _, size := range staggered[orientation] {
+					maps = append(maps, coordinateMap{orientation, axis, index, size[0], size[1], size[2]})
+				}
+			}
+		}
+	}
+
+	return maps
+}
+
+func TestTileToScreen(t *testing.T) {
+	tests := []struct {
+		m      coordinateMap
+		x      int
+		y      int
+		screen [2]float64
+	}{
+		{coordinateMap{OrientationOrthogonal, "", "", 32, 16, 0}, 2, 3, [2]float64{64, 48}},
+		{coordinateMap{OrientationOrthogonal, "", "", 32, 16, 0}, -1, -2, [2]float64{-32, -32}},
+		// The top corner of tile 0, 0 is in the middle of the top of the map, 8 tiles high.
+		{coordinateMap{OrientationIsometric, "", "", 64, 32, 0}, 0, 0, [2]float64{256, 0}},
+		{coordinateMap{OrientationIsometric, "", "", 64, 32, 0}, 1, 0, [2]float64{288, 16}},
+		{coordinateMap{OrientationIsometric, "", "", 64, 32, 0}, 0, 1, [2]float64{224, 16}},
+		{coordinateMap{OrientationStaggered, StaggerAxisY, StaggerIndexOdd, 64, 32, 0}, 0, 1, [2]float64{32, 16}},
+		{coordinateMap{OrientationStaggered, StaggerAxisY, StaggerIndexEven, 64, 32, 0}, 0, 1, [2]float64{0, 16}},
+		{coordinateMap{OrientationStaggered, StaggerAxisX, StaggerIndexOdd, 64, 32, 0}, 1, 0, [2]float64{32, 16}},
+		{coordinateMap{OrientationStaggered, StaggerAxisX, StaggerIndexOdd, 64, 32, 0}, 2, 1, [2]float64{64, 32}},
+		{coordinateMap{OrientationHexagonal, StaggerAxisY, StaggerIndexOdd, 32, 32, 16}, 0, 1, [2]float64{16, 24}},
+		{coordinateMap{OrientationHexagonal, StaggerAxisY, StaggerIndexOdd, 32, 32, 16}, 1, 2, [2]float64{32, 48}},
+		{coordinateMap{OrientationHexagonal, StaggerAxisX, StaggerIndexEven, 32, 32, 16}, 0, 0, [2]float64{0, 16}},
+		{coordinateMap{OrientationHexagonal, StaggerAxisX, StaggerIndexEven, 32, 32, 16}, 1, 0, [2]float64{24, 0}},
+		// Odd sizes are rounded down to even sizes on staggered and hexagonal maps.
+		{coordinateMap{OrientationStaggered, StaggerAxisY, StaggerIndexOdd, 33, 17, 0}, 1, 1, [2]float64{48, 8}},
+	}
+
+	for _, test := range tests {
+		x, y := test.m.newMap().TileToScreen(test.x, test.y)
+		if x != test.screen[0] || y != test.screen[1] {
+			t.Errorf("%v: TileToScreen(%d, %d) = %v, %v, want %v", test.m, test.x, test.y, x, y, test.screen)
+		}
+	}
+}
+
+func TestScreenSize(t *testing.T) {
+	tests := []struct {
+		m      coordinateMap
+		width  int
+		height int
+	}{
+		{coordinateMap{OrientationOrthogonal, "", "", 32, 16, 0}, 320, 128},
+		{coordinateMap{OrientationIsometric, "", "", 64, 32, 0}, 576, 288},
+		{coordinateMap{OrientationStaggered, StaggerAxisY, StaggerIndexOdd, 64, 32, 0}, 672, 144},
+		{coordinateMap{OrientationStaggered, StaggerAxisX, StaggerIndexOdd, 64, 32, 0}, 352, 272},
+		{coordinateMap{OrientationHexagonal, StaggerAxisY, StaggerIndexOdd, 32, 32, 16}, 336, 200},
+		{coordinateMap{OrientationHexagonal, StaggerAxisX, StaggerIndexOdd, 32, 32, 16}, 248, 272},
+	}
+
+	for _, test := range tests {
+		width, height := test.m.newMap().ScreenSize()
+		if width != test.width || height != test.height {
+			t.Errorf("%v: ScreenSize() = %d, %d, want %d, %d", test.m, width, height, test.width, test.height)
+		}
+	}
+}
+
+func TestTileCenterRoundTrip(t *testing.T) {
+	for _, c := range coordinateMaps() {
+		t.Run(c.String(), func(t *testing.T) {
+			m := c.newMap()
+
+			// Negative tiles and tiles past the size of the map are converted the same way.
+			for y := -3; y < m.Height+3; y++ {
+				for x := -3; x < m.Width+3; x++ {
+					sx, sy := m.TileCenter(x, y)
+
+					// Positions near the center are in the same tile.
+					for _, d := range [][2]float64{{0, 0}, {-2, 0}, {2, 0}, {0, -2}, {0, 2}} {
+						tileX, tileY := m.ScreenToTile(sx+d[0], sy+d[1])
+						if tileX != x || tileY != y {
+							t.Fatalf("ScreenToTile(TileCenter(%d, %d) + %v) = %d, %d", x, y, d, tileX, tileY)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTileRenderOrigin(t *testing.T) {
+	for _, c := range coordinateMaps() {
+		m := c.newMap()
+
+		// The image of a tile the size of the map tiles is drawn centered on the center of the tile.
+		for _, tile := range [][2]int{{0, 0}, {3, 2}, {-2, -1}} {
+			ox, oy := m.TileRenderOrigin(tile[0], tile[1])
+			cx, cy := m.TileCenter(tile[0], tile[1])
+
+			width, height := float64(m.TileWidth), float64(m.TileHeight)
+			if m.Orientation == OrientationStaggered || m.Orientation == OrientationHexagonal {
+				width, height = float64(m.TileWidth&^1), float64(m.TileHeight&^1)
+			}
+
+			if ox+width/2 != cx || oy-height/2 != cy {
+				t.Errorf("%v: TileRenderOrigin(%d, %d) = %v, %v, not centered on %v, %v", c, tile[0], tile[1], ox,
+					oy, cx, cy)
+			}
+		}
+	}
+}
+
+func TestPixelToScreenRoundTrip(t *testing.T) {
+	for _, c := range coordinateMaps() {
+		m := c.newMap()
+
+		for _, p := range [][2]float64{{0, 0}, {17.5, 3}, {100, 250}, {-40, 12.25}} {
+			sx, sy := m.PixelToScreen(p[0], p[1])
+			x, y := m.ScreenToPixel(sx, sy)
+			if math.Abs(x-p[0]) > 1e-9 || math.Abs(y-p[1]) > 1e-9 {
+				t.Errorf("%v: ScreenToPixel(PixelToScreen(%v)) = %v, %v", c, p, x, y)
+			}
+			if m.Orientation != OrientationIsometric && (sx != p[0] || sy != p[1]) {
+				t.Errorf("%v: PixelToScreen(%v) = %v, %v, want it unchanged", c, p, sx, sy)
+			}
+		}
+	}
+}
+
+func TestPixelToScreenIsometric(t *testing.T) {
+	m := coordinateMap{OrientationIsometric, "", "", 64, 32, 0}.newMap()
+
+	// Objects are saved with both axes in tile heights, one tile height along an axis is one tile.
+	tests := [][4]float64{
+		{0, 0, 256, 0},
+		{32, 0, 288, 16},
+		{0, 32, 224, 16},
+		{32, 32, 256, 32},
+	}
+	for _, test := range tests {
+		x, y := m.PixelToScreen(test[0], test[1])
+		if x != test[2] || y != test[3] {
+			t.Errorf("PixelToScreen(%v, %v) = %v, %v, want %v, %v", test[0], test[1], x, y, test[2], test[3])
+		}
+	}
+}
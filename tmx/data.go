@@ -1,9 +1,29 @@
 package tmx
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
 	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Data constants
+const (
+	EncodingCSV    string = "csv"
+	EncodingBase64 string = "base64"
+
+	CompressionGzip string = "gzip"
+	CompressionZlib string = "zlib"
+	CompressionZstd string = "zstd"
 )
 
 // Data structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#data
@@ -14,7 +34,7 @@ type Data struct {
 	// The encoding used to encode the tile layer data. When used, it can be “base64” and “csv” at the moment.
 	Encoding string `xml:"encoding,attr"`
 
-	// The compression used to compress the tile layer data. Tiled supports “gzip” and “zlib”.
+	// The compression used to compress the tile layer data. Tiled supports “gzip”, “zlib” and “zstd” (since 1.3).
 	Compression string `xml:"compression,attr"`
 
 	// When no encoding or compression is given, the tiles are stored as individual XML tile elements. Next to that,
@@ -52,3 +72,254 @@ func (d *Data) String() string {
 
 	return b.String()
 }
+
+// MarshalXML is called by Marshal to produce the XML element, the raw InnerXML of the data or of each chunk is
+// written as the content.
+func (d *Data) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	if len(d.Chunk) == 0 {
+		return encoder.EncodeElement(struct {
+			Encoding    string `xml:"encoding,attr,omitempty"`
+			Compression string `xml:"compression,attr,omitempty"`
+			InnerXML    string `xml:",innerxml"`
+		}{d.Encoding, d.Compression, d.InnerXML}, startElement("data", nil))
+	}
+
+	var a attrs
+	a.str("encoding", d.Encoding, "")
+	a.str("compression", d.Compression, "")
+
+	start = startElement("data", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encodeElements(encoder, []interface{}{d.Chunk})
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
+// Encode replaces the tile layer data with gids, encoded with encoding and compression. Width is the number of tiles
+// in a row, used to break csv data into rows. Level is the compression level like Map.CompressionLevel, -1 uses the
+// default level of the compression. The raw InnerXML is regenerated, and for data without an encoding so are the tile
+// elements.
+func (d *Data) Encode(gids []GID, width int, encoding string, compression string, level int) error {
+	innerXML, tiles, err := encodeGIDs(gids, width, encoding, compression, level)
+	if err != nil {
+		return err
+	}
+
+	d.Encoding = encoding
+	d.Compression = compression
+	d.InnerXML = innerXML
+	d.Tile = tiles
+	d.Chunk = nil
+
+	return nil
+}
+
+// decode decodes the tile layer data into global tile IDs, the layer is expected to hold count tiles.
+func (d *Data) decode(count int) ([]GID, error) {
+	return decodeGIDs(d.Encoding, d.Compression, d.InnerXML, d.Tile, count)
+}
+
+// decodeGIDs decodes the tile data of a layer or chunk into global tile IDs, the data is expected to hold count
+// tiles. Tiles are only used when there is no encoding.
+func decodeGIDs(encoding string, compression string, text string, tiles []*LayerTile, count int) ([]GID, error) {
+	var gids []GID
+	var err error
+
+	switch encoding {
+	case EncodingCSV:
+		gids, err = decodeCSV(text)
+	case EncodingBase64:
+		gids, err = decodeBase64(text, compression)
+	case "":
+		gids = make([]GID, 0, len(tiles))
+		for _, tile := range tiles {
+			gids = append(gids, tile.GID)
+		}
+	default:
+		return nil, fmt.Errorf("unknown data encoding: %q", encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gids) != count {
+		return nil, fmt.Errorf("error decoding %q data: expected %d tiles, found %d", encoding, count, len(gids))
+	}
+
+	return gids, nil
+}
+
+// encodeGIDs encodes global tile IDs as the inner xml of a layer or chunk. When there is no encoding the tiles are
+// returned as tile elements too.
+func encodeGIDs(gids []GID, width int, encoding string, compression string, level int) (string, []*LayerTile,
+	error) {
+	if encoding != EncodingBase64 && compression != "" {
+		return "", nil, fmt.Errorf("error encoding %q data: only base64 data can be compressed", encoding)
+	}
+
+	switch encoding {
+	case EncodingCSV:
+		return encodeCSV(gids, width), nil, nil
+	case EncodingBase64:
+		text, err := encodeBase64(gids, compression, level)
+		return text, nil, err
+	case "":
+		var b strings.Builder
+		tiles := make([]*LayerTile, len(gids))
+		for i, gid := range gids {
+			tiles[i] = &LayerTile{GID: gid}
+			if gid == 0 {
+				fmt.Fprintf(&b, "<tile/>")
+			} else {
+				fmt.Fprintf(&b, "<tile gid=\"%d\"/>", uint32(gid))
+			}
+		}
+		return b.String(), tiles, nil
+	}
+
+	return "", nil, fmt.Errorf("unknown data encoding: %q", encoding)
+}
+
+// encodeCSV encodes global tile IDs as comma separated values, one row per line.
+func encodeCSV(gids []GID, width int) string {
+	var b strings.Builder
+
+	b.WriteString("\n")
+	for i, gid := range gids {
+		b.WriteString(strconv.FormatUint(uint64(gid), 10))
+		if i < len(gids)-1 {
+			b.WriteString(",")
+			if width > 0 && (i+1)%width == 0 {
+				b.WriteString("\n")
+			}
+		}
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// encodeBase64 encodes global tile IDs as optionally compressed little-endian bytes in base64, -1 compresses them with
+// the default level of the compression.
+func encodeBase64(gids []GID, compression string, level int) (string, error) {
+	raw := make([]byte, len(gids)*4)
+	for i, gid := range gids {
+		binary.LittleEndian.PutUint32(raw[i*4:], uint32(gid))
+	}
+
+	var b bytes.Buffer
+	var w io.WriteCloser
+	var err error
+
+	switch compression {
+	case "":
+		b.Write(raw)
+	case CompressionGzip:
+		w, err = gzip.NewWriterLevel(&b, level)
+	case CompressionZlib:
+		w, err = zlib.NewWriterLevel(&b, level)
+	case CompressionZstd:
+		var options []zstd.EOption
+		if level > 0 {
+			options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		w, err = zstd.NewWriter(&b, options...)
+	default:
+		return "", fmt.Errorf("unknown data compression: %q", compression)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error compressing %s data: %w", compression, err)
+	}
+
+	if w != nil {
+		_, err = w.Write(raw)
+		if err != nil {
+			return "", fmt.Errorf("error compressing %s data: %w", compression, err)
+		}
+		err = w.Close()
+		if err != nil {
+			return "", fmt.Errorf("error compressing %s data: %w", compression, err)
+		}
+	}
+
+	return "\n" + base64.StdEncoding.EncodeToString(b.Bytes()) + "\n", nil
+}
+
+// decodeCSV decodes comma separated global tile IDs.
+func decodeCSV(text string) ([]GID, error) {
+	fields := strings.Split(strings.TrimSpace(text), ",")
+	if len(fields) == 1 && fields[0] == "" {
+		return []GID{}, nil
+	}
+
+	gids := make([]GID, 0, len(fields))
+	for _, field := range fields {
+		gid, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding csv data: %w", err)
+		}
+		gids = append(gids, GID(gid))
+	}
+
+	return gids, nil
+}
+
+// decodeBase64 decodes base64 encoded and optionally compressed little-endian global tile IDs.
+func decodeBase64(text string, compression string) ([]GID, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding base64 data: %w", err)
+	}
+
+	var r io.Reader = bytes.NewReader(raw)
+
+	switch compression {
+	case "":
+	case CompressionGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing gzip data: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	case CompressionZlib:
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing zlib data: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing zstd data: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("unknown data compression: %q", compression)
+	}
+
+	raw, err = ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing %s data: %w", compression, err)
+	}
+
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("error decoding base64 data: %d bytes is not a multiple of 4", len(raw))
+	}
+
+	gids := make([]GID, len(raw)/4)
+	for i := range gids {
+		gids[i] = GID(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+
+	return gids, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // Frame structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#frame
@@ -17,6 +18,14 @@ type Frame struct {
 	Duration int64 `xml:"duration,attr"`
 }
 
+// duration returns Duration as a time.Duration, negative durations count as 0.
+func (f *Frame) duration() time.Duration {
+	if f.Duration <= 0 {
+		return 0
+	}
+	return time.Duration(f.Duration) * time.Millisecond
+}
+
 func (f *Frame) String() string {
 	var b strings.Builder
 
@@ -0,0 +1,77 @@
+package tmx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GID flags
+const (
+	FlippedHorizontallyFlag GID = 0x80000000
+	FlippedVerticallyFlag   GID = 0x40000000
+	FlippedDiagonallyFlag   GID = 0x20000000
+	RotatedHexagonal120Flag GID = 0x10000000
+
+	gidFlags GID = FlippedHorizontallyFlag | FlippedVerticallyFlag | FlippedDiagonallyFlag | RotatedHexagonal120Flag
+)
+
+// GID structure: https://doc.mapeditor.org/en/stable/reference/global-tile-ids/
+type GID uint32
+
+// The highest four bits of the 32-bit GID are flip flags, and you will need to read and clear them before you can
+// access the GID itself to identify the tile. Whenever the diagonal flag is set, the horizontal and vertical flags
+// are applied after the tile has been flipped diagonally (an anti-diagonal flip is achieved by combining all three).
+//
+// For hexagonal maps, the diagonal flag is used to rotate the tile 60 degrees and the hexagonal 120 flag is used to
+// rotate the tile 120 degrees.
+
+// ID returns the global tile ID with the flip flags cleared. An ID of 0 means the cell is empty.
+func (g GID) ID() GID {
+	return g &^ gidFlags
+}
+
+// FlippedHorizontally reports whether the tile is flipped horizontally.
+func (g GID) FlippedHorizontally() bool {
+	return g&FlippedHorizontallyFlag != 0
+}
+
+// FlippedVertically reports whether the tile is flipped vertically.
+func (g GID) FlippedVertically() bool {
+	return g&FlippedVerticallyFlag != 0
+}
+
+// FlippedDiagonally reports whether the tile is flipped diagonally, or rotated 60 degrees on hexagonal maps.
+func (g GID) FlippedDiagonally() bool {
+	return g&FlippedDiagonallyFlag != 0
+}
+
+// RotatedHexagonal120 reports whether the tile is rotated 120 degrees, only used on hexagonal maps.
+func (g GID) RotatedHexagonal120() bool {
+	return g&RotatedHexagonal120Flag != 0
+}
+
+// Flags returns only the flip flags of the global tile ID.
+func (g GID) Flags() GID {
+	return g & gidFlags
+}
+
+func (g GID) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d", g.ID())
+
+	if g.FlippedHorizontally() {
+		fmt.Fprintf(&b, " H")
+	}
+	if g.FlippedVertically() {
+		fmt.Fprintf(&b, " V")
+	}
+	if g.FlippedDiagonally() {
+		fmt.Fprintf(&b, " D")
+	}
+	if g.RotatedHexagonal120() {
+		fmt.Fprintf(&b, " R120")
+	}
+
+	return b.String()
+}
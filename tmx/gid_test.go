@@ -0,0 +1,39 @@
+package tmx
+
+import "testing"
+
+func TestGIDFlags(t *testing.T) {
+	tests := []struct {
+		gid        GID
+		id         GID
+		h, v, d, r bool
+		s          string
+	}{
+		{0, 0, false, false, false, false, "0"},
+		{371, 371, false, false, false, false, "371"},
+		{2147483651, 3, true, false, false, false, "3 H"},
+		{1073741825, 1, false, true, false, false, "1 V"},
+		{536870914, 2, false, false, true, false, "2 D"},
+		{268435460, 4, false, false, false, true, "4 R120"},
+		// An anti-diagonal flip combines all three flips.
+		{3758096389, 5, true, true, true, false, "5 H V D"},
+	}
+
+	for _, test := range tests {
+		g := test.gid
+		if g.ID() != test.id {
+			t.Errorf("GID(%d).ID() = %d, want %d", uint32(g), g.ID(), test.id)
+		}
+		if g.FlippedHorizontally() != test.h || g.FlippedVertically() != test.v || g.FlippedDiagonally() != test.d ||
+			g.RotatedHexagonal120() != test.r {
+			t.Errorf("GID(%d) flips %t %t %t %t, want %t %t %t %t", uint32(g), g.FlippedHorizontally(),
+				g.FlippedVertically(), g.FlippedDiagonally(), g.RotatedHexagonal120(), test.h, test.v, test.d, test.r)
+		}
+		if g.Flags()|g.ID() != g {
+			t.Errorf("GID(%d).Flags() = %#x, want the flags of the GID", uint32(g), uint32(g.Flags()))
+		}
+		if g.String() != test.s {
+			t.Errorf("GID(%d).String() = %q, want %q", uint32(g), g.String(), test.s)
+		}
+	}
+}
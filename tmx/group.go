@@ -18,13 +18,13 @@ type Group struct {
 	Name string `xml:"name,attr"`
 
 	// Rendering offset of the group layer in pixels. Defaults to 0.
-	OffsetX int `xml:"offsetx,attr"`
+	OffsetX float32 `xml:"offsetx,attr"`
 
 	// Rendering offset of the group layer in pixels. Defaults to 0.
-	OffsetY int `xml:"offsety,attr"`
+	OffsetY float32 `xml:"offsety,attr"`
 
 	// The opacity of the layer as a value from 0 to 1. Defaults to 1.
-	Opacity bool `xml:"opacity,attr"`
+	Opacity float32 `xml:"opacity,attr"`
 
 	// Whether the layer is shown (1) or hidden (0). Defaults to 1.
 	Visible bool `xml:"visible,attr"`
@@ -33,34 +33,79 @@ type Group struct {
 	// opacity and visible recursively affect child layers.
 
 	// Can contain: <properties>, <layer>, <objectgroup>, <imagelayer>, <group>
-	Content []Content `xml:",any"`
+	Properties PropertySet `xml:"properties>property"`
+	Content    []Content   `xml:",any"`
 
-	// Properties  []*Property    `xml:"properties>property"`
 	// Layer       []*Layer       `xml:"layer"`
 	// ObjectGroup []*ObjectGroup `xml:"objectgroup"`
 	// ImageLayer  []*ImageLayer  `xml:"imagelayer"`
 	// Group       []*Group       `xml:"group"`
 }
 
+// PropertySet returns the custom properties of the group.
+func (g *Group) PropertySet() PropertySet {
+	return g.Properties
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (g *Group) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type group Group
+
+	// Groups are opaque and visible unless the attributes say otherwise.
+	g.Opacity = 1
+	g.Visible = true
+
+	return decoder.DecodeElement((*group)(g), &startElement)
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (g *Group) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.int("id", g.ID, 0)
+	a.str("name", g.Name, "")
+	a.float32("offsetx", g.OffsetX, 0)
+	a.float32("offsety", g.OffsetY, 0)
+	a.float32("opacity", g.Opacity, 1)
+	a.bool("visible", g.Visible, true)
+
+	start = startElement("group", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(propertyList(g.Properties))
+	if err != nil {
+		return err
+	}
+
+	err = encodeContent(encoder, g.Content)
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
 func (g *Group) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Group:\n")
-	fmt.Fprintf(&b, "\tID:      (%T) %q\n", g.ID, g.ID)
+	fmt.Fprintf(&b, "\tID:      (%T) %d\n", g.ID, g.ID)
 	fmt.Fprintf(&b, "\tName:    (%T) %q\n", g.Name, g.Name)
-	fmt.Fprintf(&b, "\tOffsetX: (%T) %d\n", g.OffsetX, g.OffsetX)
-	fmt.Fprintf(&b, "\tOffsetY: (%T) %d\n", g.OffsetY, g.OffsetY)
-	fmt.Fprintf(&b, "\tOpacity: (%T) %t\n", g.Opacity, g.Opacity)
+	fmt.Fprintf(&b, "\tOffsetX: (%T) %f\n", g.OffsetX, g.OffsetX)
+	fmt.Fprintf(&b, "\tOffsetY: (%T) %f\n", g.OffsetY, g.OffsetY)
+	fmt.Fprintf(&b, "\tOpacity: (%T) %f\n", g.Opacity, g.Opacity)
 	fmt.Fprintf(&b, "\tVisible: (%T) %t\n", g.Visible, g.Visible)
 
+	for _, property := range g.Properties {
+		fmt.Fprintf(&b, property.String())
+	}
+
 	for _, content := range g.Content {
 		fmt.Fprintf(&b, content.String())
 	}
 
-	// for _, property := range g.Properties {
-	// 	fmt.Fprintf(&b, property.String())
-	// }
-
 	// for _, layer := range g.Layer {
 	// 	fmt.Fprintf(&b, layer.String())
 	// }
@@ -20,15 +20,19 @@ type Image struct {
 	// The reference to the tileset image file (Tiled supports most common image formats).
 	Source string `xml:"source,attr"`
 
+	// The path of the image file, Source resolved relative to the file that references it. Not part of the spec, it
+	// is set when the map is loaded.
+	Path string `xml:"-"`
+
 	// Defines a specific color that is treated as transparent (example value: “#FF00FF” for magenta). Up until Tiled
 	// 0.12, this value is written out without a # but this is planned to change.
 	Trans string `xml:"trans,attr,omitempty"`
 
 	// The image width in pixels (optional, used for tile index correction when the image changes)
-	Width int `xml:"width,attr"`
+	Width int `xml:"width,attr,omitempty"`
 
 	// The image height in pixels (optional)
-	Height int `xml:"height,attr"`
+	Height int `xml:"height,attr,omitempty"`
 
 	// Note that it is not currently possible to use Tiled to create maps with embedded image data, even though the
 	// TMX format supports this. It is possible to create such maps using libtiled (Qt/C++) or tmxlib (Python).
@@ -42,6 +46,7 @@ func (i *Image) String() string {
 
 	fmt.Fprintf(&b, "Image:\n")
 	fmt.Fprintf(&b, "\tSource: (%T) %q\n", i.Source, i.Source)
+	fmt.Fprintf(&b, "\tPath:   (%T) %q\n", i.Path, i.Path)
 	fmt.Fprintf(&b, "\tTrans:  (%T) %q\n", i.Trans, i.Trans)
 	fmt.Fprintf(&b, "\tWidth:  (%T) %d\n", i.Width, i.Width)
 	fmt.Fprintf(&b, "\tHeight: (%T) %d\n", i.Height, i.Height)
@@ -18,10 +18,10 @@ type ImageLayer struct {
 	Name string `xml:"name,attr"`
 
 	// Rendering offset of the image layer in pixels. Defaults to 0. (since 0.15)
-	OffsetX int `xml:"offsetx,attr,omitempty"`
+	OffsetX float32 `xml:"offsetx,attr,omitempty"`
 
 	// Rendering offset of the image layer in pixels. Defaults to 0. (since 0.15)
-	OffsetY int `xml:"offsety,attr,omitempty"`
+	OffsetY float32 `xml:"offsety,attr,omitempty"`
 
 	// The x position of the image layer in pixels. (deprecated since 0.15)
 	// X       int      `xml:"x,attr"`
@@ -30,37 +30,78 @@ type ImageLayer struct {
 	// Y       int      `xml:"y,attr"`
 
 	// The opacity of the layer as a value from 0 to 1. Defaults to 1.
-	Opacity int `xml:"opacity,attr,omitempty"`
+	Opacity float32 `xml:"opacity,attr,omitempty"`
 
 	// Whether the layer is shown (1) or hidden (0). Defaults to 1.
 	Visible int `xml:"visible,attr"`
 
+	// Whether the layer is locked in the editor (1) or not (0). Defaults to 0. (since 1.2)
 	Locked int `xml:"locked,attr"`
 
 	// A layer consisting of a single image.
 
 	// Can contain: <properties>, <image>
-	Properties *Properties `xml:"properties,omitempty"`
+	Properties PropertySet `xml:"properties>property"`
 	Image      *Image      `xml:"image,omitempty"`
 }
 
+// PropertySet returns the custom properties of the image layer.
+func (i *ImageLayer) PropertySet() PropertySet {
+	return i.Properties
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (i *ImageLayer) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type imageLayer ImageLayer
+
+	// Image layers are opaque and visible unless the attributes say otherwise.
+	i.Opacity = 1
+	i.Visible = 1
+
+	return decoder.DecodeElement((*imageLayer)(i), &startElement)
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (i *ImageLayer) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.int("id", i.ID, 0)
+	a.str("name", i.Name, "")
+	a.float32("offsetx", i.OffsetX, 0)
+	a.float32("offsety", i.OffsetY, 0)
+	a.float32("opacity", i.Opacity, 1)
+	a.int("visible", i.Visible, 1)
+	a.int("locked", i.Locked, 0)
+
+	start = startElement("imagelayer", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encodeElements(encoder, []interface{}{
+		propertyList(i.Properties),
+		i.Image,
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
 func (i *ImageLayer) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "ImageLayer:\n")
 	fmt.Fprintf(&b, "\tID:      (%T) %d\n", i.ID, i.ID)
 	fmt.Fprintf(&b, "\tName:    (%T) %q\n", i.Name, i.Name)
-	fmt.Fprintf(&b, "\tOffsetX: (%T) %d\n", i.OffsetX, i.OffsetX)
-	fmt.Fprintf(&b, "\tOffsetY: (%T) %d\n", i.OffsetY, i.OffsetY)
-	fmt.Fprintf(&b, "\tOpacity: (%T) %d\n", i.Opacity, i.Opacity)
+	fmt.Fprintf(&b, "\tOffsetX: (%T) %f\n", i.OffsetX, i.OffsetX)
+	fmt.Fprintf(&b, "\tOffsetY: (%T) %f\n", i.OffsetY, i.OffsetY)
+	fmt.Fprintf(&b, "\tOpacity: (%T) %f\n", i.Opacity, i.Opacity)
 	fmt.Fprintf(&b, "\tVisible: (%T) %d\n", i.Visible, i.Visible)
 
-	// for _, property := range i.Properties. {
-	// 	fmt.Fprintf(&b, property.String())
-	// }
-
-	if i.Properties != nil {
-		fmt.Fprintf(&b, i.Properties.String())
+	for _, property := range i.Properties {
+		fmt.Fprintf(&b, property.String())
 	}
 
 	if i.Image != nil {
@@ -0,0 +1,281 @@
+package tmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON Map Format https://doc.mapeditor.org/en/stable/reference/json-map-format/
+
+// The JSON format is converted to the same structures as the TMX format, so it does not matter which format a map
+// was saved in.
+
+// isJSONFile reports whether a file is in the JSON format, judging by its extension.
+func isJSONFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".tmj", ".tsj", ".tj":
+		return true
+	}
+	return false
+}
+
+// jsonVersion is a version that older versions of Tiled saved as a number and newer versions save as a string.
+type jsonVersion string
+
+// UnmarshalJSON accepts a version stored as a string or as a number.
+func (v *jsonVersion) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*v = jsonVersion(s)
+		return nil
+	}
+
+	var n json.Number
+	err := json.Unmarshal(data, &n)
+	if err != nil {
+		return fmt.Errorf("error decoding version: %w", err)
+	}
+	*v = jsonVersion(n.String())
+
+	return nil
+}
+
+// jsonProperty structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#property
+type jsonProperty struct {
+	Name         string          `json:"name"`
+	Type         string          `json:"type"`
+	PropertyType string          `json:"propertytype"`
+	Value        json.RawMessage `json:"value"`
+}
+
+// jsonProperties is a list of properties, saved as an array since Tiled 1.2 and as an object of names and values
+// before that.
+type jsonProperties []*jsonProperty
+
+// UnmarshalJSON accepts properties stored as an array or as an object.
+func (p *jsonProperties) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return json.Unmarshal(data, (*[]*jsonProperty)(p))
+	}
+
+	var values map[string]json.RawMessage
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		*p = append(*p, &jsonProperty{Name: name, Type: jsonValueType(values[name]), Value: values[name]})
+	}
+
+	return nil
+}
+
+// jsonValueType guesses the property type of a value saved without one.
+func jsonValueType(value json.RawMessage) string {
+	s := string(bytes.TrimSpace(value))
+	switch {
+	case s == "true" || s == "false":
+		return PropertyTypeBool
+	case strings.HasPrefix(s, `"`):
+		return PropertyTypeString
+	case strings.ContainsAny(s, ".eE"):
+		return PropertyTypeFloat
+	}
+	return PropertyTypeInt
+}
+
+// properties converts the list to the properties of an element.
+func (p jsonProperties) properties() PropertySet {
+	if len(p) == 0 {
+		return nil
+	}
+
+	properties := make(PropertySet, 0, len(p))
+	for _, jp := range p {
+		properties = append(properties, jp.property())
+	}
+
+	return properties
+}
+
+// property converts a json property to a property.
+func (p *jsonProperty) property() *Property {
+	property := &Property{Name: p.Name, Type: p.Type, PropertyType: p.PropertyType}
+
+	// String is the default type, it is left out the same way as in the TMX format.
+	if property.Type == PropertyTypeString {
+		property.Type = ""
+	}
+
+	if property.Type != PropertyTypeClass {
+		property.Value = jsonValueString(p.Value)
+		return property
+	}
+
+	// The members of a class are saved as an object of names and values, the types of the members are only known
+	// from the class in the project file, until then they are guessed from the values.
+	var members jsonProperties
+	if json.Unmarshal(p.Value, &members) == nil {
+		for _, member := range members {
+			if bytes.HasPrefix(bytes.TrimSpace(member.Value), []byte("{")) {
+				member.Type = PropertyTypeClass
+			}
+		}
+		property.Properties = members.properties()
+	}
+
+	return property
+}
+
+// jsonValueString returns a json value as the string it would be in the TMX format.
+func jsonValueString(value json.RawMessage) string {
+	var s string
+	if json.Unmarshal(value, &s) == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(value))
+}
+
+// jsonKeys returns the keys of a json object, used to tell which values were saved.
+func jsonKeys(data []byte) (map[string]bool, error) {
+	var values map[string]json.RawMessage
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(values))
+	for key := range values {
+		keys[key] = true
+	}
+
+	return keys, nil
+}
+
+// jsonPointsPoints converts json points to the points of a polygon or polyline.
+func jsonPointsPoints(points []jsonPoint) Points {
+	p := make(Points, len(points))
+	for i, point := range points {
+		p[i] = Vertex{X: point.X, Y: point.Y}
+	}
+	return p
+}
+
+// jsonPoint structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#point
+type jsonPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// jsonObjectValue is a json object written by the JSON writer. Tiled writes the keys of json objects in alphabetical
+// order, the same order encoding/json writes the keys of a map in.
+type jsonObjectValue map[string]interface{}
+
+// jsonPropertiesValue converts properties to the json array of properties, nil when there are no properties. Class
+// members with their default value are left out.
+func jsonPropertiesValue(properties PropertySet) []jsonObjectValue {
+	properties = properties.saved()
+	if len(properties) == 0 {
+		return nil
+	}
+
+	values := make([]jsonObjectValue, 0, len(properties))
+	for _, property := range properties {
+		propertyType := property.Type
+		if propertyType == "" {
+			propertyType = PropertyTypeString
+		}
+
+		v := jsonObjectValue{
+			"name":  property.Name,
+			"type":  propertyType,
+			"value": jsonPropertyValue(property),
+		}
+		v.setNotEmpty("propertytype", property.PropertyType)
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// jsonPropertyValue converts a property value to the json type of the property type, the value of a class is an
+// object of the names and values of its members.
+func jsonPropertyValue(property *Property) interface{} {
+	value := property.Value
+
+	switch property.Type {
+	case PropertyTypeClass:
+		members := jsonObjectValue{}
+		for _, member := range property.Properties.saved() {
+			members[member.Name] = jsonPropertyValue(member)
+		}
+		return members
+	case PropertyTypeInt, PropertyTypeObject:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case PropertyTypeFloat:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case PropertyTypeBool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// setNotEmpty sets a key unless the value is empty, the same way Tiled leaves out optional values.
+func (v jsonObjectValue) setNotEmpty(key string, value interface{}) {
+	switch x := value.(type) {
+	case string:
+		if x == "" {
+			return
+		}
+	case int:
+		if x == 0 {
+			return
+		}
+	case float32:
+		if x == 0 {
+			return
+		}
+	case float64:
+		if x == 0 {
+			return
+		}
+	case []jsonObjectValue:
+		if len(x) == 0 {
+			return
+		}
+	}
+	v[key] = value
+}
+
+// writeJSON writes a json value indented the way Tiled writes json files.
+func writeJSON(w io.Writer, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", " ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+
+	return err
+}
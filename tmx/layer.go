@@ -3,6 +3,7 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -45,8 +46,115 @@ type Layer struct {
 	OffsetY float32 `xml:"offsety,attr"`
 
 	// Can contain: <properties>, <data>
-	Properties []*Property `xml:"properties>property"`
+	Properties PropertySet `xml:"properties>property"`
 	Data       *Data       `xml:"data"`
+
+	// The decoded global tile IDs of the layer, row by row, Width * Height in length. Not part of the spec, it is
+	// populated from Data when the layer is unmarshalled.
+	GID []GID `xml:"-"`
+
+	// The tile grid returned by TileGrid, built by IndexTiles.
+	grid TileGrid
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (l *Layer) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type layer Layer
+
+	// Layers are opaque and visible unless the attributes say otherwise.
+	l.Opacity = 1
+	l.Visible = true
+
+	err := decoder.DecodeElement((*layer)(l), &startElement)
+	if err != nil {
+		return err
+	}
+
+	if l.Data == nil {
+		return nil
+	}
+
+	// Infinite maps store their tiles in chunks instead.
+	if len(l.Data.Chunk) > 0 {
+		err = l.decodeChunks()
+		if err != nil {
+			return err
+		}
+		l.IndexTiles()
+		return nil
+	}
+
+	l.GID, err = l.Data.decode(l.Width * l.Height)
+	if err != nil {
+		return fmt.Errorf("error decoding layer %q: %w", l.Name, err)
+	}
+	l.IndexTiles()
+
+	return nil
+}
+
+// EncodeData regenerates the layer data from GID, or from the GID of each chunk on infinite maps, with a new encoding
+// and compression. Use it before saving to choose how tile data is stored, for example csv for readable diffs or
+// base64 with zstd compression for smaller files. Level is the compression level, pass Map.CompressionLevel to
+// compress the data the way the map asks for.
+func (l *Layer) EncodeData(encoding string, compression string, level int) error {
+	if l.Data == nil {
+		l.Data = &Data{}
+	}
+
+	if len(l.Data.Chunk) == 0 {
+		err := l.Data.Encode(l.GID, l.Width, encoding, compression, level)
+		if err != nil {
+			return fmt.Errorf("error encoding layer %q: %w", l.Name, err)
+		}
+		return nil
+	}
+
+	for _, chunk := range l.Data.Chunk {
+		innerXML, tiles, err := encodeGIDs(chunk.GID, chunk.Width, encoding, compression, level)
+		if err != nil {
+			return fmt.Errorf("error encoding layer %q chunk %d,%d: %w", l.Name, chunk.X, chunk.Y, err)
+		}
+		chunk.InnerXML = innerXML
+		chunk.Tile = tiles
+	}
+
+	l.Data.Encoding = encoding
+	l.Data.Compression = compression
+	l.Data.InnerXML = ""
+
+	return nil
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (l *Layer) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.add("id", strconv.Itoa(l.ID))
+	a.add("name", l.Name)
+	a.int("x", l.X, 0)
+	a.int("y", l.Y, 0)
+	a.add("width", strconv.Itoa(l.Width))
+	a.add("height", strconv.Itoa(l.Height))
+	a.float32("opacity", l.Opacity, 1)
+	a.bool("visible", l.Visible, true)
+	a.float32("offsetx", l.OffsetX, 0)
+	a.float32("offsety", l.OffsetY, 0)
+
+	start = startElement("layer", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encodeElements(encoder, []interface{}{
+		propertyList(l.Properties),
+		l.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
 }
 
 func (l *Layer) String() string {
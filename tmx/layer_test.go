@@ -0,0 +1,107 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// chunkedLayer is a layer of an infinite map with two chunks, one of them starting left of the origin.
+const chunkedLayer = `<layer id="1" name="chunks" width="4" height="2">
+ <data encoding="csv">
+  <chunk x="-2" y="0" width="2" height="2">1,2,0,2147483651</chunk>
+  <chunk x="0" y="0" width="2" height="2">0,0,0,4</chunk>
+ </data>
+</layer>`
+
+// reencodeLayer re-encodes the data of a layer, marshals the layer and unmarshals it again.
+func reencodeLayer(t *testing.T, data string, encoding string, compression string, level int) (*Layer, string) {
+	t.Helper()
+
+	layer := &Layer{}
+	err := xml.Unmarshal([]byte(data), layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = layer.EncodeData(encoding, compression, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := xml.Marshal(layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &Layer{}
+	err = xml.Unmarshal(written, loaded)
+	if err != nil {
+		t.Fatalf("decoding the re-encoded layer: %v\n%s", err, written)
+	}
+	return loaded, string(written)
+}
+
+func TestLayerEncodeData(t *testing.T) {
+	for _, test := range dataLayers {
+		t.Run(test.encoding+" "+test.compression, func(t *testing.T) {
+			// The fixed size layer starts out as csv, the chunks as xml tiles.
+			fixed := dataLayer(EncodingCSV, "", "1,2,0,2147483651")
+			loaded, written := reencodeLayer(t, fixed, test.encoding, test.compression, -1)
+			if loaded.Data.Encoding != test.encoding || loaded.Data.Compression != test.compression {
+				t.Errorf("written with %q %q, want %q %q:\n%s", loaded.Data.Encoding, loaded.Data.Compression,
+					test.encoding, test.compression, written)
+			}
+			if !equalGIDs(loaded.GID, dataGIDs) {
+				t.Errorf("GID = %v, want %v", loaded.GID, dataGIDs)
+			}
+
+			loaded, written = reencodeLayer(t, chunkedLayer, test.encoding, test.compression, -1)
+			chunks := loaded.Data.Chunk
+			if len(chunks) != 2 || chunks[0].X != -2 || chunks[1].Width != 2 || loaded.Data.Encoding != test.encoding {
+				t.Fatalf("chunks were not written back:\n%s", written)
+			}
+			if !equalGIDs(chunks[0].GID, dataGIDs) || !equalGIDs(chunks[1].GID, []GID{0, 0, 0, 4}) {
+				t.Errorf("chunk GID = %v and %v, want %v and [0 0 0 4]", chunks[0].GID, chunks[1].GID, dataGIDs)
+			}
+			if test.encoding != "" && strings.Contains(written, "<tile") {
+				t.Errorf("%s data is written with tile elements:\n%s", test.encoding, written)
+			}
+		})
+	}
+}
+
+func TestLayerEncodeDataLevel(t *testing.T) {
+	layer := &Layer{Name: "ground", Width: 32, Height: 32, GID: make([]GID, 32*32)}
+	for i := range layer.GID {
+		layer.GID[i] = GID((i*i/5 + i/3) % 11)
+	}
+
+	for _, compression := range []string{CompressionGzip, CompressionZlib, CompressionZstd} {
+		sizes := map[int]int{}
+		for _, level := range []int{-1, 1, 9} {
+			err := layer.EncodeData(EncodingBase64, compression, level)
+			if err != nil {
+				t.Fatalf("%s level %d: %v", compression, level, err)
+			}
+			sizes[level] = len(layer.Data.InnerXML)
+
+			gids, err := layer.Data.decode(len(layer.GID))
+			if err != nil || !equalGIDs(gids, layer.GID) {
+				t.Errorf("%s level %d data does not decode to the layer tiles: %v", compression, level, err)
+			}
+		}
+
+		// The fastest level compresses the tiles less than the best one.
+		if sizes[1] <= sizes[9] {
+			t.Errorf("%s data is %d bytes at level 1 and %d bytes at level 9", compression, sizes[1], sizes[9])
+		}
+	}
+
+	if err := layer.EncodeData(EncodingBase64, CompressionGzip, 10); err == nil {
+		t.Error("EncodeData with gzip level 10 did not return an error")
+	}
+	if err := layer.EncodeData(EncodingCSV, CompressionZlib, -1); err == nil {
+		t.Error("EncodeData of compressed csv did not return an error")
+	}
+}
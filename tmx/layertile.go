@@ -8,7 +8,7 @@ import (
 // LayerTile structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#tmx-tilelayer-tile
 type LayerTile struct {
 	// The global tile ID (default: 0).
-	GID []int
+	GID GID `xml:"gid,attr"`
 
 	// Not to be confused with the tile element inside a tileset, this element defines the value of a single tile on a
 	// tile layer. This is however the most inefficient way of storing the tile layer data, and should generally be
@@ -19,7 +19,7 @@ func (t *LayerTile) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "LayerTile:\n")
-	fmt.Fprintf(&b, "\tGID: (%T) %q\n", t.GID, t.GID)
+	fmt.Fprintf(&b, "\tGID: (%T) %d\n", t.GID, t.GID)
 
 	return b.String()
 }
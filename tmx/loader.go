@@ -0,0 +1,253 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// loader holds the state of a single load, so multiple maps can be loaded concurrently.
+type loader struct {
+	// The resolver used to find and open referenced files.
+	resolver Resolver
+
+	// The path of the file being loaded, relative sources are resolved against it.
+	base string
+
+	// The map being loaded, nil when loading a tileset on its own.
+	m *Map
+
+	// Templates already loaded, by path, shared by every instance.
+	templates map[string]*Template
+}
+
+// newLoader returns a loader for the file at base.
+func newLoader(resolver Resolver, base string) *loader {
+	return &loader{
+		resolver:  resolver,
+		base:      base,
+		templates: make(map[string]*Template),
+	}
+}
+
+// loadMap resolves the tilesets, templates, images and file properties referenced by the map.
+func (l *loader) loadMap(m *Map) error {
+	l.m = m
+	l.resolvePropertyList(m.Properties, l.base)
+
+	err := l.loadContent(m.Content)
+	if err != nil {
+		return err
+	}
+
+	m.IndexTilesets()
+
+	return nil
+}
+
+// loadContent resolves the tilesets, images and file properties referenced by Map.Content and Group.Content.
+func (l *loader) loadContent(content []Content) error {
+	for _, c := range content {
+		switch v := c.Value.(type) {
+		case *Tileset:
+			err := l.loadTileset(v, l.base)
+			if err != nil {
+				return err
+			}
+		case *Layer:
+			l.resolvePropertyList(v.Properties, l.base)
+		case *ObjectGroup:
+			err := l.loadObjectGroup(v, l.base)
+			if err != nil {
+				return err
+			}
+		case *ImageLayer:
+			l.resolvePropertyList(v.Properties, l.base)
+			l.resolveImage(v.Image, l.base)
+		case *Group:
+			l.resolvePropertyList(v.Properties, l.base)
+			err := l.loadContent(v.Content)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadTileset unmarshals an external tileset into tileset, and resolves the files referenced by the tileset. The
+// tileset is referenced from the file at base.
+func (l *loader) loadTileset(tileset *Tileset, base string) error {
+	// External Tileset
+	// Update any tileset and image sources that come from external tsx files.
+	if tileset.Source != "" {
+		tileset.Path = l.resolver.Resolve(base, tileset.Source)
+
+		// Unmarshal a tsx path.
+		tsxBytes, err := readFile(l.resolver, tileset.Path)
+		if err != nil {
+			return fmt.Errorf("error reading tsx file: %w", err)
+		}
+		if isJSONFile(tileset.Path) {
+			err = unmarshalExternalTSJ(tsxBytes, tileset)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = xml.Unmarshal(tsxBytes, tileset)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling tsx bytes: %w", err)
+			}
+		}
+
+		// Files referenced by an external tileset are relative to the tsx file.
+		base = tileset.Path
+	}
+
+	return l.resolveTileset(tileset, base)
+}
+
+// resolveTileset resolves the images, templates and file properties of a tileset against base.
+func (l *loader) resolveTileset(tileset *Tileset, base string) error {
+	l.resolvePropertyList(tileset.Properties, base)
+	l.resolveImage(tileset.Image, base)
+
+	if tileset.TerrainTypes != nil {
+		for _, terrain := range tileset.TerrainTypes.Terrain {
+			l.resolvePropertyList(terrain.Properties, base)
+		}
+	}
+
+	if tileset.Wangsets != nil {
+		for _, wangset := range tileset.Wangsets.Wangset {
+			l.resolvePropertyList(wangset.Properties, base)
+			for _, color := range wangset.WangColor {
+				l.resolvePropertyList(color.Properties, base)
+			}
+		}
+	}
+
+	for _, tile := range tileset.Tile {
+		l.resolvePropertyList(tile.Properties, base)
+		l.resolveImage(tile.Image, base)
+		for _, objectGroup := range tile.ObjectGroup {
+			err := l.loadObjectGroup(objectGroup, base)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadObjectGroup loads the templates of the objects in an object group, and resolves the file properties of the
+// object group and its objects against base.
+func (l *loader) loadObjectGroup(objectGroup *ObjectGroup, base string) error {
+	l.resolvePropertyList(objectGroup.Properties, base)
+
+	for _, object := range objectGroup.Object {
+		l.resolvePropertyList(object.Properties, base)
+		l.resolveImage(object.Image, base)
+
+		if object.Template == "" {
+			continue
+		}
+
+		template, err := l.loadTemplate(l.resolver.Resolve(base, object.Template))
+		if err != nil {
+			return err
+		}
+
+		object.applyTemplate(template)
+	}
+
+	return nil
+}
+
+// loadTemplate unmarshals a template file, and adds the tileset of the template to the GID space of the map.
+func (l *loader) loadTemplate(name string) (*Template, error) {
+	if template, ok := l.templates[name]; ok {
+		return template, nil
+	}
+
+	txBytes, err := readFile(l.resolver, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template file: %w", err)
+	}
+
+	var template *Template
+	if isJSONFile(name) {
+		template, err = unmarshalTJ(txBytes)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		template = &Template{}
+		err = xml.Unmarshal(txBytes, template)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling template bytes: %w", err)
+		}
+	}
+	template.Path = name
+
+	if template.Tileset != nil {
+		err = l.loadTileset(template.Tileset, name)
+		if err != nil {
+			return nil, err
+		}
+		if l.m != nil {
+			template.mapTileset = l.m.addTemplateTileset(template.Tileset)
+		}
+	}
+
+	if template.Object != nil {
+		l.resolvePropertyList(template.Object.Properties, name)
+		l.resolveImage(template.Object.Image, name)
+	}
+
+	l.templates[name] = template
+
+	return template, nil
+}
+
+// resolveImage sets the resolved path of an image referenced from base.
+func (l *loader) resolveImage(img *Image, base string) {
+	if img == nil {
+		return
+	}
+	img.Path = l.resolver.Resolve(base, img.Source)
+}
+
+// resolvePropertyList sets the resolved path of the file properties referenced from base.
+func (l *loader) resolvePropertyList(properties PropertySet, base string) {
+	for _, property := range properties {
+		l.resolveProperty(property, base)
+	}
+}
+
+// resolveProperty sets the resolved path of a file property referenced from base, or of the file members of a class
+// property.
+func (l *loader) resolveProperty(property *Property, base string) {
+	l.resolvePropertyList(property.Properties, base)
+
+	if property.Type != PropertyTypeFile || property.Value == "" {
+		return
+	}
+	property.Path = l.resolver.Resolve(base, property.Value)
+}
+
+// unmarshalExternalTSJ unmarshals the json bytes of an external tileset into the tileset of a map, keeping the
+// attributes that belong to the map.
+func unmarshalExternalTSJ(data []byte, tileset *Tileset) error {
+	external, err := unmarshalTSJ(data)
+	if err != nil {
+		return err
+	}
+
+	external.FirstGID = tileset.FirstGID
+	external.Source = tileset.Source
+	external.Path = tileset.Path
+	*tileset = *external
+
+	return nil
+}
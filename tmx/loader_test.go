@@ -0,0 +1,190 @@
+package tmx
+
+import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"testing"
+	"testing/fstest"
+)
+
+// memoryFiles is a map with an external tileset, an image layer, a file property and two instances of a template
+// that has a tileset of its own, in the directory layout of a game.
+func memoryFiles() MapResolver {
+	return MapResolver{
+		"maps/level.tmx": []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="2" height="2" tilewidth="16" tileheight="16" nextlayerid="4" nextobjectid="3">
+ <properties>
+  <property name="data" type="file" value="data/level.json"/>
+ </properties>
+ <tileset firstgid="1" source="../tilesets/terrain.tsx"/>
+ <layer id="1" name="ground" width="2" height="2">
+  <data encoding="csv">1,2,3,4</data>
+ </layer>
+ <imagelayer id="2" name="background">
+  <image source="bg.png" width="32" height="32"/>
+ </imagelayer>
+ <objectgroup id="3" name="objects">
+  <object id="1" template="../templates/chest.tx" x="8" y="24"/>
+  <object id="2" template="../templates/chest.tx" name="open chest" x="24" y="24"/>
+ </objectgroup>
+</map>`),
+		"tilesets/terrain.tsx": []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="terrain" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="terrain.png" width="32" height="32"/>
+</tileset>`),
+		"tilesets/terrain.png": []byte("terrain pixels"),
+		"tilesets/items.tsx": []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" name="items" tilewidth="16" tileheight="16" tilecount="4" columns="4">
+ <image source="items.png" width="64" height="16"/>
+</tileset>`),
+		"tilesets/items.png": []byte("item pixels"),
+		"templates/chest.tx": []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<template>
+ <tileset firstgid="1" source="../tilesets/items.tsx"/>
+ <object name="chest" type="container" gid="2" width="16" height="16">
+  <properties>
+   <property name="loot" value="gold"/>
+  </properties>
+ </object>
+</template>`),
+		"maps/bg.png": []byte("background pixels"),
+	}
+}
+
+func TestLoadTMXResolver(t *testing.T) {
+	tmx, err := LoadTMXResolver(memoryFiles(), "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tmx.Map
+
+	ref, err := m.ResolveGID(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Tileset.Name != "terrain" || ref.Tileset.Path != "tilesets/terrain.tsx" || ref.Tileset.FirstGID != 1 {
+		t.Errorf("gid 3 is in tileset %q at %q, want terrain at tilesets/terrain.tsx", ref.Tileset.Name,
+			ref.Tileset.Path)
+	}
+	if ref.Image == nil || ref.Image.Path != "tilesets/terrain.png" {
+		t.Errorf("tileset image %v, want tilesets/terrain.png", ref.Image)
+	}
+
+	file, err := m.Properties.File("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file != "maps/data/level.json" {
+		t.Errorf("file property %q, want maps/data/level.json", file)
+	}
+
+	var imageLayer *ImageLayer
+	var objectGroup *ObjectGroup
+	for _, c := range m.Content {
+		switch v := c.Value.(type) {
+		case *ImageLayer:
+			imageLayer = v
+		case *ObjectGroup:
+			objectGroup = v
+		}
+	}
+	if imageLayer == nil || objectGroup == nil || len(objectGroup.Object) != 2 {
+		t.Fatal("map is missing its image layer or objects")
+	}
+	if imageLayer.Image.Path != "maps/bg.png" {
+		t.Errorf("image layer image %q, want maps/bg.png", imageLayer.Image.Path)
+	}
+
+	// Images are opened with the resolver the map was loaded with.
+	rc, err := tmx.Open(imageLayer.Image.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(rc)
+	rc.Close()
+	if err != nil || string(data) != "background pixels" {
+		t.Errorf("opened image %q, %v", data, err)
+	}
+
+	chest, open := objectGroup.Object[0], objectGroup.Object[1]
+	if chest.Name != "chest" || chest.Type != "container" || chest.Width != 16 || chest.X != 8 {
+		t.Errorf("template instance %q %q %vx%v at %v, want the values of the template", chest.Name, chest.Type,
+			chest.Width, chest.Height, chest.X)
+	}
+	if open.Name != "open chest" {
+		t.Errorf("template instance name %q, want the name saved with the object", open.Name)
+	}
+	template := chest.TemplateRef
+	if template == nil || template != open.TemplateRef || template.Path != "templates/chest.tx" {
+		t.Error("template instances do not share the template loaded from templates/chest.tx")
+	}
+	if loot, err := open.Properties.StringValue("loot"); err != nil || loot != "gold" {
+		t.Errorf("template property loot = %q, %v, want gold", loot, err)
+	}
+
+	// The tileset of the template follows the tilesets of the map in its GID space.
+	if chest.GID != 6 {
+		t.Errorf("template instance gid %d, want 6", chest.GID)
+	}
+	ref, err = m.ResolveGID(chest.GID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Tileset.Name != "items" || ref.ID != 1 || ref.Image.Path != "tilesets/items.png" {
+		t.Errorf("template gid is tile %d of %q with image %q, want tile 1 of items", ref.ID, ref.Tileset.Name,
+			ref.Image.Path)
+	}
+}
+
+func TestLoadTMXFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, data := range memoryFiles() {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+
+	tmx, err := LoadTMXFS(fsys, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := tmx.Map.ResolveGID(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Tileset.Name != "items" || ref.Image.Path != "tilesets/items.png" {
+		t.Errorf("gid 6 is in tileset %q with image %q, want items", ref.Tileset.Name, ref.Image.Path)
+	}
+}
+
+func TestLoadTMXResolverMissingFile(t *testing.T) {
+	files := memoryFiles()
+	delete(files, "templates/chest.tx")
+
+	_, err := LoadTMXResolver(files, "maps/level.tmx")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("loading a map with a missing template returned %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestLoadTMXTestdata(t *testing.T) {
+	tmx, err := LoadTMX("../examples/testdata/map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := tmx.Map.ResolveGID(371)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Tileset.TileWidth != 16 || ref.Tileset.Columns != 40 || ref.ID != 370 {
+		t.Errorf("gid 371 is tile %d of a tileset with %dpx tiles and %d columns", ref.ID, ref.Tileset.TileWidth,
+			ref.Tileset.Columns)
+	}
+
+	rc, err := tmx.Open(ref.Image.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+}
@@ -3,6 +3,8 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -10,6 +12,7 @@ import (
 const (
 	OrientationOrthogonal string = "orthogonal"
 	OrientationIsometric  string = "isometric"
+	OrientationStaggered  string = "staggered"
 	OrientationHexagonal  string = "hexagonal"
 
 	RenderOrderRightDown string = "right-down"
@@ -17,6 +20,9 @@ const (
 	RenderOrderLeftDown  string = "left-down"
 	RenderOrderLeftUp    string = "left-up"
 
+	StaggerAxisX string = "x"
+	StaggerAxisY string = "y"
+
 	StaggerIndexOdd  string = "odd"
 	StaggerIndexEven string = "even"
 )
@@ -39,6 +45,10 @@ type Map struct {
 	// moment)
 	RenderOrder string `xml:"renderorder,attr"`
 
+	// The compression level to use for tile layer data (defaults to -1, which means to use the algorithm default).
+	// (since 1.3)
+	CompressionLevel int `xml:"compressionlevel,attr"`
+
 	// The map width in tiles.
 	Width int `xml:"width,attr"`
 
@@ -85,14 +95,256 @@ type Map struct {
 	// The staggered orientation refers to an isometric map using staggered axes.
 
 	// Can contain: <properties>, <tileset>, <layer>, <objectgroup>, <imagelayer>, <group> (since 1.0)
-	Content []Content `xml:",any"`
+	Properties PropertySet `xml:"properties>property"`
+	Content    []Content   `xml:",any"`
 
-	// Properties  []*Property    `xml:"properties>property"`
 	// Tileset     []*Tileset     `xml:"tileset"`
 	// Layer       []*Layer       `xml:"layer"`
 	// ObjectGroup []*ObjectGroup `xml:"objectgroup"`
 	// ImageLayer  []*ImageLayer  `xml:"imagelayer"`
 	// Group       []*Group       `xml:"group"`
+
+	// Tilesets of Content sorted by FirstGID, built when the map is unmarshalled.
+	tilesets []*Tileset
+
+	// Tilesets of object templates that are not tilesets of the map, added to the GID space after the map tilesets.
+	templateTilesets []*Tileset
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (m *Map) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type tmxMap Map
+
+	m.CompressionLevel = -1
+
+	err := decoder.DecodeElement((*tmxMap)(m), &startElement)
+	if err != nil {
+		return err
+	}
+
+	m.IndexTilesets()
+
+	return nil
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (m *Map) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.add("version", m.Version)
+	a.str("tiledversion", m.TiledVersion, "")
+	a.add("orientation", m.Orientation)
+	a.str("renderorder", m.RenderOrder, "")
+	a.int("compressionlevel", m.CompressionLevel, -1)
+	a.add("width", strconv.Itoa(m.Width))
+	a.add("height", strconv.Itoa(m.Height))
+	a.add("tilewidth", strconv.Itoa(m.TileWidth))
+	a.add("tileheight", strconv.Itoa(m.TileHeight))
+	a.int("hexsidelength", m.HexSideLength, 0)
+	a.str("staggeraxis", m.StaggerAxis, "")
+	a.str("staggerindex", m.StaggerIndex, "")
+	a.str("backgroundcolor", m.BackgroundColor, "")
+	a.add("infinite", strconv.Itoa(m.Infinite))
+	a.int("nextlayerid", m.NextLayerID, 0)
+	a.add("nextobjectid", strconv.Itoa(m.NextObjectID))
+
+	start = startElement("map", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(propertyList(m.Properties))
+	if err != nil {
+		return err
+	}
+
+	err = encodeContent(encoder, m.Content)
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
+// IndexTilesets builds the index of tilesets sorted by FirstGID used to resolve global tile IDs. It is called when
+// the map is loaded, call it again after adding, removing or renumbering tilesets.
+func (m *Map) IndexTilesets() {
+	m.tilesets = m.tilesets[:0]
+	for _, c := range m.Content {
+		if tileset, ok := c.Value.(*Tileset); ok {
+			m.tilesets = append(m.tilesets, tileset)
+		}
+	}
+	m.tilesets = append(m.tilesets, m.templateTilesets...)
+
+	sort.SliceStable(m.tilesets, func(i, j int) bool {
+		return m.tilesets[i].FirstGID < m.tilesets[j].FirstGID
+	})
+}
+
+// addTemplateTileset returns the tileset of the map with the same source as the tileset of a template. When the map
+// does not use the tileset, a copy of it is added to the GID space of the map after the last tileset.
+func (m *Map) addTemplateTileset(tileset *Tileset) *Tileset {
+	nextGID := 1
+	for _, t := range m.tilesets {
+		if tileset.Path != "" && t.Path == tileset.Path {
+			return t
+		}
+		if last := t.FirstGID + t.tileIDCount(); last > nextGID {
+			nextGID = last
+		}
+	}
+	for _, t := range m.templateTilesets {
+		if tileset.Path != "" && t.Path == tileset.Path {
+			return t
+		}
+		if last := t.FirstGID + t.tileIDCount(); last > nextGID {
+			nextGID = last
+		}
+	}
+
+	mapTileset := *tileset
+	mapTileset.FirstGID = nextGID
+	m.templateTilesets = append(m.templateTilesets, &mapTileset)
+	m.IndexTilesets()
+
+	return &mapTileset
+}
+
+// ResolveGID finds the tileset with the highest FirstGID that is lower or equal than the global tile ID, and returns
+// a TileRef describing the tile within that tileset. Flip flags are ignored.
+func (m *Map) ResolveGID(gid GID) (*TileRef, error) {
+	id := int(gid.ID())
+	if id == 0 {
+		return nil, fmt.Errorf("error resolving gid %d: empty tile", id)
+	}
+
+	// Index of the first tileset with a FirstGID greater than the gid.
+	i := sort.Search(len(m.tilesets), func(i int) bool {
+		return m.tilesets[i].FirstGID > id
+	})
+	if i == 0 {
+		return nil, fmt.Errorf("error resolving gid %d: no tileset found", id)
+	}
+
+	tileset := m.tilesets[i-1]
+	localID := id - tileset.FirstGID
+	rect, img := tileset.TileRect(localID)
+
+	return &TileRef{
+		Tileset: tileset,
+		ID:      localID,
+		Tile:    tileset.TileByID(localID),
+		Image:   img,
+		Rect:    rect,
+	}, nil
+}
+
+// PropertySet returns the custom properties of the map.
+func (m *Map) PropertySet() PropertySet {
+	return m.Properties
+}
+
+// ObjectByID returns the object with an ID, searching every object group of the map and its groups, nil when there
+// is none. Object properties refer to objects by their ID.
+func (m *Map) ObjectByID(id int) *Object {
+	return objectByID(m.Content, id)
+}
+
+// ObjectVertices returns the vertices of the shape of an object relative to its position like Object.Vertices, with
+// tile objects aligned the way the orientation of the map aligns them. Tile objects are aligned to the bottom-center
+// in isometric maps, and to the bottom-left in the others.
+func (m *Map) ObjectVertices(o *Object) []Vertex {
+	vertices := o.Vertices()
+	if o.Shape() == ShapeTile && m.Orientation == OrientationIsometric {
+		for i := range vertices {
+			vertices[i].X -= o.Width / 2
+		}
+	}
+	return vertices
+}
+
+// ObjectWorldVertices returns the vertices of the shape of an object in the map, with the rotation of the object and
+// the offsets of its object group and the groups containing it applied. Tile objects are aligned like ObjectVertices
+// aligns them. Nil is returned when the object is not in the map.
+func (m *Map) ObjectWorldVertices(o *Object) []Vertex {
+	offsetX, offsetY, ok := objectOffset(m.Content, o, 0, 0)
+	if !ok {
+		return nil
+	}
+	return o.place(m.ObjectVertices(o), offsetX, offsetY)
+}
+
+// objectOffset returns the sum of the layer offsets of the object groups and groups in content that contain an
+// object, added to the offset of the parent of content.
+func objectOffset(content []Content, o *Object, offsetX float64, offsetY float64) (float64, float64, bool) {
+	for _, c := range content {
+		switch v := c.Value.(type) {
+		case *ObjectGroup:
+			for _, object := range v.Object {
+				if object == o {
+					return offsetX + float64(v.OffsetX), offsetY + float64(v.OffsetY), true
+				}
+			}
+		case *Group:
+			x, y, ok := objectOffset(v.Content, o, offsetX+float64(v.OffsetX), offsetY+float64(v.OffsetY))
+			if ok {
+				return x, y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// layerOffset returns the offset of a layer in content, the sum of its own offset and the offsets of the groups that
+// contain it, added to the offset of the parent of content.
+func layerOffset(content []Content, layer interface{}, offsetX float64, offsetY float64) (float64, float64, bool) {
+	for _, c := range content {
+		var x, y float32
+		switch v := c.Value.(type) {
+		case *Layer:
+			x, y = v.OffsetX, v.OffsetY
+		case *ObjectGroup:
+			x, y = v.OffsetX, v.OffsetY
+		case *ImageLayer:
+			x, y = v.OffsetX, v.OffsetY
+		case *Group:
+			x, y = v.OffsetX, v.OffsetY
+		default:
+			continue
+		}
+
+		if c.Value == layer {
+			return offsetX + float64(x), offsetY + float64(y), true
+		}
+
+		if g, ok := c.Value.(*Group); ok {
+			gx, gy, found := layerOffset(g.Content, layer, offsetX+float64(x), offsetY+float64(y))
+			if found {
+				return gx, gy, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// objectByID returns the object with an ID in the object groups of content, nil when there is none.
+func objectByID(content []Content, id int) *Object {
+	for _, c := range content {
+		switch v := c.Value.(type) {
+		case *ObjectGroup:
+			for _, object := range v.Object {
+				if object.ID == id {
+					return object
+				}
+			}
+		case *Group:
+			if object := objectByID(v.Content, id); object != nil {
+				return object
+			}
+		}
+	}
+	return nil
 }
 
 func (m *Map) String() string {
@@ -103,6 +355,7 @@ func (m *Map) String() string {
 	fmt.Fprintf(&b, "\tTiled Version:      (%T) %q\n", m.TiledVersion, m.TiledVersion)
 	fmt.Fprintf(&b, "\tOrientation:        (%T) %q \n", m.Orientation, m.Orientation)
 	fmt.Fprintf(&b, "\tRender Order:       (%T) %q\n", m.RenderOrder, m.RenderOrder)
+	fmt.Fprintf(&b, "\tCompression Level:  (%T) %d\n", m.CompressionLevel, m.CompressionLevel)
 	fmt.Fprintf(&b, "\tWidth:              (%T) %d\n", m.Width, m.Width)
 	fmt.Fprintf(&b, "\tHeight:             (%T) %d\n", m.Height, m.Height)
 	fmt.Fprintf(&b, "\tTile Width:         (%T) %d\n", m.TileWidth, m.TileWidth)
@@ -114,14 +367,14 @@ func (m *Map) String() string {
 	fmt.Fprintf(&b, "\tNext Layer ID:      (%T) %d\n", m.NextLayerID, m.NextLayerID)
 	fmt.Fprintf(&b, "\tNext Object ID:     (%T) %d\n", m.NextObjectID, m.NextObjectID)
 
+	for _, property := range m.Properties {
+		fmt.Fprintf(&b, property.String())
+	}
+
 	for _, content := range m.Content {
 		fmt.Fprintf(&b, content.String())
 	}
 
-	// for _, property := range m.Properties {
-	// 	fmt.Fprintf(&b, property.String())
-	// }
-
 	// for _, tileset := range m.Tileset {
 	// 	fmt.Fprintf(&b, tileset.String())
 	// }
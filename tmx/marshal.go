@@ -0,0 +1,99 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// attrs builds the attributes of an element when marshalling, values equal to their default are left out the same
+// way Tiled leaves them out.
+type attrs []xml.Attr
+
+// add adds an attribute that is always written.
+func (a *attrs) add(name string, value string) {
+	*a = append(*a, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// str adds a string attribute unless it equals def.
+func (a *attrs) str(name string, value string, def string) {
+	if value != def {
+		a.add(name, value)
+	}
+}
+
+// int adds an int attribute unless it equals def.
+func (a *attrs) int(name string, value int, def int) {
+	if value != def {
+		a.add(name, strconv.Itoa(value))
+	}
+}
+
+// float adds a float attribute unless it equals def.
+func (a *attrs) float(name string, value float64, def float64) {
+	if value != def {
+		a.add(name, formatFloat(value))
+	}
+}
+
+// float32 adds a float32 attribute unless it equals def.
+func (a *attrs) float32(name string, value float32, def float32) {
+	if value != def {
+		a.add(name, strconv.FormatFloat(float64(value), 'f', -1, 32))
+	}
+}
+
+// bool adds a boolean attribute as 1 or 0 unless it equals def.
+func (a *attrs) bool(name string, value bool, def bool) {
+	if value != def {
+		a.add(name, formatBool(value))
+	}
+}
+
+// formatFloat formats a float the shortest way that reads back the same value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// formatBool formats a boolean as 1 or 0.
+func formatBool(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// startElement returns the start element of a tag with attributes.
+func startElement(local string, a attrs) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: local}, Attr: a}
+}
+
+// encodeEnd closes an element that was opened with EncodeToken.
+func encodeEnd(encoder *xml.Encoder, start xml.StartElement) error {
+	return encoder.EncodeToken(start.End())
+}
+
+// propertyList marshals a list of properties wrapped in a <properties> element, nothing is written when the list is
+// empty or only holds class members with their default value.
+type propertyList []*Property
+
+// MarshalXML is called by Marshal to produce the XML element.
+func (p propertyList) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	if len(PropertySet(p).saved()) == 0 {
+		return nil
+	}
+
+	return encoder.EncodeElement(struct {
+		Property []*Property `xml:"property"`
+	}{p}, startElement("properties", nil))
+}
+
+// encodeElements encodes each element in order, nil elements are left out.
+func encodeElements(encoder *xml.Encoder, elements []interface{}) error {
+	for _, element := range elements {
+		err := encoder.Encode(element)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -36,7 +38,7 @@ type Object struct {
 	Rotation float32 `xml:"rotation,attr"`
 
 	// A reference to a tile (optional).
-	GID int `xml:"gid,attr"`
+	GID GID `xml:"gid,attr"`
 
 	// Whether the object is shown (1) or hidden (0). Defaults to 1.
 	Visible bool `xml:"visible,attr"`
@@ -59,13 +61,267 @@ type Object struct {
 	// saved with the object will have higher priority, i.e. they will override the template properties.
 
 	// Can contain: <properties>, <ellipse> (since 0.9), <point>, <polygon>, <polyline>, <text> (since 1.0), image
-	Properties []*Property `xml:"properties>property"`
+	Properties PropertySet `xml:"properties>property"`
 	Ellipse    []*Ellipse  `xml:"ellipse"`
 	Point      []*Point    `xml:"point"`
 	Polygon    []*Polygon  `xml:"polygon"`
 	Polyline   []*Polyline `xml:"polyline"`
 	Text       []*Text     `xml:"text"`
 	Image      *Image      `xml:"image"`
+
+	// The template the object is an instance of, nil when Template is not set. Not part of the spec, it is loaded
+	// with the map and the object is merged with the template object.
+	TemplateRef *Template `xml:"-"`
+
+	// The names of the attributes set on the object element, used to tell instance values from template values.
+	attrs map[string]bool
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (o *Object) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type object Object
+
+	// Objects are visible unless the visible attribute says otherwise.
+	o.Visible = true
+
+	o.attrs = make(map[string]bool, len(startElement.Attr))
+	for _, attr := range startElement.Attr {
+		o.attrs[attr.Name.Local] = true
+	}
+
+	return decoder.DecodeElement((*object)(o), &startElement)
+}
+
+// hasShape reports whether the object has a shape element, an object without one is a rectangle.
+func (o *Object) hasShape() bool {
+	return o.Ellipse != nil ||
+		o.Point != nil ||
+		o.Polygon != nil ||
+		o.Polyline != nil ||
+		o.Text != nil
+}
+
+// applyTemplate merges the template object into the object, values set on the object take priority.
+func (o *Object) applyTemplate(t *Template) {
+	o.TemplateRef = t
+
+	tmpl := t.Object
+	if tmpl == nil {
+		return
+	}
+
+	if !o.attrs["name"] {
+		o.Name = tmpl.Name
+	}
+	if !o.attrs["type"] {
+		o.Type = tmpl.Type
+	}
+	if !o.attrs["width"] {
+		o.Width = tmpl.Width
+	}
+	if !o.attrs["height"] {
+		o.Height = tmpl.Height
+	}
+	if !o.attrs["rotation"] {
+		o.Rotation = tmpl.Rotation
+	}
+	if !o.attrs["gid"] {
+		o.GID = t.mapGID(tmpl.GID)
+	}
+	if !o.attrs["visible"] {
+		o.Visible = tmpl.Visible
+	}
+
+	if !o.hasShape() {
+		o.Ellipse = tmpl.Ellipse
+		o.Point = tmpl.Point
+		o.Polygon = tmpl.Polygon
+		o.Polyline = tmpl.Polyline
+		o.Text = tmpl.Text
+	}
+
+	if o.Image == nil {
+		o.Image = tmpl.Image
+	}
+
+	// Template properties come first, properties saved with the object override them.
+	properties := make(PropertySet, 0, len(tmpl.Properties)+len(o.Properties))
+	for _, property := range tmpl.Properties {
+		p := *property
+		properties = append(properties, &p)
+	}
+	for _, property := range o.Properties {
+		replaced := false
+		for i, p := range properties {
+			if p.Name == property.Name {
+				properties[i] = property
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			properties = append(properties, property)
+		}
+	}
+	o.Properties = properties
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+// For template instances, values that are the same as the template are left out, so they are still inherited.
+func (o *Object) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	// The values the object gets when an attribute is left out.
+	def := &Object{Visible: true}
+	if o.TemplateRef != nil && o.TemplateRef.Object != nil {
+		def = o.TemplateRef.Object
+	}
+	defGID := def.GID
+	if o.TemplateRef != nil {
+		defGID = o.TemplateRef.mapGID(def.GID)
+	}
+
+	var a attrs
+	a.add("id", strconv.Itoa(o.ID))
+	a.str("name", o.Name, def.Name)
+	a.str("type", o.Type, def.Type)
+	a.str("template", o.Template, "")
+	if o.GID != defGID {
+		// Flip flags use the highest bit, which does not fit in an int on 32-bit platforms.
+		a.add("gid", strconv.FormatUint(uint64(o.GID), 10))
+	}
+	a.add("x", formatFloat(o.X))
+	a.add("y", formatFloat(o.Y))
+	a.float("width", o.Width, def.Width)
+	a.float("height", o.Height, def.Height)
+	a.float32("rotation", o.Rotation, def.Rotation)
+	a.bool("visible", o.Visible, def.Visible)
+
+	start = startElement("object", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	elements := []interface{}{propertyList(o.instanceProperties())}
+
+	// Shapes and images shared with the template are inherited.
+	if o.TemplateRef == nil || o.shapeElement() != def.shapeElement() {
+		elements = append(elements, o.Ellipse, o.Point, o.Polygon, o.Polyline, o.Text)
+	}
+	if o.TemplateRef == nil || o.Image != def.Image {
+		elements = append(elements, o.Image)
+	}
+
+	err = encodeElements(encoder, elements)
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
+// instanceProperties returns the properties of the object that are not inherited unchanged from the template.
+func (o *Object) instanceProperties() PropertySet {
+	if o.TemplateRef == nil || o.TemplateRef.Object == nil {
+		return o.Properties
+	}
+
+	properties := make(PropertySet, 0, len(o.Properties))
+	for _, property := range o.Properties {
+		inherited := false
+		for _, p := range o.TemplateRef.Object.Properties {
+			if p.equal(property) {
+				inherited = true
+				break
+			}
+		}
+		if !inherited {
+			properties = append(properties, property)
+		}
+	}
+
+	return properties
+}
+
+// shapeElement returns the shape element of the object, nil when the object is a rectangle.
+func (o *Object) shapeElement() interface{} {
+	switch o.Shape() {
+	case ShapeEllipse:
+		return o.Ellipse[0]
+	case ShapePoint:
+		return o.Point[0]
+	case ShapePolygon:
+		return o.Polygon[0]
+	case ShapePolyline:
+		return o.Polyline[0]
+	case ShapeText:
+		return o.Text[0]
+	}
+	return nil
+}
+
+// ellipseSegments is the number of vertices used to approximate an ellipse.
+const ellipseSegments = 32
+
+// Shape returns the kind of shape of the object. Objects with a GID are tiles, and objects without a shape element
+// are rectangles.
+func (o *Object) Shape() ShapeKind {
+	switch {
+	case len(o.Ellipse) > 0:
+		return ShapeEllipse
+	case len(o.Point) > 0:
+		return ShapePoint
+	case len(o.Polygon) > 0:
+		return ShapePolygon
+	case len(o.Polyline) > 0:
+		return ShapePolyline
+	case len(o.Text) > 0:
+		return ShapeText
+	case o.GID != 0:
+		return ShapeTile
+	}
+	return ShapeRectangle
+}
+
+// Vertices returns the vertices of the shape of the object relative to its position, without rotation. Rectangles,
+// texts and tiles return their four corners clockwise, tiles are aligned to the bottom-left the way they are in
+// orthogonal maps, Map.ObjectVertices aligns them the way the orientation of the map does. Ellipses are approximated
+// by a polygon, and points return a single vertex.
+func (o *Object) Vertices() []Vertex {
+	switch o.Shape() {
+	case ShapeEllipse:
+		rx, ry := o.Width/2, o.Height/2
+		vertices := make([]Vertex, ellipseSegments)
+		for i := range vertices {
+			sin, cos := math.Sincos(2 * math.Pi * float64(i) / ellipseSegments)
+			vertices[i] = Vertex{X: rx + rx*cos, Y: ry + ry*sin}
+		}
+		return vertices
+	case ShapePoint:
+		return []Vertex{{}}
+	case ShapePolygon:
+		return append([]Vertex{}, o.Polygon[0].Points...)
+	case ShapePolyline:
+		return append([]Vertex{}, o.Polyline[0].Points...)
+	case ShapeTile:
+		return []Vertex{{0, -o.Height}, {o.Width, -o.Height}, {o.Width, 0}, {0, 0}}
+	}
+	return []Vertex{{0, 0}, {o.Width, 0}, {o.Width, o.Height}, {0, o.Height}}
+}
+
+// WorldVertices returns the vertices of the shape of the object rotated around its position, and moved to its
+// position plus the offset of the layers that contain it. Map.ObjectWorldVertices finds the offset of an object.
+func (o *Object) WorldVertices(offsetX float64, offsetY float64) []Vertex {
+	return o.place(o.Vertices(), offsetX, offsetY)
+}
+
+// place rotates vertices relative to the position of the object around it, and moves them to its position plus an
+// offset.
+func (o *Object) place(vertices []Vertex, offsetX float64, offsetY float64) []Vertex {
+	for i, v := range vertices {
+		v = v.rotate(float64(o.Rotation))
+		vertices[i] = Vertex{X: v.X + o.X + offsetX, Y: v.Y + o.Y + offsetY}
+	}
+	return vertices
 }
 
 func (o *Object) String() string {
@@ -83,6 +339,7 @@ func (o *Object) String() string {
 	fmt.Fprintf(&b, "\tGID:      (%T) %d\n", o.GID, o.GID)
 	fmt.Fprintf(&b, "\tVisible:  (%T) %t\n", o.Visible, o.Visible)
 	fmt.Fprintf(&b, "\tTemplate: (%T) %q\n", o.Template, o.Template)
+	fmt.Fprintf(&b, "\tShape:    (%T) %v\n", o.Shape(), o.Shape())
 
 	for _, property := range o.Properties {
 		fmt.Fprintf(&b, property.String())
@@ -112,14 +369,5 @@ func (o *Object) String() string {
 		fmt.Fprintf(&b, o.Image.String())
 	}
 
-	// Set as rectangle if all other options are nil.
-	if o.Ellipse == nil &&
-		o.Point == nil &&
-		o.Polygon == nil &&
-		o.Polyline == nil &&
-		o.Text == nil {
-		fmt.Fprintf(&b, "Rectangle\n")
-	}
-
 	return b.String()
 }
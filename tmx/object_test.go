@@ -0,0 +1,221 @@
+package tmx
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// objectMap has an object of every shape in an object group with an offset, inside a group with an offset of its own.
+const objectMap = `<map version="1.10" orientation="%s" width="4" height="4" tilewidth="32" tileheight="16">
+ <group id="1" name="world" offsetx="100" offsety="200">
+  <objectgroup id="2" name="objects" offsetx="10" offsety="20">
+   <object id="1" name="box" x="8" y="16" width="32" height="16"/>
+   <object id="2" name="turned" x="8" y="16" width="32" height="16" rotation="90"/>
+   <object id="3" name="ring" x="0" y="0" width="20" height="10"><ellipse/></object>
+   <object id="4" name="spawn" x="5" y="6"><point/></object>
+   <object id="5" name="wall" x="0" y="0"><polygon points="0,0 16,0 16,-8"/></object>
+   <object id="6" name="path" x="4" y="4"><polyline points="0,0 8.5,2 -3,7.25"/></object>
+   <object id="7" name="sign" x="0" y="0" width="64" height="12"><text>Hello</text></object>
+   <object id="8" name="crate" gid="1" x="16" y="32" width="32" height="16"/>
+  </objectgroup>
+ </group>
+ <objectgroup id="3" name="top">
+  <object id="9" name="loose" gid="1" x="0" y="16" width="32" height="16" rotation="180"/>
+ </objectgroup>
+</map>`
+
+// loadObjectMap loads objectMap with an orientation.
+func loadObjectMap(t *testing.T, orientation string) *Map {
+	t.Helper()
+
+	tmx, err := LoadTMXBytes([]byte(fmt.Sprintf(objectMap, orientation)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmx.Map
+}
+
+// equalVertices reports whether two lists of vertices are equal, allowing for rounding.
+func equalVertices(a []Vertex, b []Vertex) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i].X-b[i].X) > 1e-9 || math.Abs(a[i].Y-b[i].Y) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestObjectShape(t *testing.T) {
+	m := loadObjectMap(t, OrientationOrthogonal)
+
+	tests := []struct {
+		id    int
+		shape ShapeKind
+	}{
+		{1, ShapeRectangle},
+		{3, ShapeEllipse},
+		{4, ShapePoint},
+		{5, ShapePolygon},
+		{6, ShapePolyline},
+		{7, ShapeText},
+		{8, ShapeTile},
+	}
+
+	for _, test := range tests {
+		o := m.ObjectByID(test.id)
+		if o == nil {
+			t.Fatalf("ObjectByID(%d) = nil", test.id)
+		}
+		if o.ID != test.id || o.Shape() != test.shape {
+			t.Errorf("ObjectByID(%d) is object %d with shape %v, want shape %v", test.id, o.ID, o.Shape(), test.shape)
+		}
+	}
+
+	if o := m.ObjectByID(9); o == nil || o.Name != "loose" {
+		t.Errorf("ObjectByID(9) = %v, want the object outside the group", o)
+	}
+	if o := m.ObjectByID(10); o != nil {
+		t.Errorf("ObjectByID(10) = %v, want nil", o)
+	}
+}
+
+func TestObjectVertices(t *testing.T) {
+	m := loadObjectMap(t, OrientationOrthogonal)
+
+	tests := []struct {
+		id       int
+		vertices []Vertex
+	}{
+		{1, []Vertex{{0, 0}, {32, 0}, {32, 16}, {0, 16}}},
+		{4, []Vertex{{0, 0}}},
+		{5, []Vertex{{0, 0}, {16, 0}, {16, -8}}},
+		{6, []Vertex{{0, 0}, {8.5, 2}, {-3, 7.25}}},
+		{7, []Vertex{{0, 0}, {64, 0}, {64, 12}, {0, 12}}},
+		// Tiles are aligned to the bottom-left.
+		{8, []Vertex{{0, -16}, {32, -16}, {32, 0}, {0, 0}}},
+	}
+
+	for _, test := range tests {
+		if vertices := m.ObjectByID(test.id).Vertices(); !equalVertices(vertices, test.vertices) {
+			t.Errorf("object %d vertices %v, want %v", test.id, vertices, test.vertices)
+		}
+	}
+}
+
+func TestObjectVerticesEllipse(t *testing.T) {
+	o := loadObjectMap(t, OrientationOrthogonal).ObjectByID(3)
+
+	vertices := o.Vertices()
+	if len(vertices) != ellipseSegments {
+		t.Fatalf("ellipse has %d vertices, want %d", len(vertices), ellipseSegments)
+	}
+
+	// The polygon starts on the right of the ellipse and goes clockwise, every vertex is on the ellipse.
+	if !equalVertices(vertices[:1], []Vertex{{20, 5}}) || !equalVertices(vertices[8:9], []Vertex{{10, 10}}) {
+		t.Errorf("ellipse starts with %v and %v, want 20,5 and 10,10", vertices[0], vertices[8])
+	}
+	for _, v := range vertices {
+		dx, dy := (v.X-10)/10, (v.Y-5)/5
+		if math.Abs(dx*dx+dy*dy-1) > 1e-9 {
+			t.Errorf("vertex %v is not on the ellipse", v)
+		}
+	}
+}
+
+func TestObjectWorldVertices(t *testing.T) {
+	m := loadObjectMap(t, OrientationOrthogonal)
+
+	tests := []struct {
+		id       int
+		vertices []Vertex
+	}{
+		// The offsets of the object group and of the group are added.
+		{1, []Vertex{{118, 236}, {150, 236}, {150, 252}, {118, 252}}},
+		// Objects rotate clockwise around their position.
+		{2, []Vertex{{118, 236}, {118, 268}, {102, 268}, {102, 236}}},
+		{4, []Vertex{{115, 226}}},
+		{6, []Vertex{{114, 224}, {122.5, 226}, {111, 231.25}}},
+		{8, []Vertex{{126, 236}, {158, 236}, {158, 252}, {126, 252}}},
+		// Rotated tiles turn around the bottom-left corner.
+		{9, []Vertex{{0, 32}, {-32, 32}, {-32, 16}, {0, 16}}},
+	}
+
+	for _, test := range tests {
+		o := m.ObjectByID(test.id)
+		if vertices := m.ObjectWorldVertices(o); !equalVertices(vertices, test.vertices) {
+			t.Errorf("object %d world vertices %v, want %v", test.id, vertices, test.vertices)
+		}
+	}
+
+	// The offsets are passed to WorldVertices directly.
+	o := m.ObjectByID(2)
+	want := []Vertex{{8, 16}, {8, 48}, {-8, 48}, {-8, 16}}
+	if vertices := o.WorldVertices(0, 0); !equalVertices(vertices, want) {
+		t.Errorf("WorldVertices(0, 0) = %v, want %v", vertices, want)
+	}
+
+	if vertices := m.ObjectWorldVertices(&Object{}); vertices != nil {
+		t.Errorf("world vertices of an object outside the map %v, want nil", vertices)
+	}
+}
+
+func TestObjectWorldVerticesIsometric(t *testing.T) {
+	m := loadObjectMap(t, OrientationIsometric)
+
+	tests := []struct {
+		id       int
+		vertices []Vertex
+	}{
+		// Only tiles are aligned differently, to the bottom-center.
+		{1, []Vertex{{118, 236}, {150, 236}, {150, 252}, {118, 252}}},
+		{8, []Vertex{{110, 236}, {142, 236}, {142, 252}, {110, 252}}},
+		{9, []Vertex{{16, 32}, {-16, 32}, {-16, 16}, {16, 16}}},
+	}
+
+	for _, test := range tests {
+		o := m.ObjectByID(test.id)
+		if vertices := m.ObjectWorldVertices(o); !equalVertices(vertices, test.vertices) {
+			t.Errorf("object %d world vertices %v, want %v", test.id, vertices, test.vertices)
+		}
+	}
+
+	want := []Vertex{{-16, -16}, {16, -16}, {16, 0}, {-16, 0}}
+	if vertices := m.ObjectVertices(m.ObjectByID(8)); !equalVertices(vertices, want) {
+		t.Errorf("ObjectVertices of a tile %v, want %v", vertices, want)
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	tests := []struct {
+		s      string
+		points Points
+		err    bool
+	}{
+		{"0,0 16,0 16,-8", Points{{0, 0}, {16, 0}, {16, -8}}, false},
+		{" 1.5,2\n-3,4e1 ", Points{{1.5, 2}, {-3, 40}}, false},
+		{"", Points{}, false},
+		{"0,0 16", nil, true},
+		{"0,0,0", nil, true},
+		{"x,1", nil, true},
+		{"1,y", nil, true},
+	}
+
+	for _, test := range tests {
+		points, err := parsePoints(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("parsePoints(%q) error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if !equalVertices(points, test.points) {
+			t.Errorf("parsePoints(%q) = %v, want %v", test.s, points, test.points)
+		}
+	}
+
+	if s := (Points{{0, 0}, {8.5, -2}}).String(); s != "0,0 8.5,-2" {
+		t.Errorf("String() = %q, want 0,0 8.5,-2", s)
+	}
+}
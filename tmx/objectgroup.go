@@ -33,16 +33,16 @@ type ObjectGroup struct {
 	// Height float32  `xml:"height,attr"`
 
 	// The opacity of the layer as a value from 0 to 1. Defaults to 1.
-	Opacity int `xml:"opacity,attr,omitempty"`
+	Opacity float32 `xml:"opacity,attr,omitempty"`
 
 	// Whether the layer is shown (1) or hidden (0). Defaults to 1.
 	Visible int `xml:"visible,attr"`
 
 	// Rendering offset for this object group in pixels. Defaults to 0. (since 0.14)
-	OffsetX int `xml:"offsetx,attr,omitempty"`
+	OffsetX float32 `xml:"offsetx,attr,omitempty"`
 
 	// Rendering offset for this object group in pixels. Defaults to 0. (since 0.14)
-	OffsetY int `xml:"offsety,attr,omitempty"`
+	OffsetY float32 `xml:"offsety,attr,omitempty"`
 
 	// Whether the objects are drawn according to the order of appearance (“index”) or sorted by their y-coordinate
 	// (“topdown”). Defaults to “topdown”.
@@ -51,10 +51,55 @@ type ObjectGroup struct {
 	// The object group is in fact a map layer, and is hence called “object layer” in Tiled.
 
 	// Can contain: <properties>, <object>
-	Properties *Properties `xml:"properties,omitempty"`
+	Properties PropertySet `xml:"properties>property"`
 	Object     []*Object   `xml:"object"`
 }
 
+// PropertySet returns the custom properties of the object group.
+func (o *ObjectGroup) PropertySet() PropertySet {
+	return o.Properties
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (o *ObjectGroup) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type objectGroup ObjectGroup
+
+	// Object groups are opaque and visible unless the attributes say otherwise.
+	o.Opacity = 1
+	o.Visible = 1
+
+	return decoder.DecodeElement((*objectGroup)(o), &startElement)
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (o *ObjectGroup) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.int("id", o.ID, 0)
+	a.str("name", o.Name, "")
+	a.str("color", o.Color, "")
+	a.float32("opacity", o.Opacity, 1)
+	a.int("visible", o.Visible, 1)
+	a.float32("offsetx", o.OffsetX, 0)
+	a.float32("offsety", o.OffsetY, 0)
+	a.str("draworder", o.DrawOrder, "")
+
+	start = startElement("objectgroup", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encodeElements(encoder, []interface{}{
+		propertyList(o.Properties),
+		o.Object,
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
 func (o *ObjectGroup) String() string {
 	var b strings.Builder
 
@@ -62,18 +107,14 @@ func (o *ObjectGroup) String() string {
 	fmt.Fprintf(&b, "\tID:        (%T) %d\n", o.ID, o.ID)
 	fmt.Fprintf(&b, "\tName:      (%T) %q\n", o.Name, o.Name)
 	fmt.Fprintf(&b, "\tColor:     (%T) %q\n", o.Color, o.Color)
-	fmt.Fprintf(&b, "\tOpacity:   (%T) %d\n", o.Opacity, o.Opacity)
+	fmt.Fprintf(&b, "\tOpacity:   (%T) %f\n", o.Opacity, o.Opacity)
 	fmt.Fprintf(&b, "\tVisible:   (%T) %d\n", o.Visible, o.Visible)
-	fmt.Fprintf(&b, "\tOffsetX:   (%T) %d\n", o.OffsetX, o.OffsetX)
-	fmt.Fprintf(&b, "\tOffsetY:   (%T) %d\n", o.OffsetY, o.OffsetY)
+	fmt.Fprintf(&b, "\tOffsetX:   (%T) %f\n", o.OffsetX, o.OffsetX)
+	fmt.Fprintf(&b, "\tOffsetY:   (%T) %f\n", o.OffsetY, o.OffsetY)
 	fmt.Fprintf(&b, "\tDrawOrder: (%T) %q\n", o.DrawOrder, o.DrawOrder)
 
-	// for _, property := range o.Properties {
-	// 	fmt.Fprintf(&b, property.String())
-	// }
-
-	if o.Properties != nil {
-		fmt.Fprintf(&b, o.Properties.String())
+	for _, property := range o.Properties {
+		fmt.Fprintf(&b, property.String())
 	}
 
 	for _, object := range o.Object {
@@ -0,0 +1,63 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Points is the list of points of a polygon or polyline, saved as space separated x,y coordinates.
+type Points []Vertex
+
+// UnmarshalXMLAttr is called by Unmarshal to parse the points from the attribute value.
+func (p *Points) UnmarshalXMLAttr(attr xml.Attr) error {
+	points, err := parsePoints(attr.Value)
+	if err != nil {
+		return err
+	}
+
+	*p = points
+
+	return nil
+}
+
+// MarshalXMLAttr is called by Marshal to format the points as the attribute value.
+func (p Points) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: p.String()}, nil
+}
+
+// parsePoints parses space separated x,y coordinates.
+func parsePoints(s string) (Points, error) {
+	fields := strings.Fields(s)
+	points := make(Points, 0, len(fields))
+
+	for _, field := range fields {
+		xy := strings.Split(field, ",")
+		if len(xy) != 2 {
+			return nil, fmt.Errorf("error parsing points: invalid point %q", field)
+		}
+
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing points: %w", err)
+		}
+
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing points: %w", err)
+		}
+
+		points = append(points, Vertex{X: x, Y: y})
+	}
+
+	return points, nil
+}
+
+func (p Points) String() string {
+	s := make([]string, len(p))
+	for i, v := range p {
+		s[i] = v.String()
+	}
+	return strings.Join(s, " ")
+}
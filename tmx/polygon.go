@@ -11,7 +11,7 @@ type Polygon struct {
 	XMLName xml.Name `xml:"polygon"`
 
 	// A list of x,y coordinates in pixels.
-	Points string `xml:"points,attr"`
+	Points Points `xml:"points,attr"`
 
 	// Each polygon object is made up of a space-delimited list of x,y coordinates. The origin for these coordinates is
 	// the location of the parent object. By default, the first point is created as 0,0 denoting that the point will
@@ -22,7 +22,7 @@ func (p *Polygon) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Polygon:\n")
-	fmt.Fprintf(&b, "\tPoints: (%T) %q\n", p.Points, p.Points)
+	fmt.Fprintf(&b, "\tPoints: (%T) %q\n", p.Points, p.Points.String())
 
 	return b.String()
 }
@@ -11,7 +11,7 @@ type Polyline struct {
 	XMLName xml.Name `xml:"polyline"`
 
 	// A list of x,y coordinates in pixels.
-	Points string `xml:"points,attr"`
+	Points Points `xml:"points,attr"`
 
 	// A polyline follows the same placement definition as a polygon object.
 }
@@ -20,7 +20,7 @@ func (p *Polyline) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Polyline:\n")
-	fmt.Fprintf(&b, "\tPoints: (%T) %q\n", p.Points, p.Points)
+	fmt.Fprintf(&b, "\tPoints: (%T) %q\n", p.Points, p.Points.String())
 
 	return b.String()
 }
@@ -0,0 +1,391 @@
+package tmx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// maxClassDepth limits how deep classes are nested, so a class that contains itself can not recurse forever.
+const maxClassDepth = 32
+
+// Project structure: https://doc.mapeditor.org/en/stable/manual/projects/
+type Project struct {
+	// The path of the project file. Not part of the spec, it is set when the project is loaded.
+	Path string
+
+	// The folders of the project, relative to the project file.
+	Folders []string
+
+	// The folder scripted extensions are loaded from, relative to the project file.
+	ExtensionsPath string
+
+	// The automapping rules file used by the project, relative to the project file.
+	AutomappingRulesFile string
+
+	// The custom property types defined in the project, the classes and enums used by custom properties.
+	PropertyTypes []*PropertyType
+
+	// A project is saved as a .tiled-project JSON file, Tiled keeps the custom property types of every map and tileset
+	// in the project there. Maps and tilesets only save the name of the type and the members that were changed.
+}
+
+// jsonProject structure: https://doc.mapeditor.org/en/stable/manual/projects/
+type jsonProject struct {
+	Folders              []string            `json:"folders"`
+	ExtensionsPath       string              `json:"extensionsPath"`
+	AutomappingRulesFile string              `json:"automappingRulesFile"`
+	PropertyTypes        []*jsonPropertyType `json:"propertyTypes"`
+}
+
+// jsonPropertyType structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#property-type
+type jsonPropertyType struct {
+	ID            int            `json:"id"`
+	Name          string         `json:"name"`
+	Type          string         `json:"type"`
+	StorageType   string         `json:"storageType"`
+	Values        []string       `json:"values"`
+	ValuesAsFlags bool           `json:"valuesAsFlags"`
+	Members       jsonProperties `json:"members"`
+	Color         string         `json:"color"`
+	DrawFill      bool           `json:"drawFill"`
+	UseAs         []string       `json:"useAs"`
+}
+
+// LoadProject loads the json of a .tiled-project file into a Project struct.
+func LoadProject(source string) (*Project, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadProjectResolver(OSResolver{}, absSource)
+}
+
+// LoadProjectBytes loads the json of a .tiled-project file into a Project struct.
+func LoadProjectBytes(bytes []byte) (*Project, error) {
+	return unmarshalProject(bytes)
+}
+
+// LoadProjectFS loads the json of a .tiled-project file from a file system, such as an embed.FS or a zip.Reader, into
+// a Project struct.
+func LoadProjectFS(fsys fs.FS, name string) (*Project, error) {
+	return LoadProjectResolver(FSResolver{FS: fsys}, name)
+}
+
+// LoadProjectResolver loads the json of a .tiled-project file opened with a Resolver into a Project struct.
+func LoadProjectResolver(resolver Resolver, name string) (*Project, error) {
+	projectBytes, err := readFile(resolver, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading project file: %w", err)
+	}
+
+	p, err := unmarshalProject(projectBytes)
+	if err != nil {
+		return nil, err
+	}
+	p.Path = name
+
+	return p, nil
+}
+
+// unmarshalProject unmarshals the json bytes of a project file.
+func unmarshalProject(data []byte) (*Project, error) {
+	var jp jsonProject
+	err := json.Unmarshal(data, &jp)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling project bytes: %w", err)
+	}
+
+	p := &Project{
+		Folders:              jp.Folders,
+		ExtensionsPath:       jp.ExtensionsPath,
+		AutomappingRulesFile: jp.AutomappingRulesFile,
+	}
+
+	for _, jt := range jp.PropertyTypes {
+		t := &PropertyType{
+			ID:            jt.ID,
+			Name:          jt.Name,
+			Type:          jt.Type,
+			StorageType:   jt.StorageType,
+			Values:        jt.Values,
+			ValuesAsFlags: jt.ValuesAsFlags,
+			Members:       jt.Members.properties(),
+			Color:         jt.Color,
+			DrawFill:      jt.DrawFill,
+			UseAs:         jt.UseAs,
+		}
+
+		// Before classes were added in Tiled 1.8 every property type was a string enum.
+		if t.Type == "" {
+			t.Type = PropertyTypeKindEnum
+		}
+		if t.Type == PropertyTypeKindEnum && t.StorageType == "" {
+			t.StorageType = EnumStorageString
+		}
+
+		p.PropertyTypes = append(p.PropertyTypes, t)
+	}
+
+	// Members of a class are validated and completed the same way as the properties of a map, so classes used as
+	// members have their own defaults.
+	for _, t := range p.PropertyTypes {
+		err = p.applyProperties(t.Members, 0)
+		if err != nil {
+			return nil, fmt.Errorf("error loading property type %q: %w", t.Name, err)
+		}
+	}
+
+	return p, nil
+}
+
+// PropertyType returns the custom property type with a name, nil when there is none.
+func (p *Project) PropertyType(name string) *PropertyType {
+	for _, t := range p.PropertyTypes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// ApplyMap applies the custom property types of the project to every property of the map, its layers, objects and
+// tilesets. Class properties get the members that were not saved with their default value, and the members take the
+// types defined by the class. The values of enum properties are checked against the values of the enum. The members of
+// classes loaded from the JSON format only get their types here, so their file members are not resolved.
+func (p *Project) ApplyMap(m *Map) error {
+	err := p.applyProperties(m.Properties, 0)
+	if err != nil {
+		return fmt.Errorf("error applying property types: %w", err)
+	}
+
+	err = p.applyContent(m.Content)
+	if err != nil {
+		return fmt.Errorf("error applying property types: %w", err)
+	}
+
+	for _, tileset := range m.templateTilesets {
+		err = p.applyTileset(tileset)
+		if err != nil {
+			return fmt.Errorf("error applying property types: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ApplyTileset applies the custom property types of the project to every property of the tileset, its terrains, Wang
+// sets and tiles, the same way ApplyMap does.
+func (p *Project) ApplyTileset(t *Tileset) error {
+	err := p.applyTileset(t)
+	if err != nil {
+		return fmt.Errorf("error applying property types: %w", err)
+	}
+	return nil
+}
+
+// applyTileset applies the custom property types to the properties of a tileset, its terrains, Wang sets and tiles.
+func (p *Project) applyTileset(t *Tileset) error {
+	sets := []PropertySet{t.Properties}
+
+	if t.TerrainTypes != nil {
+		for _, terrain := range t.TerrainTypes.Terrain {
+			sets = append(sets, terrain.Properties)
+		}
+	}
+
+	if t.Wangsets != nil {
+		for _, wangset := range t.Wangsets.Wangset {
+			sets = append(sets, wangset.Properties)
+			for _, color := range wangset.WangColor {
+				sets = append(sets, color.Properties)
+			}
+		}
+	}
+
+	for _, tile := range t.Tile {
+		sets = append(sets, tile.Properties)
+		for _, objectGroup := range tile.ObjectGroup {
+			sets = append(sets, objectGroupPropertySets(objectGroup)...)
+		}
+	}
+
+	for _, s := range sets {
+		err := p.applyProperties(s, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyProperties applies the custom property types of the project to a set of properties, the same way ApplyMap
+// does.
+func (p *Project) ApplyProperties(s PropertySet) error {
+	err := p.applyProperties(s, 0)
+	if err != nil {
+		return fmt.Errorf("error applying property types: %w", err)
+	}
+	return nil
+}
+
+// applyContent applies the custom property types to Map.Content or Group.Content.
+func (p *Project) applyContent(content []Content) error {
+	for _, c := range content {
+		var sets []PropertySet
+
+		switch v := c.Value.(type) {
+		case *Tileset:
+			err := p.applyTileset(v)
+			if err != nil {
+				return err
+			}
+		case *Layer:
+			sets = append(sets, v.Properties)
+		case *ObjectGroup:
+			sets = append(sets, objectGroupPropertySets(v)...)
+		case *ImageLayer:
+			sets = append(sets, v.Properties)
+		case *Group:
+			sets = append(sets, v.Properties)
+			err := p.applyContent(v.Content)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, s := range sets {
+			err := p.applyProperties(s, 0)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// objectGroupPropertySets returns the properties of an object group and of each of its objects.
+func objectGroupPropertySets(objectGroup *ObjectGroup) []PropertySet {
+	sets := []PropertySet{objectGroup.Properties}
+	for _, object := range objectGroup.Object {
+		sets = append(sets, object.Properties)
+	}
+	return sets
+}
+
+// applyProperties applies the custom property types to each property in a set, depth is how deep the set is nested
+// in classes.
+func (p *Project) applyProperties(s PropertySet, depth int) error {
+	for _, property := range s {
+		err := p.applyProperty(property, nil, depth)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyProperty validates an enum property, or completes the members of a class property. Defaults are the members a
+// class member got from the class that contains it, they override the defaults of its own class.
+func (p *Project) applyProperty(property *Property, defaults PropertySet, depth int) error {
+	if property.PropertyType == "" {
+		if property.Type == PropertyTypeClass {
+			return fmt.Errorf("class property %q has no property type", property.Name)
+		}
+		return nil
+	}
+
+	t := p.PropertyType(property.PropertyType)
+	if t == nil {
+		return fmt.Errorf("property %q has unknown property type %q", property.Name, property.PropertyType)
+	}
+
+	switch t.Type {
+	case PropertyTypeKindEnum:
+		return t.validateEnum(property)
+	case PropertyTypeKindClass:
+		if property.Type != PropertyTypeClass {
+			return fmt.Errorf("property %q of class %q is of type %s, not class", property.Name, t.Name, property.Type)
+		}
+		if depth >= maxClassDepth {
+			return fmt.Errorf("property %q nests class %q more than %d deep", property.Name, t.Name, maxClassDepth)
+		}
+		return p.applyMembers(property, t, defaults, depth)
+	}
+
+	return fmt.Errorf("property type %q has unknown type %q", t.Name, t.Type)
+}
+
+// applyMembers orders the members of a class property the way the class defines them, adding the members that were
+// not saved with their default value from defaults, or from the class.
+func (p *Project) applyMembers(property *Property, class *PropertyType, defaults PropertySet, depth int) error {
+	var unknown []string
+	for _, member := range property.Properties {
+		if class.Members.Property(member.Name) == nil {
+			unknown = append(unknown, member.Name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("property %q has members %s that class %q does not define", property.Name,
+			strings.Join(unknown, ", "), class.Name)
+	}
+
+	members := make(PropertySet, 0, len(class.Members))
+	for _, def := range class.Members {
+		if d := defaults.Property(def.Name); d != nil {
+			def = d
+		}
+
+		member := property.Properties.Property(def.Name)
+		if member == nil {
+			member = def.copyDefault()
+		} else {
+			member.Type = def.Type
+			member.PropertyType = def.PropertyType
+		}
+
+		err := p.applyProperty(member, def.Properties, depth+1)
+		if err != nil {
+			return err
+		}
+
+		members = append(members, member)
+	}
+	property.Properties = members
+
+	return nil
+}
+
+// copyDefault returns a copy of a class member, marked as having its default value so it is not saved.
+func (p *Property) copyDefault() *Property {
+	c := *p
+	c.def = true
+
+	c.Properties = make(PropertySet, len(p.Properties))
+	for i, member := range p.Properties {
+		c.Properties[i] = member.copyDefault()
+	}
+
+	return &c
+}
+
+func (p *Project) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Project:\n")
+	fmt.Fprintf(&b, "\tPath:                 (%T) %q\n", p.Path, p.Path)
+	fmt.Fprintf(&b, "\tFolders:              (%T) %q\n", p.Folders, p.Folders)
+	fmt.Fprintf(&b, "\tExtensionsPath:       (%T) %q\n", p.ExtensionsPath, p.ExtensionsPath)
+	fmt.Fprintf(&b, "\tAutomappingRulesFile: (%T) %q\n", p.AutomappingRulesFile, p.AutomappingRulesFile)
+
+	for _, t := range p.PropertyTypes {
+		fmt.Fprintf(&b, t.String())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,309 @@
+package tmx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testProject has a string enum, an int enum of flags and a class with a nested class, as saved by Tiled.
+const testProject = `{
+ "automappingRulesFile": "",
+ "folders": ["."],
+ "propertyTypes": [
+  {"id": 1, "name": "Element", "type": "enum", "storageType": "string", "values": ["fire", "water", "earth"],
+   "valuesAsFlags": false},
+  {"id": 2, "name": "Layers", "type": "enum", "storageType": "int", "values": ["ground", "air", "water"],
+   "valuesAsFlags": true},
+  {"id": 3, "name": "Point", "type": "class", "useAs": ["property"], "members": [
+   {"name": "x", "type": "int", "value": 0},
+   {"name": "y", "type": "int", "value": 0}
+  ]},
+  {"id": 4, "name": "Spawn", "type": "class", "color": "#ffa0a0a4", "drawFill": true, "useAs": ["property", "object"],
+   "members": [
+    {"name": "count", "type": "int", "value": 1},
+    {"name": "element", "type": "string", "propertytype": "Element", "value": "fire"},
+    {"name": "at", "type": "class", "propertytype": "Point", "value": {"y": 5}}
+   ]},
+  {"id": 5, "name": "Legacy", "values": ["a", "b"]}
+ ]
+}`
+
+// loadTestProject loads testProject.
+func loadTestProject(t *testing.T) *Project {
+	t.Helper()
+
+	p, err := LoadProjectResolver(MapResolver{"game.tiled-project": []byte(testProject)}, "game.tiled-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadProject(t *testing.T) {
+	p := loadTestProject(t)
+
+	if p.Path != "game.tiled-project" || len(p.Folders) != 1 || len(p.PropertyTypes) != 5 {
+		t.Fatalf("loaded %q with %v and %d types", p.Path, p.Folders, len(p.PropertyTypes))
+	}
+
+	spawn := p.PropertyType("Spawn")
+	if spawn == nil || spawn.Type != PropertyTypeKindClass || !spawn.DrawFill || len(spawn.UseAs) != 2 {
+		t.Fatalf("Spawn is %+v", spawn)
+	}
+
+	// Class members get the members of their class, with the values saved for the member.
+	at := spawn.Members.Property("at")
+	if at == nil || len(at.Properties) != 2 {
+		t.Fatalf("member at is %+v, want the x and y of Point", at)
+	}
+	if x, _ := at.Properties.Int("x"); x != 0 {
+		t.Errorf("at.x = %d, want the default 0", x)
+	}
+	if y, _ := at.Properties.Int("y"); y != 5 {
+		t.Errorf("at.y = %d, want 5", y)
+	}
+
+	// Property types saved before Tiled 1.8 are string enums.
+	legacy := p.PropertyType("Legacy")
+	if legacy == nil || legacy.Type != PropertyTypeKindEnum || legacy.StorageType != EnumStorageString {
+		t.Errorf("Legacy is %+v, want a string enum", legacy)
+	}
+
+	if p.PropertyType("Missing") != nil {
+		t.Error("PropertyType of an unknown name is not nil")
+	}
+}
+
+func TestLoadProjectFiles(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "game.tiled-project")
+	err := os.WriteFile(name, []byte(testProject), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadProject(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Path != name || len(p.PropertyTypes) != 5 || p.PropertyType("Spawn") == nil {
+		t.Errorf("loaded %q with %d types", p.Path, len(p.PropertyTypes))
+	}
+
+	fsys := fstest.MapFS{"game/game.tiled-project": &fstest.MapFile{Data: []byte(testProject)}}
+	p, err = LoadProjectFS(fsys, "game/game.tiled-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Path != "game/game.tiled-project" || p.PropertyType("Element") == nil {
+		t.Errorf("loaded %q with %d types", p.Path, len(p.PropertyTypes))
+	}
+
+	if _, err := LoadProject(filepath.Join(t.TempDir(), "missing.tiled-project")); err == nil {
+		t.Error("loading a missing project did not return an error")
+	}
+	if _, err := LoadProjectBytes([]byte(`{"propertyTypes": [`)); err == nil {
+		t.Error("loading a broken project did not return an error")
+	}
+}
+
+func TestApplyMap(t *testing.T) {
+	p := loadTestProject(t)
+
+	// Only the members that were changed from the class are saved.
+	m := `<map version="1.10" orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16">
+ <objectgroup id="1" name="spawns">
+  <object id="1" x="0" y="0">
+   <properties>
+    <property name="spawn" type="class" propertytype="Spawn">
+     <properties>
+      <property name="at" type="class" propertytype="Point">
+       <properties>
+        <property name="x" type="int" value="3"/>
+       </properties>
+      </property>
+      <property name="count" type="int" value="4"/>
+     </properties>
+    </property>
+   </properties>
+  </object>
+ </objectgroup>
+</map>`
+
+	tmx, err := LoadTMXBytes([]byte(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.ApplyMap(tmx.Map)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	object := tmx.Map.Content[0].Value.(*ObjectGroup).Object[0]
+	spawn, err := object.Properties.Class("spawn")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The members are in the order of the class, with the defaults of the class and of the member filled in.
+	var got []string
+	for _, member := range spawn {
+		got = append(got, member.Name)
+	}
+	if strings.Join(got, ",") != "count,element,at" {
+		t.Errorf("members %v, want count, element and at", got)
+	}
+
+	var v struct {
+		Count   int    `tiled:"count"`
+		Element string `tiled:"element"`
+		At      struct {
+			X int `tiled:"x"`
+			Y int `tiled:"y"`
+		} `tiled:"at"`
+	}
+	err = spawn.Decode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Count != 4 || v.Element != "fire" || v.At.X != 3 || v.At.Y != 5 {
+		t.Errorf("spawn is %+v, want count 4, element fire and at 3,5", v)
+	}
+
+	// The members added with their default values are not saved.
+	var b bytes.Buffer
+	err = tmx.WriteTMX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{`name="element"`, `name="y"`} {
+		if strings.Contains(b.String(), name) {
+			t.Errorf("written map contains the default member %s:\n%s", name, b.String())
+		}
+	}
+	if !strings.Contains(b.String(), `name="x" type="int" value="3"`) {
+		t.Errorf("written map lost the member x:\n%s", b.String())
+	}
+}
+
+func TestApplyTileset(t *testing.T) {
+	p := loadTestProject(t)
+
+	tileset, err := LoadTSXBytes([]byte(`<tileset name="spawns" tilewidth="16" tileheight="16" tilecount="2" columns="2">
+ <properties>
+  <property name="element" propertytype="Element" value="earth"/>
+ </properties>
+ <image source="spawns.png" width="32" height="16"/>
+ <tile id="0">
+  <properties>
+   <property name="spawn" type="class" propertytype="Spawn">
+    <properties>
+     <property name="count" type="int" value="2"/>
+    </properties>
+   </property>
+  </properties>
+  <objectgroup draworder="index" id="2">
+   <object id="1" x="0" y="0" width="16" height="16">
+    <properties>
+     <property name="at" type="class" propertytype="Point"/>
+    </properties>
+   </object>
+  </objectgroup>
+ </tile>
+ <wangsets>
+  <wangset name="ground" type="corner" tile="-1">
+   <wangcolor name="grass" color="#00ff00" tile="-1" probability="1">
+    <properties>
+     <property name="layers" type="int" propertytype="Layers" value="3"/>
+    </properties>
+   </wangcolor>
+  </wangset>
+ </wangsets>
+</tileset>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.ApplyTileset(tileset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Class properties of tiles and of their collision objects get the members of their class.
+	spawn, err := tileset.TileByID(0).Properties.Class("spawn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := spawn.Int("count"); count != 2 || !spawn.Has("element") || !spawn.Has("at") {
+		t.Errorf("tile property spawn has %d members, want count 2 and the defaults of Spawn", len(spawn))
+	}
+	at, err := tileset.TileByID(0).ObjectGroup[0].Object[0].Properties.Class("at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y, _ := at.Int("y"); len(at) != 2 || y != 0 {
+		t.Errorf("object property at is %v, want the members of Point", at)
+	}
+
+	// Enum values of the tileset and of Wang colors are checked.
+	tileset.Wangsets.Wangset[0].WangColor[0].Properties[0].Value = "8"
+	err = p.ApplyTileset(tileset)
+	if err == nil || !strings.Contains(err.Error(), "layers") {
+		t.Errorf("ApplyTileset returned %v, want an error naming the property layers", err)
+	}
+}
+
+func TestApplyPropertiesEnums(t *testing.T) {
+	p := loadTestProject(t)
+
+	tests := []struct {
+		property string
+		err      bool
+	}{
+		{`<property name="e" propertytype="Element" value="water"/>`, false},
+		{`<property name="e" propertytype="Element" value="air"/>`, true},
+		{`<property name="e" type="int" propertytype="Element" value="1"/>`, true},
+		{`<property name="l" type="int" propertytype="Layers" value="0"/>`, false},
+		{`<property name="l" type="int" propertytype="Layers" value="7"/>`, false},
+		{`<property name="l" type="int" propertytype="Layers" value="8"/>`, true},
+		{`<property name="l" propertytype="Layers" value="ground"/>`, true},
+		{`<property name="x" propertytype="Unknown" value="1"/>`, true},
+		{`<property name="c" type="class"/>`, true},
+	}
+
+	for _, test := range tests {
+		err := p.ApplyProperties(decodeProperties(t, test.property))
+		if (err != nil) != test.err {
+			t.Errorf("%s: error %v, want error %t", test.property, err, test.err)
+		}
+	}
+}
+
+func TestApplyPropertiesUnknownMember(t *testing.T) {
+	p := loadTestProject(t)
+
+	properties := decodeProperties(t, `<property name="at" type="class" propertytype="Point">
+ <properties>
+  <property name="z" type="int" value="1"/>
+ </properties>
+</property>`)
+
+	err := p.ApplyProperties(properties)
+	if err == nil || !strings.Contains(err.Error(), "z") {
+		t.Errorf("ApplyProperties returned %v, want an error naming the member z", err)
+	}
+}
+
+func TestLoadProjectRecursiveClass(t *testing.T) {
+	data := `{"propertyTypes": [{"id": 1, "name": "Node", "type": "class", "members": [
+ {"name": "next", "type": "class", "propertytype": "Node", "value": {}}
+]}]}`
+
+	_, err := LoadProjectBytes([]byte(data))
+	if err == nil {
+		t.Error("loading a class that contains itself did not return an error")
+	}
+}
@@ -1,30 +0,0 @@
-package tmx
-
-import (
-	"encoding/xml"
-	"fmt"
-	"strings"
-)
-
-// Properties structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#properties
-type Properties struct {
-	XMLName xml.Name `xml:"properties"`
-
-	// Can contain: <property>
-	Property []Property `xml:",any"`
-
-	// Wraps any number of custom properties. Can be used as a child of the map, tileset, tile (when part of a tileset),
-	// terrain, layer, objectgroup, object, imagelayer and group elements.
-}
-
-func (p *Properties) String() string {
-	var b strings.Builder
-
-	fmt.Fprintf(&b, "Properties:\n")
-
-	for _, property := range p.Property {
-		fmt.Fprintf(&b, property.String())
-	}
-
-	return b.String()
-}
@@ -1,11 +1,24 @@
 package tmx
 
 import (
+	"bytes"
 	"encoding/xml"
 	"fmt"
 	"strings"
 )
 
+// Property constants
+const (
+	PropertyTypeString string = "string"
+	PropertyTypeInt    string = "int"
+	PropertyTypeFloat  string = "float"
+	PropertyTypeBool   string = "bool"
+	PropertyTypeColor  string = "color"
+	PropertyTypeFile   string = "file"
+	PropertyTypeObject string = "object"
+	PropertyTypeClass  string = "class"
+)
+
 // Property structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#property
 type Property struct {
 	XMLName xml.Name `xml:"property"`
@@ -13,13 +26,28 @@ type Property struct {
 	// The name of the property.
 	Name string `xml:"name,attr"`
 
-	// The type of the property. Can be string (default), int, float, bool, color or file (since 0.16, with color and
-	// file added in 0.17).
-	Type string `xml:"type,attr"`
+	// The type of the property. Can be string (default), int, float, bool, color, file, object or class (since 0.16,
+	// with color and file added in 0.17, object added in 1.4 and class added in 1.8).
+	Type string `xml:"type,attr,omitempty"`
 
-	// The value of the property.
+	// The name of the custom property type, the class of a class property or the enum of a string or int property.
+	// The custom types are defined in the project file. (since 1.8)
+	PropertyType string `xml:"propertytype,attr,omitempty"`
+
+	// The value of the property, or the characters inside the property element for values that contain newlines.
 	Value string `xml:"value,attr"`
 
+	// The path of a file property, Value resolved relative to the file that contains the property. Not part of the
+	// spec, it is set when the map is loaded.
+	Path string `xml:"-"`
+
+	// The members of a class property, only the members that were changed from the class are saved. Project.ApplyMap
+	// adds the others with their default value.
+	Properties PropertySet `xml:"properties>property"`
+
+	// Whether the property is a class member added with its default value, default members are not saved.
+	def bool
+
 	// Boolean properties have a value of either “true” or “false”.
 	//
 	// Color properties are stored in the format #AARRGGBB.
@@ -32,13 +60,112 @@ type Property struct {
 	// attribute.
 }
 
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element, taking the value from the
+// characters inside the element when there is no value attribute.
+func (p *Property) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	var v struct {
+		Name         string      `xml:"name,attr"`
+		Type         string      `xml:"type,attr"`
+		PropertyType string      `xml:"propertytype,attr"`
+		Value        string      `xml:"value,attr"`
+		Properties   PropertySet `xml:"properties>property"`
+		CharData     string      `xml:",chardata"`
+	}
+
+	err := decoder.DecodeElement(&v, &startElement)
+	if err != nil {
+		return err
+	}
+
+	p.XMLName = startElement.Name
+	p.Name = v.Name
+	p.Type = v.Type
+	p.PropertyType = v.PropertyType
+	p.Value = v.Value
+	p.Properties = v.Properties
+
+	// The characters inside a class property are only the whitespace around its members.
+	if p.Type == PropertyTypeClass {
+		return nil
+	}
+
+	for _, attr := range startElement.Attr {
+		if attr.Name.Local == "value" {
+			return nil
+		}
+	}
+	p.Value = v.CharData
+
+	return nil
+}
+
+// MarshalXML is called by Marshal to produce the XML element, values that contain newlines are written as the
+// characters inside the element, the same way Tiled saves them. Class members with their default value are left out.
+func (p *Property) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	if p.def {
+		return nil
+	}
+
+	var a attrs
+	a.add("name", p.Name)
+	a.str("type", p.Type, "")
+	a.str("propertytype", p.PropertyType, "")
+
+	if p.Type == PropertyTypeClass {
+		return encoder.EncodeElement(struct {
+			Properties propertyList
+		}{propertyList(p.Properties)}, startElement("property", a))
+	}
+
+	if !strings.Contains(p.Value, "\n") {
+		a.add("value", p.Value)
+		return encoder.EncodeElement(struct{}{}, startElement("property", a))
+	}
+
+	// EscapeText escapes newlines, they are kept as they are so multi-line values stay readable.
+	var b bytes.Buffer
+	err := xml.EscapeText(&b, []byte(p.Value))
+	if err != nil {
+		return err
+	}
+
+	return encoder.EncodeElement(struct {
+		InnerXML string `xml:",innerxml"`
+	}{strings.ReplaceAll(b.String(), "&#xA;", "\n")}, startElement("property", a))
+}
+
+// equal reports whether two properties have the same name, type and value, and the same saved class members.
+func (p *Property) equal(o *Property) bool {
+	if p.Name != o.Name || p.Type != o.Type || p.PropertyType != o.PropertyType || p.Value != o.Value {
+		return false
+	}
+
+	members, others := p.Properties.saved(), o.Properties.saved()
+	if len(members) != len(others) {
+		return false
+	}
+	for _, member := range members {
+		other := others.Property(member.Name)
+		if other == nil || !member.equal(other) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *Property) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Property:\n")
-	fmt.Fprintf(&b, "\tName:  (%T) %q\n", p.Name, p.Name)
-	fmt.Fprintf(&b, "\tType:  (%T) %q\n", p.Type, p.Type)
-	fmt.Fprintf(&b, "\tValue: (%T) %q\n", p.Value, p.Value)
+	fmt.Fprintf(&b, "\tName:         (%T) %q\n", p.Name, p.Name)
+	fmt.Fprintf(&b, "\tType:         (%T) %q\n", p.Type, p.Type)
+	fmt.Fprintf(&b, "\tPropertyType: (%T) %q\n", p.PropertyType, p.PropertyType)
+	fmt.Fprintf(&b, "\tValue:        (%T) %q\n", p.Value, p.Value)
+
+	for _, property := range p.Properties {
+		fmt.Fprintf(&b, property.String())
+	}
 
 	return b.String()
 }
@@ -0,0 +1,265 @@
+package tmx
+
+import (
+	"fmt"
+	"image/color"
+	"reflect"
+	"strings"
+)
+
+// DecodeError lists every problem found while decoding properties into a struct, so they can all be fixed at once.
+type DecodeError struct {
+	// The names of required properties that were not found.
+	Missing []string
+
+	// The names of properties that no field of the struct decodes.
+	Unknown []string
+
+	// The reasons properties could not be decoded into their field, such as a type mismatch.
+	Invalid []string
+}
+
+func (e *DecodeError) Error() string {
+	var problems []string
+
+	if len(e.Missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing required properties %q", e.Missing))
+	}
+	if len(e.Unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("unknown properties %q", e.Unknown))
+	}
+	problems = append(problems, e.Invalid...)
+
+	return "error decoding properties: " + strings.Join(problems, "; ")
+}
+
+// propertyField is a struct field that a property is decoded into.
+type propertyField struct {
+	// The name of the property, from the tag or the field name.
+	name string
+
+	// Whether the name came from a tag, untagged field names match properties case-insensitively.
+	tagged bool
+
+	// Whether decoding fails when the property is missing.
+	required bool
+
+	// The index of the field, for reflect.Value.FieldByIndex.
+	index []int
+
+	// The name of the field in the struct, used in errors.
+	field string
+}
+
+// Decode fills the struct v points to from the properties, similar to the way encoding/json decodes objects. Each
+// exported field is decoded from the property named by its tiled tag, or from the property with the field name when
+// there is no tag. The tag options are:
+//
+//	Speed  float64 `tiled:"speed"`           // decoded from the property "speed"
+//	Name   string  `tiled:"name,required"`   // decoding fails when "name" is missing
+//	Cache  int     `tiled:"-"`               // never decoded
+//
+// Values are converted according to the property type. String fields take string, file and color properties, file
+// properties give the resolved path. Int and uint fields take int and object properties, float fields take float and
+// int properties, bool fields take bool properties, color.NRGBA fields take color properties, and struct fields take
+// class properties, decoded from the members of the class. Pointer fields are allocated when the property is found,
+// and embedded structs without a tag are decoded as if their fields were part of the outer struct.
+//
+// Missing required properties, properties that no field decodes and values that do not match the type of their
+// field are all reported in a *DecodeError, fields that could be decoded are filled either way.
+func (s PropertySet) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("error decoding properties: %T is not a pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	e := &DecodeError{}
+	used := make(map[*Property]bool, len(s))
+
+	for _, f := range propertyFields(rv.Type(), nil) {
+		property := s.fieldProperty(f)
+		if property == nil {
+			if f.required {
+				e.Missing = append(e.Missing, f.name)
+			}
+			continue
+		}
+		used[property] = true
+
+		err := s.decodeValue(rv.FieldByIndex(f.index), property)
+		if err != nil {
+			e.Invalid = append(e.Invalid, fmt.Sprintf("field %s: %v", f.field, err))
+		}
+	}
+
+	for _, property := range s {
+		if !used[property] {
+			e.Unknown = append(e.Unknown, property.Name)
+		}
+	}
+
+	if len(e.Missing) == 0 && len(e.Unknown) == 0 && len(e.Invalid) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// fieldProperty returns the property a field is decoded from, nil when there is none.
+func (s PropertySet) fieldProperty(f propertyField) *Property {
+	if f.tagged {
+		return s.Property(f.name)
+	}
+
+	for _, property := range s {
+		if strings.EqualFold(property.Name, f.name) {
+			return property
+		}
+	}
+
+	return nil
+}
+
+// propertyFields returns the fields of a struct type that properties are decoded into, index is the index of the
+// struct within an outer struct.
+func propertyFields(t reflect.Type, index []int) []propertyField {
+	var fields []propertyField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("tiled")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		// Embedded structs without a tag are flattened.
+		if sf.Anonymous && !tagged && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, propertyFields(sf.Type, fieldIndex)...)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		f := propertyField{name: sf.Name, index: fieldIndex, field: sf.Name}
+
+		options := strings.Split(tag, ",")
+		if options[0] != "" {
+			f.name = options[0]
+			f.tagged = true
+		}
+		for _, option := range options[1:] {
+			if option == "required" {
+				f.required = true
+			}
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// decodeValue converts the value of a property to the type of a field and sets the field.
+func (s PropertySet) decodeValue(field reflect.Value, property *Property) error {
+	if field.Kind() == reflect.Ptr {
+		v := reflect.New(field.Type().Elem())
+		err := s.decodeValue(v.Elem(), property)
+		if err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(color.NRGBA{}) {
+		c, err := s.Color(property.Name)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(c))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Struct:
+		members, err := s.Class(property.Name)
+		if err != nil {
+			return err
+		}
+		err = members.Decode(field.Addr().Interface())
+		if err != nil {
+			return fmt.Errorf("class property %q: %w", property.Name, err)
+		}
+
+	case reflect.String:
+		var v string
+		var err error
+		switch property.Type {
+		case PropertyTypeFile:
+			v, err = s.File(property.Name)
+		case PropertyTypeColor:
+			v = property.Value
+		default:
+			v, err = s.StringValue(property.Name)
+		}
+		if err != nil {
+			return err
+		}
+		field.SetString(v)
+
+	case reflect.Bool:
+		v, err := s.Bool(property.Name)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := s.intValue(property)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(int64(v)) {
+			return fmt.Errorf("property %q value %d overflows %s", property.Name, v, field.Type())
+		}
+		field.SetInt(int64(v))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := s.intValue(property)
+		if err != nil {
+			return err
+		}
+		if v < 0 || field.OverflowUint(uint64(v)) {
+			return fmt.Errorf("property %q value %d overflows %s", property.Name, v, field.Type())
+		}
+		field.SetUint(uint64(v))
+
+	case reflect.Float32, reflect.Float64:
+		v, err := s.Float(property.Name)
+		if err != nil {
+			return err
+		}
+		if field.OverflowFloat(v) {
+			return fmt.Errorf("property %q value %v overflows %s", property.Name, v, field.Type())
+		}
+		field.SetFloat(v)
+
+	default:
+		return fmt.Errorf("property %q can not be decoded into %s", property.Name, field.Type())
+	}
+
+	return nil
+}
+
+// intValue returns the value of an int or object property.
+func (s PropertySet) intValue(property *Property) (int, error) {
+	if property.Type == PropertyTypeObject {
+		return s.Object(property.Name)
+	}
+	return s.Int(property.Name)
+}
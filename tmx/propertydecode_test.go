@@ -0,0 +1,138 @@
+package tmx
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestDecodeTestdata(t *testing.T) {
+	tmx, err := LoadTMXResolver(testdataFiles(t), "map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Untagged fields match the property names case-insensitively.
+	var v struct {
+		MapPropertyBool   bool
+		MapPropertyColor  color.NRGBA
+		MapPropertyFile   string
+		MapPropertyFloat  float32
+		MapPropertyInt    *int
+		MapPropertyString string `tiled:"MapPropertyString,required"`
+	}
+	err = tmx.Map.Properties.Decode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !v.MapPropertyBool || v.MapPropertyColor != (color.NRGBA{0xff, 0, 0, 0xff}) || v.MapPropertyFloat != 3.25 ||
+		v.MapPropertyString != "text" {
+		t.Errorf("decoded %+v", v)
+	}
+	if v.MapPropertyInt == nil || *v.MapPropertyInt != 1 {
+		t.Errorf("pointer field was not allocated and set to 1")
+	}
+
+	// File properties decode to the resolved path.
+	if v.MapPropertyFile != "tileset.tsx" {
+		t.Errorf("file property decoded to %q, want tileset.tsx", v.MapPropertyFile)
+	}
+}
+
+// decodeStats is a class with an embedded struct, used to test decoding class properties.
+type decodeStats struct {
+	decodeBase
+	Speed float64 `tiled:"speed"`
+}
+
+// decodeBase is embedded in decodeStats, its fields decode as fields of decodeStats.
+type decodeBase struct {
+	HP uint8 `tiled:"hp"`
+}
+
+func TestDecode(t *testing.T) {
+	properties := decodeProperties(t, `
+<property name="name" value="slime"/>
+<property name="target" type="object" value="12"/>
+<property name="tint" type="color" value="#8000ff00"/>
+<property name="stats" type="class" propertytype="Stats">
+ <properties>
+  <property name="hp" type="int" value="30"/>
+  <property name="speed" type="int" value="2"/>
+ </properties>
+</property>
+<property name="notes">line one
+line two</property>`)
+
+	var v struct {
+		Name   string `tiled:"name"`
+		Target int    `tiled:"target"`
+		Tint   string `tiled:"tint"`
+		Stats  *decodeStats
+		Notes  string
+		Cache  int `tiled:"-"`
+	}
+	v.Cache = 7
+
+	err := properties.Decode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Name != "slime" || v.Target != 12 || v.Tint != "#8000ff00" || v.Notes != "line one\nline two" {
+		t.Errorf("decoded %+v", v)
+	}
+	if v.Stats == nil || v.Stats.HP != 30 || v.Stats.Speed != 2 {
+		t.Errorf("class property decoded to %+v, want hp 30 and speed 2", v.Stats)
+	}
+	if v.Cache != 7 {
+		t.Errorf("field tagged - was changed to %d", v.Cache)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	properties := decodeProperties(t, `
+<property name="name" value="slime"/>
+<property name="hp" type="int" value="300"/>
+<property name="alive" value="yes"/>
+<property name="extra" value="unused"/>`)
+
+	var v struct {
+		Name  string `tiled:"name"`
+		HP    int8   `tiled:"hp"`
+		Alive bool   `tiled:"alive"`
+		Level int    `tiled:"level,required"`
+	}
+	err := properties.Decode(&v)
+
+	var e *DecodeError
+	if !errors.As(err, &e) {
+		t.Fatalf("Decode returned %v, want a *DecodeError", err)
+	}
+	if len(e.Missing) != 1 || e.Missing[0] != "level" {
+		t.Errorf("missing %q, want level", e.Missing)
+	}
+	if len(e.Unknown) != 1 || e.Unknown[0] != "extra" {
+		t.Errorf("unknown %q, want extra", e.Unknown)
+	}
+	if len(e.Invalid) != 2 {
+		t.Errorf("invalid %q, want the overflowing hp and the string alive", e.Invalid)
+	}
+
+	// Fields that could be decoded are filled either way.
+	if v.Name != "slime" {
+		t.Errorf("name decoded to %q, want slime", v.Name)
+	}
+}
+
+func TestDecodeNotAStruct(t *testing.T) {
+	var properties PropertySet
+
+	var i int
+	for _, v := range []interface{}{nil, i, &i, struct{}{}} {
+		if err := properties.Decode(v); err == nil {
+			t.Errorf("Decode(%T) did not return an error", v)
+		}
+	}
+}
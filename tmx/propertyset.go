@@ -0,0 +1,200 @@
+package tmx
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// PropertySet is the list of custom properties of an element, with getters that read a value as the type Tiled saved
+// it with. Every element with custom properties keeps them in a Properties field, also returned by PropertySet.
+type PropertySet []*Property
+
+// Property returns the property with a name, nil when there is none.
+func (s PropertySet) Property(name string) *Property {
+	for _, property := range s {
+		if property.Name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+// Has reports whether there is a property with a name.
+func (s PropertySet) Has(name string) bool {
+	return s.Property(name) != nil
+}
+
+// StringValue returns the value of a string property.
+func (s PropertySet) StringValue(name string) (string, error) {
+	property, err := s.typed(name, PropertyTypeString)
+	if err != nil {
+		return "", err
+	}
+	return property.Value, nil
+}
+
+// Int returns the value of an int property.
+func (s PropertySet) Int(name string) (int, error) {
+	property, err := s.typed(name, PropertyTypeInt)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.Atoi(property.Value)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing int property %q: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// Float returns the value of a float property. Int properties are read as floats too, since Tiled saves whole
+// numbers without a decimal point.
+func (s PropertySet) Float(name string) (float64, error) {
+	property, err := s.typed(name, PropertyTypeFloat, PropertyTypeInt)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := strconv.ParseFloat(property.Value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing float property %q: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// Bool returns the value of a bool property, saved as “true” or “false”.
+func (s PropertySet) Bool(name string) (bool, error) {
+	property, err := s.typed(name, PropertyTypeBool)
+	if err != nil {
+		return false, err
+	}
+
+	switch property.Value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("error parsing bool property %q: invalid value %q", name, property.Value)
+}
+
+// Color returns the value of a color property, saved as #AARRGGBB, or #RRGGBB by older versions of Tiled. A color
+// property that was left unset has an empty value and returns the zero color.
+func (s PropertySet) Color(name string) (color.NRGBA, error) {
+	property, err := s.typed(name, PropertyTypeColor)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+
+	if property.Value == "" {
+		return color.NRGBA{}, nil
+	}
+
+	c, err := ParseColor(property.Value)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("error parsing color property %q: %w", name, err)
+	}
+
+	return c, nil
+}
+
+// File returns the path of a file property, resolved relative to the file that contains the property when it was
+// loaded with a resolver, and the saved value otherwise. The path can be opened with TMX.Open.
+func (s PropertySet) File(name string) (string, error) {
+	property, err := s.typed(name, PropertyTypeFile)
+	if err != nil {
+		return "", err
+	}
+
+	if property.Path != "" {
+		return property.Path, nil
+	}
+
+	return property.Value, nil
+}
+
+// Object returns the ID of the object an object property refers to, 0 when it refers to no object. The object can be
+// found with Map.ObjectByID.
+func (s PropertySet) Object(name string) (int, error) {
+	property, err := s.typed(name, PropertyTypeObject)
+	if err != nil {
+		return 0, err
+	}
+
+	if property.Value == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.Atoi(property.Value)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing object property %q: %w", name, err)
+	}
+
+	return v, nil
+}
+
+// Class returns the members of a class property.
+func (s PropertySet) Class(name string) (PropertySet, error) {
+	property, err := s.typed(name, PropertyTypeClass)
+	if err != nil {
+		return nil, err
+	}
+	return property.Properties, nil
+}
+
+// saved returns the properties that are saved, leaving out class members with their default value.
+func (s PropertySet) saved() PropertySet {
+	saved := make(PropertySet, 0, len(s))
+	for _, property := range s {
+		if !property.def {
+			saved = append(saved, property)
+		}
+	}
+	return saved
+}
+
+// typed returns the property with a name, or an error when there is none or its type is not one of types.
+func (s PropertySet) typed(name string, types ...string) (*Property, error) {
+	property := s.Property(name)
+	if property == nil {
+		return nil, fmt.Errorf("property %q not found", name)
+	}
+
+	propertyType := property.Type
+	if propertyType == "" {
+		propertyType = PropertyTypeString
+	}
+
+	for _, t := range types {
+		if propertyType == t {
+			return property, nil
+		}
+	}
+
+	return nil, fmt.Errorf("property %q is of type %s, not %s", name, propertyType, types[0])
+}
+
+// ParseColor parses a color saved as #AARRGGBB or #RRGGBB, the leading # is optional.
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+
+	c := color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+	if len(hex) == 8 {
+		c.A = uint8(v >> 24)
+	}
+
+	return c, nil
+}
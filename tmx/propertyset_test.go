@@ -0,0 +1,153 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"image/color"
+	"testing"
+)
+
+// decodeProperties unmarshals the properties of a properties element.
+func decodeProperties(t *testing.T, data string) PropertySet {
+	t.Helper()
+
+	var properties struct {
+		Property PropertySet `xml:"property"`
+	}
+	err := xml.Unmarshal([]byte("<properties>"+data+"</properties>"), &properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return properties.Property
+}
+
+// getterProperties has a property of every type, as saved by Tiled.
+const getterProperties = `
+<property name="name" value="slime"/>
+<property name="hp" type="int" value="30"/>
+<property name="speed" type="float" value="1.5"/>
+<property name="alive" type="bool" value="true"/>
+<property name="tint" type="color" value="#8000ff00"/>
+<property name="old tint" type="color" value="#00ff00"/>
+<property name="no tint" type="color" value=""/>
+<property name="script" type="file" value="scripts/slime.lua"/>
+<property name="target" type="object" value="12"/>
+<property name="no target" type="object" value="0"/>
+<property name="broken" type="int" value="x"/>
+<property name="maybe" type="bool" value="yes"/>`
+
+func TestPropertySetHas(t *testing.T) {
+	properties := decodeProperties(t, getterProperties)
+
+	if !properties.Has("hp") || !properties.Has("no tint") {
+		t.Error("Has returned false for a property in the set")
+	}
+	if properties.Has("mp") || properties.Has("Name") {
+		t.Error("Has returned true for a property not in the set")
+	}
+	if p := properties.Property("speed"); p == nil || p.Value != "1.5" {
+		t.Errorf("Property(speed) = %v, want the speed property", p)
+	}
+}
+
+func TestPropertySetGetters(t *testing.T) {
+	properties := decodeProperties(t, getterProperties)
+
+	s, err := properties.StringValue("name")
+	if err != nil || s != "slime" {
+		t.Errorf("StringValue(name) = %q, %v, want slime", s, err)
+	}
+	i, err := properties.Int("hp")
+	if err != nil || i != 30 {
+		t.Errorf("Int(hp) = %d, %v, want 30", i, err)
+	}
+	f, err := properties.Float("speed")
+	if err != nil || f != 1.5 {
+		t.Errorf("Float(speed) = %v, %v, want 1.5", f, err)
+	}
+	// Whole numbers are saved as int properties.
+	f, err = properties.Float("hp")
+	if err != nil || f != 30 {
+		t.Errorf("Float(hp) = %v, %v, want 30", f, err)
+	}
+	b, err := properties.Bool("alive")
+	if err != nil || !b {
+		t.Errorf("Bool(alive) = %t, %v, want true", b, err)
+	}
+	file, err := properties.File("script")
+	if err != nil || file != "scripts/slime.lua" {
+		t.Errorf("File(script) = %q, %v, want scripts/slime.lua", file, err)
+	}
+
+	tests := []struct {
+		name  string
+		color color.NRGBA
+	}{
+		{"tint", color.NRGBA{0, 0xff, 0, 0x80}},
+		{"old tint", color.NRGBA{0, 0xff, 0, 0xff}},
+		{"no tint", color.NRGBA{}},
+	}
+	for _, test := range tests {
+		c, err := properties.Color(test.name)
+		if err != nil || c != test.color {
+			t.Errorf("Color(%s) = %v, %v, want %v", test.name, c, err, test.color)
+		}
+	}
+
+	for name, want := range map[string]int{"target": 12, "no target": 0} {
+		id, err := properties.Object(name)
+		if err != nil || id != want {
+			t.Errorf("Object(%s) = %d, %v, want %d", name, id, err, want)
+		}
+	}
+}
+
+func TestPropertySetGetterErrors(t *testing.T) {
+	properties := decodeProperties(t, getterProperties)
+
+	tests := []struct {
+		getter string
+		name   string
+		get    func(name string) error
+	}{
+		{"StringValue", "hp", func(name string) error { _, err := properties.StringValue(name); return err }},
+		{"StringValue", "missing", func(name string) error { _, err := properties.StringValue(name); return err }},
+		{"Int", "speed", func(name string) error { _, err := properties.Int(name); return err }},
+		{"Int", "broken", func(name string) error { _, err := properties.Int(name); return err }},
+		{"Float", "name", func(name string) error { _, err := properties.Float(name); return err }},
+		{"Bool", "name", func(name string) error { _, err := properties.Bool(name); return err }},
+		{"Bool", "maybe", func(name string) error { _, err := properties.Bool(name); return err }},
+		{"Color", "name", func(name string) error { _, err := properties.Color(name); return err }},
+		{"File", "name", func(name string) error { _, err := properties.File(name); return err }},
+		{"Object", "hp", func(name string) error { _, err := properties.Object(name); return err }},
+		{"Object", "missing", func(name string) error { _, err := properties.Object(name); return err }},
+		{"Class", "name", func(name string) error { _, err := properties.Class(name); return err }},
+	}
+
+	for _, test := range tests {
+		if err := test.get(test.name); err == nil {
+			t.Errorf("%s(%s) did not return an error", test.getter, test.name)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s     string
+		color color.NRGBA
+		err   bool
+	}{
+		{"#ffff0000", color.NRGBA{0xff, 0, 0, 0xff}, false},
+		{"#80102030", color.NRGBA{0x10, 0x20, 0x30, 0x80}, false},
+		{"#102030", color.NRGBA{0x10, 0x20, 0x30, 0xff}, false},
+		{"102030", color.NRGBA{0x10, 0x20, 0x30, 0xff}, false},
+		{"#1020", color.NRGBA{}, true},
+		{"#10203g", color.NRGBA{}, true},
+	}
+
+	for _, test := range tests {
+		c, err := ParseColor(test.s)
+		if (err != nil) != test.err || c != test.color {
+			t.Errorf("ParseColor(%q) = %v, %v, want %v and error %t", test.s, c, err, test.color, test.err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package tmx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PropertyType constants
+const (
+	PropertyTypeKindClass string = "class"
+	PropertyTypeKindEnum  string = "enum"
+
+	EnumStorageString string = "string"
+	EnumStorageInt    string = "int"
+)
+
+// PropertyType structure: https://doc.mapeditor.org/en/stable/manual/custom-properties/#custom-types
+type PropertyType struct {
+	// The unique ID of the type within the project.
+	ID int
+
+	// The name of the type, properties refer to it with their PropertyType.
+	Name string
+
+	// Whether the type is a “class” or an “enum”.
+	Type string
+
+	// How the value of an enum is saved, as a “string” or as an “int”. Enums are saved as the name of the value, or
+	// as the index of the value in Values.
+	StorageType string
+
+	// The values of an enum.
+	Values []string
+
+	// Whether more than one value of an enum can be set. String enums save the names of the values separated by
+	// commas, int enums set the bit of each value.
+	ValuesAsFlags bool
+
+	// The members of a class with their default values.
+	Members PropertySet
+
+	// The color objects of a class are drawn with, in the format #AARRGGBB.
+	Color string
+
+	// Whether objects of a class are drawn filled.
+	DrawFill bool
+
+	// The elements a class can be used as, such as “property”, “map”, “layer”, “object”, “tile” and “tileset”.
+	UseAs []string
+
+	// Custom types are defined in the project, and shared by every map and tileset of the project. Classes group a
+	// number of members, enums limit a string or int property to a list of values.
+}
+
+// validateEnum reports an error when the value of a property is not one of the values of the enum.
+func (t *PropertyType) validateEnum(property *Property) error {
+	if t.StorageType == EnumStorageInt {
+		if property.Type != PropertyTypeInt {
+			return fmt.Errorf("property %q of enum %q is of type %s, not int", property.Name, t.Name, property.Type)
+		}
+
+		v, err := strconv.Atoi(property.Value)
+		if err != nil {
+			return fmt.Errorf("error parsing enum property %q: %w", property.Name, err)
+		}
+
+		limit := len(t.Values)
+		if t.ValuesAsFlags {
+			limit = 1 << len(t.Values)
+		}
+		if v < 0 || v >= limit {
+			return fmt.Errorf("property %q has value %d, not a value of enum %q", property.Name, v, t.Name)
+		}
+
+		return nil
+	}
+
+	if property.Type != "" && property.Type != PropertyTypeString {
+		return fmt.Errorf("property %q of enum %q is of type %s, not string", property.Name, t.Name, property.Type)
+	}
+
+	values := []string{property.Value}
+	if t.ValuesAsFlags {
+		// No flags set is saved as an empty string.
+		if property.Value == "" {
+			return nil
+		}
+		values = strings.Split(property.Value, ",")
+	}
+
+	for _, value := range values {
+		if !t.hasValue(value) {
+			return fmt.Errorf("property %q has value %q, not a value of enum %q", property.Name, value, t.Name)
+		}
+	}
+
+	return nil
+}
+
+// hasValue reports whether value is one of the values of the enum.
+func (t *PropertyType) hasValue(value string) bool {
+	for _, v := range t.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *PropertyType) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "PropertyType:\n")
+	fmt.Fprintf(&b, "\tID:            (%T) %d\n", t.ID, t.ID)
+	fmt.Fprintf(&b, "\tName:          (%T) %q\n", t.Name, t.Name)
+	fmt.Fprintf(&b, "\tType:          (%T) %q\n", t.Type, t.Type)
+	fmt.Fprintf(&b, "\tStorageType:   (%T) %q\n", t.StorageType, t.StorageType)
+	fmt.Fprintf(&b, "\tValues:        (%T) %q\n", t.Values, t.Values)
+	fmt.Fprintf(&b, "\tValuesAsFlags: (%T) %t\n", t.ValuesAsFlags, t.ValuesAsFlags)
+	fmt.Fprintf(&b, "\tColor:         (%T) %q\n", t.Color, t.Color)
+	fmt.Fprintf(&b, "\tDrawFill:      (%T) %t\n", t.DrawFill, t.DrawFill)
+	fmt.Fprintf(&b, "\tUseAs:         (%T) %q\n", t.UseAs, t.UseAs)
+
+	for _, member := range t.Members {
+		fmt.Fprintf(&b, member.String())
+	}
+
+	return b.String()
+}
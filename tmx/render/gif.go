@@ -0,0 +1,236 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/go-stuff/tiled/tmx"
+)
+
+// WriteGIF writes an animated GIF of the map playing its tile animations from start for length, for previews of whole
+// maps. A frame is added each time an animated tile changes, so the GIF shows the exact timing of the animations as
+// far as the 10 millisecond steps of GIF delays allow. The GIF loops forever.
+func (r *Renderer) WriteGIF(w io.Writer, start time.Duration, length time.Duration) error {
+	if length <= 0 {
+		return fmt.Errorf("error writing gif: length %v is not positive", length)
+	}
+
+	previous := r.Clock
+	defer func() {
+		r.Clock = previous
+	}()
+
+	r.Clock = r.TMX.Map.AnimationClock()
+	end := start + length
+	times := append([]time.Duration{start}, r.Clock.Changes(start, end)...)
+	times = append(times, end)
+
+	// Each frame is converted to a paletted image as soon as it is drawn, so only one full color frame is kept.
+	var frames gifFrames
+	for i, t := range times[:len(times)-1] {
+		r.Clock.Reset()
+		r.Clock.Advance(t)
+
+		img, err := r.Render()
+		if err != nil {
+			return fmt.Errorf("error writing gif: %w", err)
+		}
+		frames.add(img, t, times[i+1], i == len(times)-2)
+	}
+
+	err := gif.EncodeAll(w, &frames.anim)
+	if err != nil {
+		return fmt.Errorf("error writing gif: %w", err)
+	}
+
+	return nil
+}
+
+// SaveGIF writes an animated GIF of the map playing its tile animations from start for length to a file.
+func (r *Renderer) SaveGIF(name string, start time.Duration, length time.Duration) error {
+	var b bytes.Buffer
+
+	err := r.WriteGIF(&b, start, length)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(name, b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing gif file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteTileGIF writes an animated GIF of one loop of the animation of a tile, cut from the tileset images with the
+// duration of each frame. Frames of different sizes are aligned to the bottom-left corner, the way Tiled draws them.
+// Tiles that are not animated are written as a single frame.
+func (r *Renderer) WriteTileGIF(w io.Writer, tileset *tmx.Tileset, id int) error {
+	frames := []*tmx.Frame{{TileID: id}}
+	if tile := tileset.TileByID(id); tile != nil && tile.Animation != nil && len(tile.Animation.Frame) > 0 {
+		frames = tile.Animation.Frame
+	}
+
+	var size image.Point
+	for _, frame := range frames {
+		rect, _ := tileset.TileRect(frame.TileID)
+		if rect.Dx() > size.X {
+			size.X = rect.Dx()
+		}
+		if rect.Dy() > size.Y {
+			size.Y = rect.Dy()
+		}
+	}
+	if size.X == 0 || size.Y == 0 {
+		return fmt.Errorf("error writing gif: tile %d of tileset %q has no image", id, tileset.Name)
+	}
+
+	var anim gifFrames
+	var t time.Duration
+	for i, frame := range frames {
+		rect, img := tileset.TileRect(frame.TileID)
+		if img == nil {
+			return fmt.Errorf("error writing gif: tile %d of tileset %q has no image", frame.TileID, tileset.Name)
+		}
+
+		src, err := r.image(img)
+		if err != nil {
+			return fmt.Errorf("error writing gif: %w", err)
+		}
+
+		dst := image.NewRGBA(image.Rectangle{Max: size})
+		drawTile(dst, src, rect, 0, float64(size.Y-rect.Dy()), float64(rect.Dx()), float64(rect.Dy()), tileFlip{}, 1)
+
+		anim.add(dst, t, t+frameDuration(frame), i == len(frames)-1)
+		t += frameDuration(frame)
+	}
+
+	err := gif.EncodeAll(w, &anim.anim)
+	if err != nil {
+		return fmt.Errorf("error writing gif: %w", err)
+	}
+
+	return nil
+}
+
+// SaveTileGIF writes an animated GIF of one loop of the animation of a tile to a file.
+func (r *Renderer) SaveTileGIF(name string, tileset *tmx.Tileset, id int) error {
+	var b bytes.Buffer
+
+	err := r.WriteTileGIF(&b, tileset, id)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(name, b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing gif file: %w", err)
+	}
+
+	return nil
+}
+
+// gifFrames builds the frames of a looping GIF from images added in the order they are shown, converting each image to
+// a paletted image as it is added. Images equal to the image before them are merged into it, and delays are rounded so
+// rounding errors do not add up over the frames.
+type gifFrames struct {
+	anim gif.GIF
+
+	// The image of the last frame, compared with the next image to merge equal frames.
+	previous *image.RGBA
+}
+
+// add adds an image shown from one time until another, last is set for the final image of the GIF.
+func (f *gifFrames) add(img *image.RGBA, from time.Duration, to time.Duration, last bool) {
+	// GIF delays are in hundredths of a second.
+	delay := int(to/(10*time.Millisecond)) - int(from/(10*time.Millisecond))
+
+	if f.previous != nil && bytes.Equal(f.previous.Pix, img.Pix) {
+		f.anim.Delay[len(f.anim.Delay)-1] += delay
+		return
+	}
+	if delay <= 0 && !last {
+		return
+	}
+
+	f.anim.Image = append(f.anim.Image, paletted(img))
+	f.anim.Delay = append(f.anim.Delay, delay)
+	f.previous = img
+}
+
+// paletted converts an image to a paletted image for a GIF. Pixels that are more than half transparent become the
+// transparent color, GIFs have no partial transparency. Images with at most 255 colors keep their exact colors, others
+// are dithered to the web safe palette.
+func paletted(img *image.RGBA) *image.Paletted {
+	transparent := color.RGBA{}
+	colors := color.Palette{transparent}
+	indexes := map[color.RGBA]uint8{transparent: 0}
+
+	opaque := func(i int) color.RGBA {
+		p := img.Pix[i : i+4]
+		if p[3] < 0x80 {
+			return transparent
+		}
+		// Undo the premultiplied alpha, the GIF color is drawn fully opaque.
+		a := uint32(p[3])
+		return color.RGBA{
+			R: uint8(uint32(p[0]) * 0xff / a),
+			G: uint8(uint32(p[1]) * 0xff / a),
+			B: uint8(uint32(p[2]) * 0xff / a),
+			A: 0xff,
+		}
+	}
+
+	exact := true
+	for i := 0; i < len(img.Pix); i += 4 {
+		c := opaque(i)
+		if _, ok := indexes[c]; ok {
+			continue
+		}
+		if len(colors) == 256 {
+			exact = false
+			break
+		}
+		indexes[c] = uint8(len(colors))
+		colors = append(colors, c)
+	}
+
+	bounds := img.Bounds()
+
+	if !exact {
+		p := image.NewPaletted(bounds, append(color.Palette{transparent}, palette.WebSafe...))
+		draw.FloydSteinberg.Draw(p, bounds, img, bounds.Min)
+
+		// Dithering does not keep transparent pixels, they are cleared again.
+		for i := 0; i < len(img.Pix); i += 4 {
+			if img.Pix[i+3] < 0x80 {
+				p.Pix[i/4] = 0
+			}
+		}
+		return p
+	}
+
+	p := image.NewPaletted(bounds, colors)
+	for i := 0; i < len(img.Pix); i += 4 {
+		p.Pix[i/4] = indexes[opaque(i)]
+	}
+
+	return p
+}
+
+// frameDuration returns how long a frame of an animation is shown, negative durations count as 0.
+func frameDuration(frame *tmx.Frame) time.Duration {
+	if frame.Duration <= 0 {
+		return 0
+	}
+	return time.Duration(frame.Duration) * time.Millisecond
+}
@@ -0,0 +1,295 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-stuff/tiled/tmx"
+)
+
+// animatedMap is a 2x1 map whose first tile shows tile 1 for 100ms and then tile 2 for 200ms, tile 3 shows tile 1 for
+// 100ms twice and then tile 2 for 100ms, and the second cell holds tile 4 which is not animated.
+const animatedMap = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" renderorder="right-down" width="2" height="1" tilewidth="2" tileheight="2">
+ <tileset firstgid="1" name="animated" tilewidth="2" tileheight="2" tilecount="5" columns="5">
+  <image source="tiles.png" width="10" height="2"/>
+  <tile id="0">
+   <animation>
+    <frame tileid="1" duration="100"/>
+    <frame tileid="2" duration="200"/>
+   </animation>
+  </tile>
+  <tile id="3">
+   <animation>
+    <frame tileid="1" duration="100"/>
+    <frame tileid="1" duration="100"/>
+    <frame tileid="2" duration="100"/>
+   </animation>
+  </tile>
+ </tileset>
+ <layer id="1" name="tiles" width="2" height="1">
+  <data encoding="csv">1,5</data>
+ </layer>
+</map>`
+
+// tilesPNG returns a 10x2 tileset image of five tiles, each filled with its own color.
+func tilesPNG(t *testing.T) []byte {
+	t.Helper()
+
+	colors := []color.RGBA{
+		{0xff, 0, 0, 0xff},
+		{0, 0xff, 0, 0xff},
+		{0, 0, 0xff, 0xff},
+		{0xff, 0xff, 0, 0xff},
+		{0xff, 0, 0xff, 0xff},
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 10, 2))
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 2; y++ {
+			img.Set(x, y, colors[x/2])
+		}
+	}
+
+	var b bytes.Buffer
+	err := png.Encode(&b, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// loadAnimatedMap loads animatedMap and its tileset image from memory.
+func loadAnimatedMap(t *testing.T) *tmx.TMX {
+	t.Helper()
+
+	m, err := tmx.LoadTMXResolver(tmx.MapResolver{
+		"maps/animated.tmx": []byte(animatedMap),
+		"maps/tiles.png":    tilesPNG(t),
+	}, "maps/animated.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// decodeGIF decodes a GIF and checks it loops forever when it has more than one frame, single frames are written
+// without a loop count.
+func decodeGIF(t *testing.T, data []byte) *gif.GIF {
+	t.Helper()
+
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) > 1 && anim.LoopCount != 0 {
+		t.Errorf("LoopCount = %d, want 0 to loop forever", anim.LoopCount)
+	}
+	if len(anim.Image) != len(anim.Delay) {
+		t.Errorf("found %d images and %d delays", len(anim.Image), len(anim.Delay))
+	}
+	return anim
+}
+
+// equalDelays reports whether two lists of GIF delays are equal.
+func equalDelays(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWriteGIF(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  time.Duration
+		length time.Duration
+		delays []int
+	}{
+		{"two loops", 0, 600 * time.Millisecond, []int{10, 20, 10, 20}},
+		{"within a frame", 0, 50 * time.Millisecond, []int{5}},
+		{"starting within a frame", 150 * time.Millisecond, 200 * time.Millisecond, []int{15, 5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewRenderer(loadAnimatedMap(t))
+
+			var b bytes.Buffer
+			err := r.WriteGIF(&b, test.start, test.length)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Clock != nil {
+				t.Error("WriteGIF did not restore the clock of the renderer")
+			}
+
+			anim := decodeGIF(t, b.Bytes())
+			if !equalDelays(anim.Delay, test.delays) {
+				t.Errorf("delays %v, want %v", anim.Delay, test.delays)
+			}
+			for _, frame := range anim.Image {
+				if got := frame.Bounds(); got != image.Rect(0, 0, 4, 2) {
+					t.Errorf("frame bounds %v, want the map size", got)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteGIFFrames(t *testing.T) {
+	r := NewRenderer(loadAnimatedMap(t))
+
+	var b bytes.Buffer
+	err := r.WriteGIF(&b, 0, 300*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim := decodeGIF(t, b.Bytes())
+
+	// The first tile shows tile 1 and then tile 2, the second tile is the same in every frame.
+	want := []color.Color{color.RGBA{0, 0xff, 0, 0xff}, color.RGBA{0, 0, 0xff, 0xff}}
+	if len(anim.Image) != len(want) {
+		t.Fatalf("found %d frames, want %d", len(anim.Image), len(want))
+	}
+	for i, frame := range anim.Image {
+		if got := color.RGBAModel.Convert(frame.At(0, 0)); got != want[i] {
+			t.Errorf("frame %d: first tile is %v, want %v", i, got, want[i])
+		}
+		if got := color.RGBAModel.Convert(frame.At(2, 0)); got != (color.RGBA{0xff, 0, 0xff, 0xff}) {
+			t.Errorf("frame %d: second tile is %v, want tile 4", i, got)
+		}
+	}
+}
+
+func TestWriteGIFLength(t *testing.T) {
+	r := NewRenderer(loadAnimatedMap(t))
+
+	err := r.WriteGIF(&bytes.Buffer{}, 0, 0)
+	if err == nil {
+		t.Error("WriteGIF with a length of 0 did not return an error")
+	}
+}
+
+func TestWriteTileGIF(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     int
+		delays []int
+	}{
+		{"animated", 0, []int{10, 20}},
+		// Frames showing the same tile are merged into one frame.
+		{"repeated frames", 3, []int{20, 10}},
+		{"not animated", 4, []int{0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := loadAnimatedMap(t)
+			r := NewRenderer(m)
+
+			var b bytes.Buffer
+			err := r.WriteTileGIF(&b, tilesetOf(t, m), test.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			anim := decodeGIF(t, b.Bytes())
+			if !equalDelays(anim.Delay, test.delays) {
+				t.Errorf("delays %v, want %v", anim.Delay, test.delays)
+			}
+			for _, frame := range anim.Image {
+				if got := frame.Bounds(); got != image.Rect(0, 0, 2, 2) {
+					t.Errorf("frame bounds %v, want the tile size", got)
+				}
+			}
+		})
+	}
+}
+
+// tilesetOf returns the first tileset of a map.
+func tilesetOf(t *testing.T, m *tmx.TMX) *tmx.Tileset {
+	t.Helper()
+
+	for _, c := range m.Map.Content {
+		if tileset, ok := c.Value.(*tmx.Tileset); ok {
+			return tileset
+		}
+	}
+	t.Fatal("map has no tileset")
+	return nil
+}
+
+func TestSaveGIF(t *testing.T) {
+	r := NewRenderer(loadAnimatedMap(t))
+
+	name := filepath.Join(t.TempDir(), "animated.gif")
+	err := r.SaveGIF(name, 0, 300*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim := decodeGIF(t, data)
+	if !equalDelays(anim.Delay, []int{10, 20}) || anim.Config.Width != 4 || anim.Config.Height != 2 {
+		t.Errorf("saved %dx%d gif with delays %v, want the 4x2 map with delays [10 20]", anim.Config.Width,
+			anim.Config.Height, anim.Delay)
+	}
+
+	err = r.SaveGIF(filepath.Join(t.TempDir(), "missing", "animated.gif"), 0, 300*time.Millisecond)
+	if err == nil {
+		t.Error("saving into a missing directory did not return an error")
+	}
+	err = r.SaveGIF(filepath.Join(t.TempDir(), "empty.gif"), 0, 0)
+	if err == nil {
+		t.Error("SaveGIF with a length of 0 did not return an error")
+	}
+}
+
+func TestSaveTileGIF(t *testing.T) {
+	m := loadAnimatedMap(t)
+	r := NewRenderer(m)
+
+	name := filepath.Join(t.TempDir(), "tile.gif")
+	err := r.SaveTileGIF(name, tilesetOf(t, m), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim := decodeGIF(t, data)
+	if !equalDelays(anim.Delay, []int{10, 20}) {
+		t.Errorf("delays %v, want [10 20]", anim.Delay)
+	}
+
+	// The frames show tile 1 and then tile 2.
+	want := []color.Color{color.RGBA{0, 0xff, 0, 0xff}, color.RGBA{0, 0, 0xff, 0xff}}
+	for i, frame := range anim.Image {
+		if got := color.RGBAModel.Convert(frame.At(1, 1)); got != want[i] {
+			t.Errorf("frame %d is %v, want %v", i, got, want[i])
+		}
+	}
+
+	err = r.SaveTileGIF(filepath.Join(t.TempDir(), "missing", "tile.gif"), tilesetOf(t, m), 0)
+	if err == nil {
+		t.Error("saving into a missing directory did not return an error")
+	}
+}
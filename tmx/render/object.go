@@ -0,0 +1,404 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-stuff/tiled/tmx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Object overlay constants
+const (
+	// The color objects are drawn with when their object group has no color, the default of Tiled.
+	defaultObjectColor string = "#a0a0a4"
+
+	// The alpha the inside of rectangles, ellipses and polygons is filled with, out of 255.
+	objectFillAlpha uint32 = 50
+
+	// The radius of the marker drawn at point objects, in pixels.
+	pointRadius float64 = 3
+
+	// The pixel size object names are drawn with.
+	namePixelSize int = 12
+)
+
+// faceKey identifies a font face in Renderer.faces.
+type faceKey struct {
+	font   *opentype.Font
+	size   int
+	bold   bool
+	italic bool
+}
+
+// objectGroupColor returns the color the objects of an object group are drawn with.
+func objectGroupColor(objectGroup *tmx.ObjectGroup) (color.NRGBA, error) {
+	s := objectGroup.Color
+	if s == "" {
+		s = defaultObjectColor
+	}
+
+	c, err := tmx.ParseColor(s)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("error parsing color of object group %q: %w", objectGroup.Name, err)
+	}
+
+	return c, nil
+}
+
+// screenVertices returns the vertices of the shape of an object on the image.
+func (r *Renderer) screenVertices(object *tmx.Object, state layerState) []tmx.Vertex {
+	vertices := object.WorldVertices(0, 0)
+	for i, v := range vertices {
+		x, y := r.TMX.Map.PixelToScreen(v.X, v.Y)
+		vertices[i] = tmx.Vertex{X: x + state.offsetX, Y: y + state.offsetY}
+	}
+	return vertices
+}
+
+// renderShape draws the outline of a rectangle, ellipse, point, polygon or polyline object, with closed shapes filled
+// by a translucent version of the color.
+func (r *Renderer) renderShape(dst *image.RGBA, object *tmx.Object, c color.NRGBA, state layerState) {
+	vertices := r.screenVertices(object, state)
+	if len(vertices) == 0 {
+		return
+	}
+
+	fill := c
+	fill.A = uint8(uint32(c.A) * objectFillAlpha / 0xff)
+
+	switch object.Shape() {
+	case tmx.ShapePoint:
+		center := vertices[0]
+		marker := make([]tmx.Vertex, 16)
+		for i := range marker {
+			sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(len(marker)))
+			marker[i] = tmx.Vertex{X: center.X + pointRadius*cos, Y: center.Y + pointRadius*sin}
+		}
+		fillPolygon(dst, marker, c, state.opacity)
+	case tmx.ShapePolyline:
+		strokeLines(dst, vertices, false, c, state.opacity)
+	default:
+		fillPolygon(dst, vertices, fill, state.opacity)
+		strokeLines(dst, vertices, true, c, state.opacity)
+	}
+}
+
+// renderText draws the text of a text object, laid out within the bounds of the object and clipped to them. The
+// object is rotated around its position.
+func (r *Renderer) renderText(dst *image.RGBA, object *tmx.Object, state layerState) error {
+	text := object.Text[0]
+
+	width, height := int(math.Ceil(object.Width)), int(math.Ceil(object.Height))
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	face, err := r.face(text.PixelSize, text.Bold, text.Italic)
+	if err != nil {
+		return err
+	}
+
+	c, err := tmx.ParseColor(text.Color)
+	if err != nil {
+		return fmt.Errorf("error parsing text color: %w", err)
+	}
+
+	// The text is drawn unrotated and clipped to the object, then drawn onto the map like a tile.
+	canvas := image.NewNRGBA(image.Rect(0, 0, width, height))
+	src := image.NewUniform(c)
+
+	metrics := face.Metrics()
+	lines := textLines(face, text.Value, text.Wrap, text.Kerning, fixed.I(width))
+	lineHeight := metrics.Height
+	textHeight := lineHeight * fixed.Int26_6(len(lines))
+
+	var top fixed.Int26_6
+	switch text.VAlign {
+	case "center":
+		top = (fixed.I(height) - textHeight) / 2
+	case "bottom":
+		top = fixed.I(height) - textHeight
+	}
+
+	for i, line := range lines {
+		lineWidth := measureText(face, line, text.Kerning)
+
+		var left fixed.Int26_6
+		switch text.HAlign {
+		case "center":
+			left = (fixed.I(width) - lineWidth) / 2
+		case "right":
+			left = fixed.I(width) - lineWidth
+		}
+
+		baseline := top + lineHeight*fixed.Int26_6(i) + metrics.Ascent
+		drawText(canvas, src, face, fixed.Point26_6{X: left, Y: baseline}, line, text.Kerning)
+
+		// Underlines and strikeouts are a line a twelfth of the pixel size thick.
+		thickness := text.PixelSize/12 + 1
+		if text.Underline {
+			y := (baseline + metrics.Descent/2).Round()
+			draw.Draw(canvas, image.Rect(left.Floor(), y, (left+lineWidth).Ceil(), y+thickness), src, image.Point{},
+				draw.Over)
+		}
+		if text.Strikeout {
+			y := (baseline - metrics.XHeight/2).Round()
+			draw.Draw(canvas, image.Rect(left.Floor(), y, (left+lineWidth).Ceil(), y+thickness), src, image.Point{},
+				draw.Over)
+		}
+	}
+
+	x, y := r.TMX.Map.PixelToScreen(object.X, object.Y)
+	x += state.offsetX
+	y += state.offsetY
+
+	flip := tileFlip{rotation: float64(object.Rotation), anchorX: x, anchorY: y}
+	drawTile(dst, canvas, canvas.Bounds(), x, y, float64(width), float64(height), flip, state.opacity)
+
+	return nil
+}
+
+// renderName draws the name of an object centered above the bounding box of its shape.
+func (r *Renderer) renderName(dst *image.RGBA, object *tmx.Object, c color.NRGBA, state layerState) error {
+	face, err := r.face(namePixelSize, false, false)
+	if err != nil {
+		return err
+	}
+
+	minX, minY, maxX := math.Inf(1), math.Inf(1), math.Inf(-1)
+	for _, v := range r.screenVertices(object, state) {
+		minX, minY, maxX = math.Min(minX, v.X), math.Min(minY, v.Y), math.Max(maxX, v.X)
+	}
+	if object.Shape() == tmx.ShapePoint {
+		minY -= pointRadius
+	}
+
+	c.A = uint8(uint32(c.A) * opacityAlpha(state.opacity) / 0xff)
+
+	width := measureText(face, object.Name, true)
+	center := fixed.Int26_6((minX + maxX) / 2 * 64)
+	dot := fixed.Point26_6{X: center - width/2, Y: fixed.Int26_6(minY*64) - face.Metrics().Descent}
+
+	drawText(dst, image.NewUniform(c), face, dot, object.Name, true)
+
+	return nil
+}
+
+// face returns the font face text is drawn with, Renderer.Font or one of the Go fonts.
+func (r *Renderer) face(pixelSize int, bold bool, italic bool) (font.Face, error) {
+	key := faceKey{font: r.Font, size: pixelSize, bold: bold, italic: italic}
+	if r.Font != nil {
+		// A font set by the user is used as it is, for every style.
+		key.bold, key.italic = false, false
+	}
+
+	if face, ok := r.faces[key]; ok {
+		return face, nil
+	}
+
+	f := r.Font
+	if f == nil {
+		var err error
+		f, err = opentype.Parse(goFontData(bold, italic))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing font: %w", err)
+		}
+	}
+
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size: float64(pixelSize),
+		// At 72 DPI the size in points is the size in pixels.
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating font face: %w", err)
+	}
+
+	if r.faces == nil {
+		r.faces = make(map[faceKey]font.Face)
+	}
+	r.faces[key] = face
+
+	return face, nil
+}
+
+// goFontData returns the TrueType data of the Go font with a style.
+func goFontData(bold bool, italic bool) []byte {
+	switch {
+	case bold && italic:
+		return gobolditalic.TTF
+	case bold:
+		return gobold.TTF
+	case italic:
+		return goitalic.TTF
+	}
+	return goregular.TTF
+}
+
+// textLines splits text into the lines it is drawn on. Lines end at line breaks, and when wrap is set also before
+// the word that does not fit the width. Words wider than the width are left on a line of their own.
+func textLines(face font.Face, text string, wrap bool, kerning bool, width fixed.Int26_6) []string {
+	var lines []string
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		if !wrap {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			if line == "" {
+				line = word
+				continue
+			}
+			if measureText(face, line+" "+word, kerning) > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// measureText returns the advance of a line of text.
+func measureText(face font.Face, text string, kerning bool) fixed.Int26_6 {
+	var width fixed.Int26_6
+
+	prev := rune(-1)
+	for _, c := range text {
+		if kerning && prev >= 0 {
+			width += face.Kern(prev, c)
+		}
+		advance, ok := face.GlyphAdvance(c)
+		if ok {
+			width += advance
+		}
+		prev = c
+	}
+
+	return width
+}
+
+// drawText draws a line of text onto dst with the baseline starting at dot.
+func drawText(dst draw.Image, src image.Image, face font.Face, dot fixed.Point26_6, text string, kerning bool) {
+	prev := rune(-1)
+	for _, c := range text {
+		if kerning && prev >= 0 {
+			dot.X += face.Kern(prev, c)
+		}
+		rect, mask, maskPoint, advance, ok := face.Glyph(dot, c)
+		if ok {
+			draw.DrawMask(dst, rect, src, image.Point{}, mask, maskPoint, draw.Over)
+		}
+		dot.X += advance
+		prev = c
+	}
+}
+
+// fillPolygon fills the inside of a polygon with a color, using the even-odd rule on the centers of the pixels.
+func fillPolygon(dst *image.RGBA, vertices []tmx.Vertex, c color.NRGBA, opacity float64) {
+	if len(vertices) < 3 {
+		return
+	}
+
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, v := range vertices {
+		minY, maxY = math.Min(minY, v.Y), math.Max(maxY, v.Y)
+	}
+
+	bounds := dst.Bounds()
+	startY := int(math.Max(math.Floor(minY), float64(bounds.Min.Y)))
+	endY := int(math.Min(math.Ceil(maxY), float64(bounds.Max.Y)))
+
+	pixel := []uint8{c.R, c.G, c.B, c.A}
+	alpha := opacityAlpha(opacity)
+
+	var crossings []float64
+	for y := startY; y < endY; y++ {
+		cy := float64(y) + 0.5
+
+		crossings = crossings[:0]
+		for i, a := range vertices {
+			b := vertices[(i+1)%len(vertices)]
+			if (a.Y <= cy) != (b.Y <= cy) {
+				crossings = append(crossings, a.X+(cy-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			startX := int(math.Max(math.Ceil(crossings[i]-0.5), float64(bounds.Min.X)))
+			endX := int(math.Min(math.Ceil(crossings[i+1]-0.5), float64(bounds.Max.X)))
+			for x := startX; x < endX; x++ {
+				blendPixel(dst, x, y, pixel, alpha)
+			}
+		}
+	}
+}
+
+// strokeLines draws one pixel wide lines between the vertices, and back to the first vertex when closed is set.
+func strokeLines(dst *image.RGBA, vertices []tmx.Vertex, closed bool, c color.NRGBA, opacity float64) {
+	pixel := []uint8{c.R, c.G, c.B, c.A}
+	alpha := opacityAlpha(opacity)
+	bounds := dst.Bounds()
+
+	// Each pixel is blended once, so lines do not get darker where they meet.
+	drawn := make(map[image.Point]bool)
+
+	count := len(vertices) - 1
+	if closed {
+		count = len(vertices)
+	}
+
+	for i := 0; i < count; i++ {
+		a, b := vertices[i], vertices[(i+1)%len(vertices)]
+
+		steps := int(math.Ceil(math.Max(math.Abs(b.X-a.X), math.Abs(b.Y-a.Y))))
+		for step := 0; step <= steps; step++ {
+			t := 0.0
+			if steps > 0 {
+				t = float64(step) / float64(steps)
+			}
+
+			p := image.Pt(int(math.Floor(a.X+(b.X-a.X)*t)), int(math.Floor(a.Y+(b.Y-a.Y)*t)))
+			if drawn[p] {
+				continue
+			}
+			drawn[p] = true
+
+			// Lines along the right and bottom edges of the image are moved back onto it.
+			if p.X == bounds.Max.X {
+				p.X--
+			}
+			if p.Y == bounds.Max.Y {
+				p.Y--
+			}
+			if p.In(bounds) {
+				blendPixel(dst, p.X, p.Y, pixel, alpha)
+			}
+		}
+	}
+}
+
+// opacityAlpha returns an opacity as an alpha out of 255.
+func opacityAlpha(opacity float64) uint32 {
+	return uint32(math.Round(math.Max(0, math.Min(opacity, 1)) * 0xff))
+}
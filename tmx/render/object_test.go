@@ -0,0 +1,206 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// shapesMap is a 128x64 map with a black background, an object group with a color holding a rectangle, ellipse,
+// point, polygon and polyline, and an object group without a color holding a named rectangle.
+const shapesMap = `<map version="1.10" orientation="orthogonal" width="32" height="16" tilewidth="4" tileheight="4"
+ backgroundcolor="#000000">
+ <objectgroup id="1" name="shapes" color="#ff0000">
+  <object id="1" x="2" y="2" width="10" height="6"/>
+  <object id="2" x="20" y="2" width="10" height="10"><ellipse/></object>
+  <object id="3" x="40" y="6"><point/></object>
+  <object id="4" x="50" y="2"><polygon points="0,0 10,0 0,10"/></object>
+  <object id="5" x="2" y="14"><polyline points="0,0 20,0"/></object>
+ </objectgroup>
+ <objectgroup id="2" name="default">
+  <object id="6" name="door" x="70" y="30" width="6" height="6"/>
+ </objectgroup>
+</map>`
+
+// textMap returns a 128x64 map with a black background and a white 96x40 text object at 8, 8, the attributes are
+// added to the text element.
+func textMap(attributes string, text string) string {
+	return fmt.Sprintf(`<map version="1.10" orientation="orthogonal" width="32" height="16" tilewidth="4" tileheight="4"
+ backgroundcolor="#000000">
+ <objectgroup id="1" name="text">
+  <object id="1" x="8" y="8" width="96" height="40"><text color="#ffffff" %s>%s</text></object>
+ </objectgroup>
+</map>`, attributes, text)
+}
+
+// renderObjects renders a map loaded with loadRenderMap with its objects and their names.
+func renderObjects(t *testing.T, data string) *image.RGBA {
+	t.Helper()
+
+	r := NewRenderer(loadRenderMap(t, data))
+	r.Objects = true
+	r.ObjectNames = true
+
+	img, err := r.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// inkBounds returns the bounds of the pixels within rect that are not black.
+func inkBounds(img *image.RGBA, rect image.Rectangle) image.Rectangle {
+	var bounds image.Rectangle
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if img.RGBAAt(x, y) != black {
+				bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return bounds
+}
+
+func TestRenderShapes(t *testing.T) {
+	img := renderObjects(t, shapesMap)
+
+	// Closed shapes are outlined in the color of their group and filled with a translucent version of it.
+	fill := color.RGBA{50, 0, 0, 0xff}
+	gray := color.RGBA{0xa0, 0xa0, 0xa4, 0xff}
+
+	checkPixels(t, img, []pixel{
+		// Rectangle.
+		{2, 4, red}, {12, 4, red}, {6, 2, red}, {6, 5, fill}, {1, 4, black}, {13, 4, black},
+		// Ellipse.
+		{25, 7, fill}, {20, 2, black}, {29, 11, black},
+		// Point.
+		{40, 6, red}, {40, 9, black},
+		// Polygon.
+		{52, 4, fill}, {50, 6, red}, {58, 10, black},
+		// Polyline, which is not closed and not filled.
+		{10, 14, red}, {10, 15, black}, {10, 13, black},
+		// Object groups without a color use the default color of Tiled.
+		{70, 32, gray}, {73, 33, color.RGBA{0x1f, 0x1f, 0x20, 0xff}},
+	})
+
+	// The name of the door is drawn centered above it.
+	name := inkBounds(img, image.Rect(62, 0, 100, 30))
+	if name.Empty() || name.Max.Y > 30 || name.Min.Y < 16 || (name.Min.X+name.Max.X)/2 < 70 ||
+		(name.Min.X+name.Max.X)/2 > 76 {
+		t.Errorf("name drawn at %v, want it centered above the door", name)
+	}
+
+	img, err := NewRenderer(loadRenderMap(t, shapesMap)).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ink := inkBounds(img, img.Bounds()); !ink.Empty() {
+		t.Errorf("objects drawn at %v without Renderer.Objects", ink)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	// The text object covers 8, 8 to 104, 48.
+	tests := []struct {
+		name       string
+		attributes string
+		text       string
+		check      func(ink image.Rectangle) bool
+	}{
+		{
+			name:  "top left",
+			text:  "Hello",
+			check: func(ink image.Rectangle) bool { return ink.Min.X < 12 && ink.Min.Y < 14 && ink.Max.X < 60 },
+		},
+		{
+			name:       "right",
+			attributes: `halign="right"`,
+			text:       "Hello",
+			check:      func(ink image.Rectangle) bool { return ink.Max.X > 100 && ink.Min.X > 50 },
+		},
+		{
+			name:       "center",
+			attributes: `halign="center" valign="center"`,
+			text:       "Hello",
+			check: func(ink image.Rectangle) bool {
+				x, y := (ink.Min.X+ink.Max.X)/2, (ink.Min.Y+ink.Max.Y)/2
+				return x > 50 && x < 62 && y > 22 && y < 34
+			},
+		},
+		{
+			name:       "bottom",
+			attributes: `valign="bottom"`,
+			text:       "Hello",
+			check:      func(ink image.Rectangle) bool { return ink.Max.Y > 40 && ink.Min.Y > 24 },
+		},
+		{
+			name:       "pixel size",
+			attributes: `pixelsize="32"`,
+			text:       "Hi",
+			check:      func(ink image.Rectangle) bool { return ink.Dy() > 20 },
+		},
+		{
+			name:  "no wrap",
+			text:  "wrapped words and more words",
+			check: func(ink image.Rectangle) bool { return ink.Dy() < 20 && ink.Max.X == 104 },
+		},
+		{
+			name:       "wrap",
+			attributes: `wrap="1"`,
+			text:       "wrapped words and more words",
+			check:      func(ink image.Rectangle) bool { return ink.Dy() > 30 && ink.Max.X < 104 },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := renderObjects(t, textMap(test.attributes, test.text))
+
+			// Text is clipped to the bounds of its object.
+			ink := inkBounds(img, img.Bounds())
+			if ink.Empty() || !ink.In(image.Rect(8, 8, 104, 48)) {
+				t.Fatalf("text drawn at %v, want it within the object", ink)
+			}
+			if !test.check(ink) {
+				t.Errorf("text drawn at %v", ink)
+			}
+		})
+	}
+}
+
+func TestRenderTextClipped(t *testing.T) {
+	img := renderObjects(t, textMap(`pixelsize="40"`, "A long line of large text"))
+
+	ink := inkBounds(img, img.Bounds())
+	if ink.Max.X != 104 {
+		t.Errorf("text drawn at %v, want it clipped at the right edge of the object", ink)
+	}
+	if !ink.In(image.Rect(8, 8, 104, 48)) {
+		t.Errorf("text drawn at %v, want it within the object", ink)
+	}
+}
+
+func TestRenderDrawOrder(t *testing.T) {
+	// The cyan object comes first but is lower on the screen than the yellow object it overlaps.
+	objects := `<object id="1" gid="4" x="0" y="8" width="4" height="4"/>
+  <object id="2" gid="2" x="0" y="6" width="4" height="4"/>`
+
+	tests := []struct {
+		drawOrder string
+		color     color.RGBA
+	}{
+		{"topdown", cyan},
+		{"index", yellow},
+	}
+
+	for _, test := range tests {
+		t.Run(test.drawOrder, func(t *testing.T) {
+			img := renderMap(t, orthogonalMap(1, 2, "", fmt.Sprintf(`<objectgroup id="1" name="objects" draworder="%s">
+  %s
+ </objectgroup>`, test.drawOrder, objects)))
+
+			checkPixels(t, img, []pixel{{0, 2, yellow}, {0, 5, test.color}, {0, 7, cyan}})
+		})
+	}
+}
@@ -0,0 +1,585 @@
+// Package render draws the maps loaded by package tmx into images and animated GIFs in software, without a GPU. It is
+// kept apart from package tmx so the parser does not depend on image decoders and fonts.
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+
+	"github.com/go-stuff/tiled/tmx"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+
+	// Tileset and image layer images are decoded with the decoders registered by these packages.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Renderer draws a map into an image in software, without a GPU. Tile layers, image layers and tile objects are drawn
+// in the order of tmx.Map.Content, the same way Tiled draws them.
+type Renderer struct {
+	// The map to draw, its images are opened with tmx.TMX.Resolver.
+	TMX *tmx.TMX
+
+	// Whether the other objects of object groups are drawn too, as an overlay for reviewing levels. Rectangles,
+	// ellipses, points, polygons and polylines are drawn in the color of their object group, and text objects with
+	// their text.
+	Objects bool
+
+	// Whether the names of objects are drawn above them, when Objects is set.
+	ObjectNames bool
+
+	// The clock animated tiles are drawn with, they show their first frame when it is nil.
+	Clock *tmx.AnimationClock
+
+	// The font text objects and object names are drawn with. When it is nil the bundled Go fonts are used, with their
+	// bold and italic variants.
+	Font *opentype.Font
+
+	// The decoded images, by path, so each image is only decoded once.
+	images map[string]*image.NRGBA
+
+	// The font faces text is drawn with, by font and size.
+	faces map[faceKey]font.Face
+}
+
+// NewRenderer returns a renderer that draws the map of t.
+func NewRenderer(t *tmx.TMX) *Renderer {
+	return &Renderer{
+		TMX:    t,
+		images: make(map[string]*image.NRGBA),
+	}
+}
+
+// layerState is the state a layer inherits from the groups that contain it.
+type layerState struct {
+	offsetX float64
+	offsetY float64
+	opacity float64
+}
+
+// Render draws the map into a new image the size of the map in screen pixels, filled with tmx.Map.BackgroundColor.
+// Hidden layers and objects are left out, the opacity and offsets of groups apply to every layer they contain. Infinite
+// maps are drawn into an image that fits the chunks of every tile layer, with the top-left chunk at 0, 0.
+func (r *Renderer) Render() (*image.RGBA, error) {
+	m := r.TMX.Map
+
+	bounds := renderBounds(m)
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	if m.BackgroundColor != "" {
+		c, err := tmx.ParseColor(m.BackgroundColor)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing background color: %w", err)
+		}
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	parent := layerState{
+		offsetX: float64(-bounds.Min.X),
+		offsetY: float64(-bounds.Min.Y),
+		opacity: 1,
+	}
+
+	err := r.renderContent(dst, m.Content, parent)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering map: %w", err)
+	}
+
+	return dst, nil
+}
+
+// renderContent draws the layers of tmx.Map.Content or Group.Content.
+func (r *Renderer) renderContent(dst *image.RGBA, content []tmx.Content, parent layerState) error {
+	for _, c := range content {
+		var err error
+
+		switch v := c.Value.(type) {
+		case *tmx.Layer:
+			if v.Visible {
+				err = r.renderLayer(dst, v, parent.child(v.OffsetX, v.OffsetY, v.Opacity))
+			}
+		case *tmx.ImageLayer:
+			if v.Visible != 0 {
+				err = r.renderImageLayer(dst, v, parent.child(v.OffsetX, v.OffsetY, v.Opacity))
+			}
+		case *tmx.ObjectGroup:
+			if v.Visible != 0 {
+				err = r.renderObjectGroup(dst, v, parent.child(v.OffsetX, v.OffsetY, v.Opacity))
+			}
+		case *tmx.Group:
+			if v.Visible {
+				err = r.renderContent(dst, v.Content, parent.child(v.OffsetX, v.OffsetY, v.Opacity))
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// child returns the state of a layer within the parent, with its own offsets and opacity added.
+func (p layerState) child(offsetX float32, offsetY float32, opacity float32) layerState {
+	return layerState{
+		offsetX: p.offsetX + float64(offsetX),
+		offsetY: p.offsetY + float64(offsetY),
+		opacity: p.opacity * float64(opacity),
+	}
+}
+
+// renderCell is a tile placed on a tile layer.
+type renderCell struct {
+	x   int
+	y   int
+	gid tmx.GID
+}
+
+// renderLayer draws the tiles of a tile layer. Each tile image is aligned to the bottom-left corner of the bounding box
+// of its cell, moved by the tile offset of its tileset.
+func (r *Renderer) renderLayer(dst *image.RGBA, layer *tmx.Layer, state layerState) error {
+	m := r.TMX.Map
+
+	var cells []renderCell
+	err := layer.TileGrid().EachTile(func(x int, y int, gid tmx.GID) error {
+		cells = append(cells, renderCell{x, y, gid})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sortCells(m, cells)
+
+	for _, cell := range cells {
+		ref, err := r.resolveGID(cell.gid)
+		if err != nil {
+			return fmt.Errorf("error rendering layer %q: %w", layer.Name, err)
+		}
+		if ref.Image == nil {
+			continue
+		}
+
+		src, err := r.image(ref.Image)
+		if err != nil {
+			return err
+		}
+
+		width, height := float64(ref.Rect.Dx()), float64(ref.Rect.Dy())
+		flip := tileFlip{h: cell.gid.FlippedHorizontally(), v: cell.gid.FlippedVertically()}
+
+		// On hexagonal maps the diagonal flag rotates the tile by 60 degrees, instead of flipping it.
+		if m.Orientation == tmx.OrientationHexagonal {
+			if cell.gid.FlippedDiagonally() {
+				flip.rotation += 60
+			}
+			if cell.gid.RotatedHexagonal120() {
+				flip.rotation += 120
+			}
+		} else if cell.gid.FlippedDiagonally() {
+			flip.d = true
+			width, height = height, width
+		}
+
+		originX, originY := m.TileRenderOrigin(cell.x, cell.y)
+		x := originX + state.offsetX
+		y := originY - height + state.offsetY
+		if ref.Tileset.TileOffset != nil {
+			x += float64(ref.Tileset.TileOffset.X)
+			y += float64(ref.Tileset.TileOffset.Y)
+		}
+
+		// Hexagonal rotations turn the tile around its center.
+		flip.anchorX, flip.anchorY = x+width/2, y+height/2
+
+		drawTile(dst, src, ref.Rect, x, y, width, height, flip, state.opacity)
+	}
+
+	return nil
+}
+
+// sortCells sorts tiles into the order Tiled draws them. Orthogonal maps follow tmx.Map.RenderOrder, other
+// orientations draw from the top of the screen down so tiles in front overlap the tiles behind them.
+func sortCells(m *tmx.Map, cells []renderCell) {
+	if m.Orientation == "" || m.Orientation == tmx.OrientationOrthogonal {
+		up := m.RenderOrder == tmx.RenderOrderRightUp || m.RenderOrder == tmx.RenderOrderLeftUp
+		left := m.RenderOrder == tmx.RenderOrderLeftDown || m.RenderOrder == tmx.RenderOrderLeftUp
+
+		sort.SliceStable(cells, func(i, j int) bool {
+			a, b := cells[i], cells[j]
+			if a.y != b.y {
+				return (a.y < b.y) != up
+			}
+			return (a.x < b.x) != left
+		})
+		return
+	}
+
+	sort.SliceStable(cells, func(i, j int) bool {
+		ax, ay := m.TileToScreen(cells[i].x, cells[i].y)
+		bx, by := m.TileToScreen(cells[j].x, cells[j].y)
+		if ay != by {
+			return ay < by
+		}
+		return ax < bx
+	})
+}
+
+// renderImageLayer draws the image of an image layer at the offsets of the layer.
+func (r *Renderer) renderImageLayer(dst *image.RGBA, layer *tmx.ImageLayer, state layerState) error {
+	if layer.Image == nil || (layer.Image.Source == "" && layer.Image.Path == "") {
+		return nil
+	}
+
+	src, err := r.image(layer.Image)
+	if err != nil {
+		return err
+	}
+
+	size := src.Bounds().Size()
+	drawTile(dst, src, src.Bounds(), state.offsetX, state.offsetY, float64(size.X), float64(size.Y), tileFlip{},
+		state.opacity)
+
+	return nil
+}
+
+// renderObjectGroup draws the tile objects of an object group, and the other objects when Renderer.Objects is set.
+// Objects are drawn from the top of the screen down, unless the draw order of the group is “index”.
+func (r *Renderer) renderObjectGroup(dst *image.RGBA, objectGroup *tmx.ObjectGroup, state layerState) error {
+	m := r.TMX.Map
+
+	objects := make([]*tmx.Object, 0, len(objectGroup.Object))
+	for _, object := range objectGroup.Object {
+		if object.Visible {
+			objects = append(objects, object)
+		}
+	}
+
+	if objectGroup.DrawOrder != "index" {
+		sort.SliceStable(objects, func(i, j int) bool {
+			_, yi := m.PixelToScreen(objects[i].X, objects[i].Y)
+			_, yj := m.PixelToScreen(objects[j].X, objects[j].Y)
+			return yi < yj
+		})
+	}
+
+	groupColor, err := objectGroupColor(objectGroup)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		var err error
+
+		switch {
+		case object.GID.ID() != 0:
+			err = r.renderTileObject(dst, object, state)
+		case r.Objects && object.Shape() == tmx.ShapeText:
+			err = r.renderText(dst, object, state)
+		case r.Objects:
+			r.renderShape(dst, object, groupColor, state)
+		}
+		if err != nil {
+			return fmt.Errorf("error rendering object %d: %w", object.ID, err)
+		}
+
+		if r.Objects && r.ObjectNames && object.Name != "" {
+			err = r.renderName(dst, object, groupColor, state)
+			if err != nil {
+				return fmt.Errorf("error rendering object %d: %w", object.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderTileObject draws the tile of a tile object, scaled to the size of the object and rotated around its position.
+// Tile objects are aligned by their bottom-left corner, or by their bottom center on isometric maps.
+func (r *Renderer) renderTileObject(dst *image.RGBA, object *tmx.Object, state layerState) error {
+	m := r.TMX.Map
+
+	ref, err := r.resolveGID(object.GID)
+	if err != nil {
+		return err
+	}
+	if ref.Image == nil {
+		return nil
+	}
+
+	src, err := r.image(ref.Image)
+	if err != nil {
+		return err
+	}
+
+	width, height := object.Width, object.Height
+	if width == 0 && height == 0 {
+		width, height = float64(ref.Rect.Dx()), float64(ref.Rect.Dy())
+	}
+
+	anchorX, anchorY := m.PixelToScreen(object.X, object.Y)
+	anchorX += state.offsetX
+	anchorY += state.offsetY
+
+	x, y := anchorX, anchorY-height
+	if m.Orientation == tmx.OrientationIsometric {
+		x -= width / 2
+	}
+	if ref.Tileset.TileOffset != nil {
+		x += float64(ref.Tileset.TileOffset.X)
+		y += float64(ref.Tileset.TileOffset.Y)
+	}
+
+	flip := tileFlip{
+		h:        object.GID.FlippedHorizontally(),
+		v:        object.GID.FlippedVertically(),
+		d:        object.GID.FlippedDiagonally(),
+		rotation: float64(object.Rotation),
+		anchorX:  anchorX,
+		anchorY:  anchorY,
+	}
+
+	drawTile(dst, src, ref.Rect, x, y, width, height, flip, state.opacity)
+
+	return nil
+}
+
+// resolveGID resolves a global tile ID to the tile drawn for it, the current frame of animated tiles.
+func (r *Renderer) resolveGID(gid tmx.GID) (*tmx.TileRef, error) {
+	ref, err := r.TMX.Map.ResolveGID(gid)
+	if err != nil {
+		return nil, err
+	}
+
+	id := ref.ID
+	if r.Clock != nil {
+		id = r.Clock.TileID(ref.Tileset, ref.ID)
+	} else if ref.Tile != nil && ref.Tile.Animation != nil && len(ref.Tile.Animation.Frame) > 0 {
+		id = ref.Tile.Animation.Frame[0].TileID
+	}
+
+	if id != ref.ID {
+		ref.ID = id
+		ref.Tile = ref.Tileset.TileByID(id)
+		ref.Rect, ref.Image = ref.Tileset.TileRect(id)
+	}
+
+	return ref, nil
+}
+
+// image returns the decoded pixels of an image, with the pixels of its transparent color cleared.
+func (r *Renderer) image(img *tmx.Image) (*image.NRGBA, error) {
+	name := img.Path
+	if name == "" {
+		name = img.Source
+	}
+
+	if src, ok := r.images[name]; ok {
+		return src, nil
+	}
+
+	resolver := r.TMX.Resolver
+	if resolver == nil {
+		resolver = tmx.OSResolver{}
+	}
+
+	f, err := resolver.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("error opening image %q: %w", name, err)
+	}
+	defer f.Close()
+
+	decoded, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding image %q: %w", name, err)
+	}
+
+	src := image.NewNRGBA(decoded.Bounds())
+	draw.Draw(src, src.Bounds(), decoded, decoded.Bounds().Min, draw.Src)
+
+	if img.Trans != "" {
+		trans, err := tmx.ParseColor(img.Trans)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing transparent color of image %q: %w", name, err)
+		}
+		clearColor(src, trans)
+	}
+
+	if r.images == nil {
+		r.images = make(map[string]*image.NRGBA)
+	}
+	r.images[name] = src
+
+	return src, nil
+}
+
+// clearColor makes every pixel with the color of key transparent, the alpha of key is ignored.
+func clearColor(img *image.NRGBA, key color.NRGBA) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		if img.Pix[i] == key.R && img.Pix[i+1] == key.G && img.Pix[i+2] == key.B {
+			img.Pix[i+3] = 0
+		}
+	}
+}
+
+// renderBounds returns the screen rectangle the map is drawn in. Fixed size maps start at 0, 0, infinite maps cover
+// the bounding boxes of the cells of every chunk that holds a tile.
+func renderBounds(m *tmx.Map) image.Rectangle {
+	width, height := m.ScreenSize()
+	if m.Infinite == 0 {
+		return image.Rect(0, 0, width, height)
+	}
+
+	tiles, ok := contentTileBounds(m.Content)
+	if !ok {
+		return image.Rect(0, 0, width, height)
+	}
+
+	var bounds image.Rectangle
+	corners := []image.Point{
+		tiles.Min,
+		{tiles.Max.X - 1, tiles.Min.Y},
+		{tiles.Min.X, tiles.Max.Y - 1},
+		{tiles.Max.X - 1, tiles.Max.Y - 1},
+	}
+	for _, corner := range corners {
+		bounds = bounds.Union(cellBounds(m, corner.X, corner.Y))
+	}
+
+	return bounds
+}
+
+// cellBounds returns the screen bounding box of the cell of a tile.
+func cellBounds(m *tmx.Map, x int, y int) image.Rectangle {
+	originX, originY := m.TileRenderOrigin(x, y)
+	minX := int(math.Floor(originX))
+	maxY := int(math.Ceil(originY))
+
+	return image.Rect(minX, maxY-m.TileHeight, minX+m.TileWidth, maxY)
+}
+
+// contentTileBounds returns the rectangle in tiles covered by the tiles of the tile layers in content.
+func contentTileBounds(content []tmx.Content) (image.Rectangle, bool) {
+	var bounds image.Rectangle
+	found := false
+
+	for _, c := range content {
+		var r image.Rectangle
+		switch v := c.Value.(type) {
+		case *tmx.Layer:
+			r = v.TileGrid().Bounds()
+		case *tmx.Group:
+			r, _ = contentTileBounds(v.Content)
+		}
+		if r.Empty() {
+			continue
+		}
+
+		if !found {
+			bounds, found = r, true
+		}
+		bounds = bounds.Union(r)
+	}
+
+	return bounds, found
+}
+
+// tileFlip is how a tile image is flipped and rotated when it is drawn.
+type tileFlip struct {
+	// Whether the image is flipped horizontally, vertically and diagonally. The diagonal flip is done first.
+	h bool
+	v bool
+	d bool
+
+	// The clockwise rotation in degrees, around the anchor in screen pixels.
+	rotation float64
+	anchorX  float64
+	anchorY  float64
+}
+
+// drawTile draws the src rectangle of an image into the rectangle x, y, width, height of dst, flipped, rotated and
+// scaled with nearest neighbour sampling, and blended over dst with the opacity.
+func drawTile(dst *image.RGBA, src *image.NRGBA, srcRect image.Rectangle, x float64, y float64, width float64,
+	height float64, flip tileFlip, opacity float64) {
+
+	if srcRect.Empty() || width <= 0 || height <= 0 || opacity <= 0 {
+		return
+	}
+	srcRect = srcRect.Intersect(src.Bounds())
+	if srcRect.Empty() {
+		return
+	}
+
+	sin, cos := math.Sincos(flip.rotation * math.Pi / 180)
+
+	// The bounding box of the rotated rectangle on dst.
+	corners := []tmx.Vertex{{X: x, Y: y}, {X: x + width, Y: y}, {X: x, Y: y + height}, {X: x + width, Y: y + height}}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, c := range corners {
+		dx, dy := c.X-flip.anchorX, c.Y-flip.anchorY
+		rx := flip.anchorX + dx*cos - dy*sin
+		ry := flip.anchorY + dx*sin + dy*cos
+		minX, minY = math.Min(minX, rx), math.Min(minY, ry)
+		maxX, maxY = math.Max(maxX, rx), math.Max(maxY, ry)
+	}
+
+	area := image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+	area = area.Intersect(dst.Bounds())
+
+	srcWidth, srcHeight := srcRect.Dx(), srcRect.Dy()
+	alpha := opacityAlpha(opacity)
+
+	for py := area.Min.Y; py < area.Max.Y; py++ {
+		for px := area.Min.X; px < area.Max.X; px++ {
+			// Rotate the center of the pixel back, and find where it falls within the rectangle.
+			dx, dy := float64(px)+0.5-flip.anchorX, float64(py)+0.5-flip.anchorY
+			u := (flip.anchorX + dx*cos + dy*sin - x) / width
+			v := (flip.anchorY - dx*sin + dy*cos - y) / height
+			if u < 0 || u >= 1 || v < 0 || v >= 1 {
+				continue
+			}
+
+			// Undo the flips in the reverse order they are applied.
+			if flip.v {
+				v = 1 - v
+			}
+			if flip.h {
+				u = 1 - u
+			}
+			if flip.d {
+				u, v = v, u
+			}
+
+			sx := srcRect.Min.X + int(u*float64(srcWidth))
+			sy := srcRect.Min.Y + int(v*float64(srcHeight))
+			if sx >= srcRect.Max.X || sy >= srcRect.Max.Y {
+				continue
+			}
+
+			blendPixel(dst, px, py, src.Pix[src.PixOffset(sx, sy):], alpha)
+		}
+	}
+}
+
+// blendPixel blends a non-premultiplied source pixel, with its alpha scaled by alpha, over a pixel of dst.
+func blendPixel(dst *image.RGBA, x int, y int, s []uint8, alpha uint32) {
+	sa := uint32(s[3]) * alpha / 0xff
+	if sa == 0 {
+		return
+	}
+
+	d := dst.Pix[dst.PixOffset(x, y):]
+	inv := 0xff - sa
+
+	d[0] = uint8((uint32(s[0])*sa + uint32(d[0])*inv) / 0xff)
+	d[1] = uint8((uint32(s[1])*sa + uint32(d[1])*inv) / 0xff)
+	d[2] = uint8((uint32(s[2])*sa + uint32(d[2])*inv) / 0xff)
+	d[3] = uint8(sa + uint32(d[3])*inv/0xff)
+}
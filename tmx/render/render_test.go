@@ -0,0 +1,466 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/go-stuff/tiled/tmx"
+)
+
+// The colors of the test images.
+var (
+	red     = color.RGBA{0xff, 0, 0, 0xff}
+	green   = color.RGBA{0, 0xff, 0, 0xff}
+	blue    = color.RGBA{0, 0, 0xff, 0xff}
+	white   = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	yellow  = color.RGBA{0xff, 0xff, 0, 0xff}
+	magenta = color.RGBA{0xff, 0, 0xff, 0xff}
+	cyan    = color.RGBA{0, 0xff, 0xff, 0xff}
+	black   = color.RGBA{0, 0, 0, 0xff}
+	none    = color.RGBA{}
+)
+
+// renderTileset is a tileset of four 4x4 tiles: tile 0 has a red, green, blue and white quarter from its top-left
+// corner in reading order, so flips can be told apart, tiles 1, 2 and 3 are yellow, magenta and cyan.
+const renderTileset = `<tileset firstgid="1" name="tiles" tilewidth="4" tileheight="4" tilecount="4" columns="4">
+  <image source="tiles.png" width="16" height="4"/>
+ </tileset>`
+
+// pixel is the color expected at a pixel of a rendered map.
+type pixel struct {
+	x     int
+	y     int
+	color color.RGBA
+}
+
+// encodePNG encodes an image as PNG.
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	err := png.Encode(&b, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// fillImage returns an image with a column of width pixels in each color, height pixels high.
+func fillImage(width int, height int, colors ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width*len(colors), height))
+	for x := 0; x < img.Rect.Dx(); x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, colors[x/width])
+		}
+	}
+	return img
+}
+
+// renderFiles returns the images the test maps use: tiles.png of renderTileset, iso.png with red, green, blue and
+// yellow 8x4 tiles and layer.png with a magenta and a white half.
+func renderFiles(t *testing.T) tmx.MapResolver {
+	t.Helper()
+
+	tiles := fillImage(4, 4, red, yellow, magenta, cyan)
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			tiles.Set(x, y, []color.RGBA{red, green, blue, white}[y/2*2+x/2])
+		}
+	}
+
+	return tmx.MapResolver{
+		"tiles.png": encodePNG(t, tiles),
+		"iso.png":   encodePNG(t, fillImage(8, 4, red, green, blue, yellow)),
+		"layer.png": encodePNG(t, fillImage(2, 4, magenta, white)),
+	}
+}
+
+// loadRenderMap loads a map from memory with the images of renderFiles.
+func loadRenderMap(t *testing.T, data string) *tmx.TMX {
+	t.Helper()
+
+	files := renderFiles(t)
+	files["map.tmx"] = []byte(data)
+
+	m, err := tmx.LoadTMXResolver(files, "map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// renderMap renders a map loaded with loadRenderMap.
+func renderMap(t *testing.T, data string) *image.RGBA {
+	t.Helper()
+
+	img, err := NewRenderer(loadRenderMap(t, data)).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// orthogonalMap returns a map of 4x4 tiles with renderTileset and the content, the attributes are added to the map
+// element.
+func orthogonalMap(width int, height int, attributes string, content string) string {
+	return fmt.Sprintf(`<map version="1.10" orientation="orthogonal" renderorder="right-down" width="%d" height="%d"
+ tilewidth="4" tileheight="4" %s>
+ %s
+ %s
+</map>`, width, height, attributes, renderTileset, content)
+}
+
+// checkPixels checks the colors of pixels of a rendered map.
+func checkPixels(t *testing.T, img *image.RGBA, pixels []pixel) {
+	t.Helper()
+
+	for _, p := range pixels {
+		if got := img.RGBAAt(p.x, p.y); got != p.color {
+			t.Errorf("pixel %d,%d is %v, want %v", p.x, p.y, got, p.color)
+		}
+	}
+}
+
+func TestRenderFlips(t *testing.T) {
+	img := renderMap(t, orthogonalMap(4, 1, "", `<layer id="1" name="flips" width="4" height="1">
+  <data encoding="csv">1,2147483649,1073741825,536870913</data>
+ </layer>`))
+
+	if img.Bounds() != image.Rect(0, 0, 16, 4) {
+		t.Fatalf("image bounds %v, want the map size", img.Bounds())
+	}
+
+	checkPixels(t, img, []pixel{
+		// Not flipped.
+		{0, 0, red}, {3, 0, green}, {0, 3, blue}, {3, 3, white},
+		// Flipped horizontally.
+		{4, 0, green}, {7, 0, red}, {4, 3, white},
+		// Flipped vertically.
+		{8, 0, blue}, {11, 0, white}, {8, 3, red},
+		// Flipped diagonally, the top-right and bottom-left quarters swap.
+		{12, 0, red}, {15, 0, blue}, {12, 3, green}, {15, 3, white},
+	})
+}
+
+func TestRenderContentOrder(t *testing.T) {
+	imageLayer := `<imagelayer id="1" name="image"><image source="layer.png" width="4" height="4"/></imagelayer>`
+	tileLayer := `<layer id="2" name="tiles" width="1" height="1"><data encoding="csv">2</data></layer>`
+	objectGroup := `<objectgroup id="3" name="objects">
+  <object id="1" gid="4" x="0" y="4" width="2" height="2"/>
+ </objectgroup>`
+
+	tests := []struct {
+		name    string
+		content string
+		pixels  []pixel
+	}{
+		{
+			name:    "image, tiles, objects",
+			content: imageLayer + tileLayer + objectGroup,
+			pixels:  []pixel{{0, 0, yellow}, {3, 0, yellow}, {0, 3, cyan}, {1, 2, cyan}, {2, 3, yellow}},
+		},
+		{
+			name:    "objects, tiles, image",
+			content: objectGroup + tileLayer + imageLayer,
+			pixels:  []pixel{{0, 0, magenta}, {3, 0, white}, {0, 3, magenta}},
+		},
+		{
+			name:    "tiles, image, objects",
+			content: tileLayer + imageLayer + objectGroup,
+			pixels:  []pixel{{0, 0, magenta}, {3, 0, white}, {0, 3, cyan}, {3, 3, white}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkPixels(t, renderMap(t, orthogonalMap(1, 1, "", test.content)), test.pixels)
+		})
+	}
+}
+
+func TestRenderVisible(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"layer", `<layer id="1" name="tiles" width="1" height="1" visible="0"><data encoding="csv">2</data></layer>`},
+		{"image layer", `<imagelayer id="1" name="image" visible="0"><image source="layer.png"/></imagelayer>`},
+		{"object group", `<objectgroup id="1" name="objects" visible="0">
+  <object id="1" gid="2" x="0" y="4" width="4" height="4"/>
+ </objectgroup>`},
+		{"object", `<objectgroup id="1" name="objects">
+  <object id="1" gid="2" x="0" y="4" width="4" height="4" visible="0"/>
+ </objectgroup>`},
+		// Hidden groups hide the visible layers they contain.
+		{"group", `<group id="1" name="group" visible="0">
+  <layer id="2" name="tiles" width="1" height="1"><data encoding="csv">2</data></layer>
+ </group>`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := renderMap(t, orthogonalMap(1, 1, `backgroundcolor="#000000"`, test.content))
+			checkPixels(t, img, []pixel{{0, 0, black}, {3, 3, black}})
+		})
+	}
+}
+
+func TestRenderOpacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		color   color.RGBA
+	}{
+		{
+			name: "layer",
+			content: `<layer id="1" name="tiles" width="1" height="1" opacity="0.5">
+  <data encoding="csv">2</data>
+ </layer>`,
+			color: color.RGBA{0x80, 0x80, 0, 0xff},
+		},
+		{
+			name: "object group",
+			content: `<objectgroup id="1" name="objects" opacity="0.5">
+  <object id="1" gid="2" x="0" y="4" width="4" height="4"/>
+ </objectgroup>`,
+			color: color.RGBA{0x80, 0x80, 0, 0xff},
+		},
+		{
+			// The opacity of a group multiplies the opacity of the layers it contains.
+			name: "group",
+			content: `<group id="1" name="group" opacity="0.5">
+  <layer id="2" name="tiles" width="1" height="1" opacity="0.5"><data encoding="csv">2</data></layer>
+ </group>`,
+			color: color.RGBA{0x40, 0x40, 0, 0xff},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := renderMap(t, orthogonalMap(1, 1, `backgroundcolor="#000000"`, test.content))
+			checkPixels(t, img, []pixel{{0, 0, test.color}, {3, 3, test.color}})
+		})
+	}
+}
+
+func TestRenderOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		pixels  []pixel
+	}{
+		{
+			name: "layer",
+			content: `<layer id="1" name="tiles" width="3" height="2" offsetx="4" offsety="2">
+  <data encoding="csv">2,0,0,0,0,0</data>
+ </layer>`,
+			pixels: []pixel{{3, 2, black}, {4, 1, black}, {4, 2, yellow}, {7, 5, yellow}, {8, 2, black}},
+		},
+		{
+			name: "image layer",
+			content: `<imagelayer id="1" name="image" offsetx="4" offsety="2">
+  <image source="layer.png" width="4" height="4"/>
+ </imagelayer>`,
+			pixels: []pixel{{3, 2, black}, {4, 2, magenta}, {7, 5, white}, {8, 2, black}},
+		},
+		{
+			name: "object group",
+			content: `<objectgroup id="1" name="objects" offsetx="4" offsety="2">
+  <object id="1" gid="2" x="0" y="4" width="4" height="4"/>
+ </objectgroup>`,
+			pixels: []pixel{{3, 2, black}, {4, 2, yellow}, {7, 5, yellow}, {8, 2, black}},
+		},
+		{
+			// The offsets of a group are added to the offsets of the layers it contains.
+			name: "group",
+			content: `<group id="1" name="group" offsetx="2" offsety="1">
+  <layer id="2" name="tiles" width="3" height="2" offsetx="2" offsety="1">
+   <data encoding="csv">2,0,0,0,0,0</data>
+  </layer>
+ </group>`,
+			pixels: []pixel{{3, 2, black}, {4, 1, black}, {4, 2, yellow}, {7, 5, yellow}, {8, 2, black}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkPixels(t, renderMap(t, orthogonalMap(3, 2, `backgroundcolor="#000000"`, test.content)), test.pixels)
+		})
+	}
+}
+
+func TestRenderTileOffset(t *testing.T) {
+	img := renderMap(t, `<map version="1.10" orientation="orthogonal" width="2" height="2" tilewidth="4" tileheight="4"
+ backgroundcolor="#000000">
+ <tileset firstgid="1" name="tiles" tilewidth="4" tileheight="4" tilecount="4" columns="4">
+  <tileoffset x="4" y="2"/>
+  <image source="tiles.png" width="16" height="4"/>
+ </tileset>
+ <layer id="1" name="tiles" width="2" height="2"><data encoding="csv">2,0,0,0</data></layer>
+ <objectgroup id="2" name="objects"><object id="1" gid="4" x="0" y="8" width="4" height="4"/></objectgroup>
+</map>`)
+
+	// Tiles and tile objects are both moved by the offset of their tileset.
+	checkPixels(t, img, []pixel{
+		{0, 0, black}, {3, 3, black}, {4, 1, black},
+		{4, 2, yellow}, {7, 5, yellow},
+		{0, 6, black}, {4, 6, cyan}, {7, 7, cyan},
+	})
+}
+
+func TestRenderImageTrans(t *testing.T) {
+	img := renderMap(t, orthogonalMap(1, 1, `backgroundcolor="#0000ff"`, `<imagelayer id="1" name="image">
+  <image source="layer.png" trans="ff00ff" width="4" height="4"/>
+ </imagelayer>`))
+
+	// The magenta half of the image is cleared by its transparent color.
+	checkPixels(t, img, []pixel{{0, 0, blue}, {1, 3, blue}, {2, 0, white}, {3, 3, white}})
+}
+
+func TestClearColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0xff, 0, 0xff, 0xff})
+	img.SetNRGBA(1, 0, color.NRGBA{0xff, 0, 0xfe, 0xff})
+	img.SetNRGBA(2, 0, color.NRGBA{0xff, 0, 0xff, 0x80})
+
+	// The alpha of the key and of the pixels is ignored.
+	clearColor(img, color.NRGBA{0xff, 0, 0xff, 0})
+
+	want := []uint8{0, 0xff, 0}
+	for x, alpha := range want {
+		if got := img.NRGBAAt(x, 0).A; got != alpha {
+			t.Errorf("pixel %d has alpha %d, want %d", x, got, alpha)
+		}
+	}
+}
+
+func TestRenderBackgroundColor(t *testing.T) {
+	tests := []struct {
+		attributes string
+		color      color.RGBA
+	}{
+		{``, none},
+		{`backgroundcolor="#00ff00"`, green},
+		// Translucent background colors are stored premultiplied.
+		{`backgroundcolor="#80ff0000"`, color.RGBA{0x80, 0, 0, 0x80}},
+	}
+
+	for _, test := range tests {
+		img := renderMap(t, orthogonalMap(2, 1, test.attributes, ""))
+		checkPixels(t, img, []pixel{{0, 0, test.color}, {7, 3, test.color}})
+	}
+
+	_, err := NewRenderer(loadRenderMap(t, orthogonalMap(1, 1, `backgroundcolor="#00ff0"`, ""))).Render()
+	if err == nil {
+		t.Error("rendering a map with a broken background color did not return an error")
+	}
+}
+
+func TestRenderIsometric(t *testing.T) {
+	m := loadRenderMap(t, `<map version="1.10" orientation="isometric" width="2" height="2" tilewidth="8" tileheight="4">
+ <tileset firstgid="1" name="iso" tilewidth="8" tileheight="4" tilecount="4" columns="4">
+  <image source="iso.png" width="32" height="4"/>
+ </tileset>
+ <layer id="1" name="tiles" width="2" height="2"><data encoding="csv">1,2,3,4</data></layer>
+ <objectgroup id="2" name="objects"><object id="1" gid="4" x="4" y="4" width="4" height="2"/></objectgroup>
+</map>`)
+
+	bounds := []image.Rectangle{
+		image.Rect(4, 0, 12, 4),
+		image.Rect(8, 2, 16, 6),
+		image.Rect(0, 2, 8, 6),
+		image.Rect(4, 4, 12, 8),
+	}
+	for i, want := range bounds {
+		if got := cellBounds(m.Map, i%2, i/2); got != want {
+			t.Errorf("cell %d,%d has bounds %v, want %v", i%2, i/2, got, want)
+		}
+	}
+
+	img, err := NewRenderer(m).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 16, 8) {
+		t.Fatalf("image bounds %v, want the map size", img.Bounds())
+	}
+
+	// Cells are drawn from the top of the screen down, so the top center of every cell shows its own tile.
+	checkPixels(t, img, []pixel{{8, 0, red}, {12, 2, green}, {4, 2, blue}, {8, 4, yellow}, {15, 4, green}})
+
+	// The tile object at the bottom corner of the first cell is aligned by its bottom center.
+	checkPixels(t, img, []pixel{{5, 2, blue}, {6, 2, yellow}, {9, 3, yellow}, {10, 2, green}})
+}
+
+func TestRenderHexagonal(t *testing.T) {
+	m := loadRenderMap(t, `<map version="1.10" orientation="hexagonal" width="2" height="2" tilewidth="8" tileheight="4"
+ hexsidelength="2" staggeraxis="y" staggerindex="odd">
+ <tileset firstgid="1" name="iso" tilewidth="8" tileheight="4" tilecount="4" columns="4">
+  <image source="iso.png" width="32" height="4"/>
+ </tileset>
+ <layer id="1" name="tiles" width="2" height="2"><data encoding="csv">1,2,3,4</data></layer>
+</map>`)
+
+	// Odd rows are shifted right by half a tile, rows overlap by the height of the slanted sides.
+	bounds := []image.Rectangle{
+		image.Rect(0, 0, 8, 4),
+		image.Rect(8, 0, 16, 4),
+		image.Rect(4, 3, 12, 7),
+		image.Rect(12, 3, 20, 7),
+	}
+	for i, want := range bounds {
+		if got := cellBounds(m.Map, i%2, i/2); got != want {
+			t.Errorf("cell %d,%d has bounds %v, want %v", i%2, i/2, got, want)
+		}
+	}
+
+	img, err := NewRenderer(m).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 20, 7) {
+		t.Fatalf("image bounds %v, want the map size", img.Bounds())
+	}
+
+	checkPixels(t, img, []pixel{
+		{0, 0, red}, {3, 3, red}, {7, 2, red}, {8, 2, green}, {15, 2, green},
+		{4, 3, blue}, {11, 6, blue}, {12, 3, yellow}, {19, 6, yellow}, {0, 6, none},
+	})
+}
+
+func TestRenderInfinite(t *testing.T) {
+	m := loadRenderMap(t, orthogonalMap(1, 1, `infinite="1"`, `<group id="1" name="group">
+  <layer id="2" name="tiles" width="4" height="2">
+   <data encoding="csv">
+    <chunk x="-2" y="0" width="2" height="1">2,0</chunk>
+    <chunk x="0" y="0" width="2" height="1">0,4</chunk>
+   </data>
+  </layer>
+ </group>
+ <layer id="3" name="empty" width="4" height="2">
+  <data encoding="csv"><chunk x="4" y="0" width="2" height="1">0,0</chunk></data>
+ </layer>`))
+
+	tiles, ok := contentTileBounds(m.Map.Content)
+	if !ok || tiles != image.Rect(-2, 0, 2, 1) {
+		t.Errorf("content tile bounds %v, %t, want the chunks", tiles, ok)
+	}
+	if _, ok := contentTileBounds(nil); ok {
+		t.Error("content without tile layers has tile bounds")
+	}
+
+	img, err := NewRenderer(m).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 16, 4) {
+		t.Fatalf("image bounds %v, want the bounds of the chunks", img.Bounds())
+	}
+
+	// The top-left chunk is drawn at 0, 0.
+	checkPixels(t, img, []pixel{{0, 0, yellow}, {3, 3, yellow}, {4, 0, none}, {11, 3, none}, {12, 0, cyan}})
+}
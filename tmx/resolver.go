@@ -0,0 +1,97 @@
+package tmx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Resolver is not listed in the spec, it is used by the loader to find and open every file referenced by a map:
+// external tilesets, object templates, images and file properties.
+type Resolver interface {
+	// Resolve returns the path of source, as referenced from the file at base. Base is empty when the referencing
+	// file has no path, for example when loading a map from bytes.
+	Resolve(base string, source string) string
+
+	// Open opens a path returned by Resolve for reading.
+	Open(name string) (io.ReadCloser, error)
+}
+
+// OSResolver resolves and opens files on the OS file system, relative sources are resolved against the directory of
+// the referencing file. It is the default Resolver.
+type OSResolver struct{}
+
+// Resolve returns source joined to the directory of base, absolute sources are returned as they are.
+func (OSResolver) Resolve(base string, source string) string {
+	if source == "" || filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(filepath.Dir(base), filepath.FromSlash(source))
+}
+
+// Open opens the named file.
+func (OSResolver) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+// FSResolver resolves and opens files within a fs.FS, such as an embed.FS or a zip.Reader. Paths are slash separated
+// and relative to the root of the file system.
+type FSResolver struct {
+	FS fs.FS
+}
+
+// Resolve returns source joined to the directory of base.
+func (r FSResolver) Resolve(base string, source string) string {
+	return resolveSlash(base, source)
+}
+
+// Open opens the named file.
+func (r FSResolver) Open(name string) (io.ReadCloser, error) {
+	return r.FS.Open(name)
+}
+
+// MapResolver resolves and opens files held in memory, keyed by slash separated path. It is useful in tests.
+type MapResolver map[string][]byte
+
+// Resolve returns source joined to the directory of base.
+func (r MapResolver) Resolve(base string, source string) string {
+	return resolveSlash(base, source)
+}
+
+// Open opens the named file.
+func (r MapResolver) Open(name string) (io.ReadCloser, error) {
+	data, ok := r[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+// resolveSlash joins a slash separated source to the directory of base.
+func resolveSlash(base string, source string) string {
+	if source == "" {
+		return source
+	}
+	return path.Join(path.Dir(base), source)
+}
+
+// readFile opens and reads a resolved path with a Resolver.
+func readFile(r Resolver, name string) ([]byte, error) {
+	rc, err := r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	return data, nil
+}
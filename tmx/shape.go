@@ -0,0 +1,35 @@
+package tmx
+
+// ShapeKind is the kind of shape an object has, every object has exactly one.
+type ShapeKind int
+
+// ShapeKind constants
+const (
+	ShapeRectangle ShapeKind = iota
+	ShapeEllipse
+	ShapePoint
+	ShapePolygon
+	ShapePolyline
+	ShapeText
+	ShapeTile
+)
+
+func (k ShapeKind) String() string {
+	switch k {
+	case ShapeRectangle:
+		return "rectangle"
+	case ShapeEllipse:
+		return "ellipse"
+	case ShapePoint:
+		return "point"
+	case ShapePolygon:
+		return "polygon"
+	case ShapePolyline:
+		return "polyline"
+	case ShapeText:
+		return "text"
+	case ShapeTile:
+		return "tile"
+	}
+	return "unknown"
+}
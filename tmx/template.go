@@ -0,0 +1,51 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Template structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#template-files
+type Template struct {
+	XMLName xml.Name `xml:"template"`
+
+	// Templates are saved in their own file, and are referenced by objects that are template instances.
+
+	// Can contain: <tileset>, <object>
+	Tileset *Tileset `xml:"tileset"`
+	Object  *Object  `xml:"object"`
+
+	// The path of the template file, Object.Template resolved relative to the map file. Not part of the spec, it is
+	// set when the map is loaded.
+	Path string `xml:"-"`
+
+	// The tileset of the template within the GID space of the map, either a tileset of the map with the same source
+	// or a copy of Tileset with a FirstGID following the tilesets of the map.
+	mapTileset *Tileset
+}
+
+// mapGID converts a global tile ID of the template into the GID space of the map.
+func (t *Template) mapGID(gid GID) GID {
+	if gid.ID() == 0 || t.Tileset == nil || t.mapTileset == nil {
+		return gid
+	}
+	return GID(t.mapTileset.FirstGID+int(gid.ID())-t.Tileset.FirstGID) | gid.Flags()
+}
+
+func (t *Template) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Template:\n")
+	fmt.Fprintf(&b, "\tPath: (%T) %q\n", t.Path, t.Path)
+
+	if t.Tileset != nil {
+		fmt.Fprintf(&b, t.Tileset.String())
+	}
+
+	if t.Object != nil {
+		fmt.Fprintf(&b, t.Object.String())
+	}
+
+	return b.String()
+}
@@ -17,7 +17,7 @@ type Terrain struct {
 	Tile int `xml:"tile,attr"`
 
 	// Can contain: <properties>
-	Properties []*Property `xml:"properties>property"`
+	Properties PropertySet `xml:"properties>property"`
 }
 
 func (t *Terrain) String() string {
@@ -0,0 +1,130 @@
+package tmx
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+)
+
+// FillTerrain picks a tile of the tileset for every cell of a grid, matching the terrain types of the four corners of
+// the cell, the way the terrain brush of Tiled did before Wang sets replaced it. The colors of the corners of the grid
+// are terrain types plus one, the colors of the Wang set made by TerrainWangset, and 0 allows any terrain. Tiles are
+// picked from that Wang set by Wangset.Autotile, weighted by Tile.Probability.
+//
+// The global tile IDs are returned row by row, Width * Height in length, ready to be used as Layer.GID. The tileset
+// must be used by a map, so its FirstGID is set. Cells get 0 when the tileset has no terrain tiles.
+func (t *Tileset) FillTerrain(g *WangGrid, rnd *rand.Rand) []GID {
+	w := t.TerrainWangset()
+	if w == nil {
+		return make([]GID, g.Width*g.Height)
+	}
+	return w.Autotile(t, g, rnd)
+}
+
+// terrainGrid returns the terrain types plus one of the corners of a rectangle of cells of a tile layer, read from the
+// tiles of the tileset in the cells of the rectangle and around it. Flipped tiles are read the way they are shown. A
+// corner shared by tiles that do not agree takes the terrain of the first tile, row by row. Positions in the grid are
+// relative to the rectangle.
+func (t *Tileset) terrainGrid(layer *Layer, r image.Rectangle) *WangGrid {
+	g := NewWangGrid(r.Dx(), r.Dy())
+
+	read := r.Inset(-1).Intersect(image.Rect(0, 0, layer.Width, layer.Height))
+	for y := read.Min.Y; y < read.Max.Y; y++ {
+		for x := read.Min.X; x < read.Max.X; x++ {
+			corners, ok := t.layerTerrain(layer, x, y)
+			if !ok {
+				continue
+			}
+
+			for c, corner := range corners {
+				cx, cy := x+c%2-r.Min.X, y+c/2-r.Min.Y
+				if corner >= 0 && g.Corner(cx, cy) == 0 {
+					g.SetCorner(cx, cy, corner+1)
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+// layerTerrain returns the terrain corners of the tile at a position of a layer, false when the cell is not a tile of
+// the tileset with terrain.
+func (t *Tileset) layerTerrain(layer *Layer, x int, y int) (TerrainCorners, bool) {
+	i := y*layer.Width + x
+	if i >= len(layer.GID) {
+		return TerrainCorners{}, false
+	}
+
+	gid := layer.GID[i]
+	id := int(gid.ID()) - t.FirstGID
+	if gid.ID() == 0 || id < 0 || id >= t.tileIDCount() {
+		return TerrainCorners{}, false
+	}
+
+	tile := t.TileByID(id)
+	if tile == nil {
+		return TerrainCorners{}, false
+	}
+
+	corners, err := tile.TerrainCorners()
+	if err != nil || corners.Empty() {
+		return TerrainCorners{}, false
+	}
+
+	return corners.flip(gid), true
+}
+
+// PaintTerrain paints a terrain type over a rectangle of cells of a tile layer, relative to the layer, the way the
+// terrain brush of Tiled does. Every corner of the rectangle gets the terrain, and the tiles of the rectangle and of
+// the cells around it are replaced by the tiles of the tileset that match their corners, so the painted terrain
+// blends into the terrain around it. Cells around the rectangle are only replaced when they are empty or hold a
+// terrain tile of the tileset. Tiles are picked the same way as FillTerrain.
+//
+// Only the layers of fixed size maps can be painted. Call EncodeData before saving the layer.
+func (t *Tileset) PaintTerrain(layer *Layer, r image.Rectangle, terrain int, rnd *rand.Rand) error {
+	if layer.Data != nil && len(layer.Data.Chunk) > 0 {
+		return fmt.Errorf("error painting terrain: layer %q is stored in chunks", layer.Name)
+	}
+	if t.TerrainTypes == nil || terrain < 0 || terrain >= len(t.TerrainTypes.Terrain) {
+		return fmt.Errorf("error painting terrain: tileset %q has no terrain %d", t.Name, terrain)
+	}
+
+	for len(layer.GID) < layer.Width*layer.Height {
+		layer.GID = append(layer.GID, 0)
+	}
+
+	bounds := image.Rect(0, 0, layer.Width, layer.Height)
+	painted := r.Intersect(bounds)
+	if painted.Empty() {
+		return nil
+	}
+
+	around := painted.Inset(-1).Intersect(bounds)
+	g := t.terrainGrid(layer, around)
+	for y := painted.Min.Y; y <= painted.Max.Y; y++ {
+		for x := painted.Min.X; x <= painted.Max.X; x++ {
+			g.SetCorner(x-around.Min.X, y-around.Min.Y, terrain+1)
+		}
+	}
+
+	gids := t.FillTerrain(g, rnd)
+	for y := around.Min.Y; y < around.Max.Y; y++ {
+		for x := around.Min.X; x < around.Max.X; x++ {
+			i := y*layer.Width + x
+
+			if !image.Pt(x, y).In(painted) && layer.GID[i] != 0 {
+				if _, ok := t.layerTerrain(layer, x, y); !ok {
+					continue
+				}
+			}
+
+			gid := gids[(y-around.Min.Y)*g.Width+x-around.Min.X]
+			if gid != 0 {
+				layer.GID[i] = gid
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"image"
+	"testing"
+)
+
+// terrainTileset has a grass terrain and a water terrain, a tile of each and the eight tiles around a water tile in
+// grass, from the top-left to the bottom-right.
+const terrainTileset = `<tileset firstgid="1" name="terrain" tilewidth="16" tileheight="16" tilecount="10" columns="10">
+ <image source="terrain.png" width="160" height="16"/>
+ <terraintypes>
+  <terrain name="grass" tile="0"/>
+  <terrain name="water" tile="1"/>
+ </terraintypes>
+ <tile id="0" terrain="0,0,0,0"/>
+ <tile id="1" terrain="1,1,1,1"/>
+ <tile id="2" terrain="0,0,0,1"/>
+ <tile id="3" terrain="0,0,1,1"/>
+ <tile id="4" terrain="0,0,1,0"/>
+ <tile id="5" terrain="0,1,0,1"/>
+ <tile id="6" terrain="1,0,1,0"/>
+ <tile id="7" terrain="0,1,0,0"/>
+ <tile id="8" terrain="1,1,0,0"/>
+ <tile id="9" terrain="1,0,0,0"/>
+</tileset>`
+
+// loadTerrainTileset unmarshals terrainTileset.
+func loadTerrainTileset(t *testing.T) *Tileset {
+	t.Helper()
+
+	tileset := &Tileset{}
+	err := xml.Unmarshal([]byte(terrainTileset), tileset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tileset
+}
+
+func TestParseTerrainCorners(t *testing.T) {
+	tests := []struct {
+		s       string
+		corners TerrainCorners
+		err     bool
+	}{
+		{"0,0,,1", TerrainCorners{0, 0, -1, 1}, false},
+		{",,,", TerrainCorners{-1, -1, -1, -1}, false},
+		{"", TerrainCorners{-1, -1, -1, -1}, false},
+		{"2, 3 ,4,5", TerrainCorners{2, 3, 4, 5}, false},
+		{"0,0,1", TerrainCorners{}, true},
+		{"0,a,1,1", TerrainCorners{}, true},
+	}
+
+	for _, test := range tests {
+		corners, err := ParseTerrainCorners(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("ParseTerrainCorners(%q) error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if !test.err && corners != test.corners {
+			t.Errorf("ParseTerrainCorners(%q) = %v, want %v", test.s, corners, test.corners)
+		}
+	}
+}
+
+func TestTerrainWangset(t *testing.T) {
+	tileset := loadTerrainTileset(t)
+
+	w := tileset.TerrainWangset()
+	if w == nil {
+		t.Fatal("TerrainWangset returned nil")
+	}
+	if w.Type != WangsetCorner {
+		t.Errorf("type %q, want %q", w.Type, WangsetCorner)
+	}
+	if len(w.WangColor) != 2 || w.WangColor[0].Name != "grass" || w.WangColor[1].Tile != 1 {
+		t.Errorf("colors %v, want grass and water", w.WangColor)
+	}
+	if len(w.WangTile) != 10 {
+		t.Fatalf("found %d wang tiles, want 10", len(w.WangTile))
+	}
+
+	// Tile 2 is grass with water at the bottom-right, colors are the terrain plus one.
+	if got, want := w.WangTile[2].WangID, (WangID{0, 1, 0, 2, 0, 1, 0, 1}); got != want {
+		t.Errorf("wang id %v, want %v", got, want)
+	}
+
+	if (&Tileset{}).TerrainWangset() != nil {
+		t.Error("TerrainWangset of a tileset without terrain types is not nil")
+	}
+}
+
+func TestFillTerrain(t *testing.T) {
+	tileset := loadTerrainTileset(t)
+
+	// Water on the bottom-right corner of the first cell, grass on every other corner.
+	g := NewWangGrid(2, 1)
+	for x := 0; x <= 2; x++ {
+		for y := 0; y <= 1; y++ {
+			g.SetCorner(x, y, 1)
+		}
+	}
+	g.SetCorner(1, 1, 2)
+
+	gids := tileset.FillTerrain(g, nil)
+	if want := []GID{3, 5}; len(gids) != 2 || gids[0] != want[0] || gids[1] != want[1] {
+		t.Errorf("FillTerrain = %v, want %v", gids, want)
+	}
+
+	// Corners that allow any terrain match every tile, the most likely one is picked.
+	gids = tileset.FillTerrain(NewWangGrid(1, 1), nil)
+	if len(gids) != 1 || gids[0] == 0 {
+		t.Errorf("FillTerrain of an empty grid = %v, want a tile", gids)
+	}
+}
+
+func TestPaintTerrain(t *testing.T) {
+	tileset := loadTerrainTileset(t)
+	layer := &Layer{Name: "ground", Width: 3, Height: 3, GID: []GID{1, 1, 1, 1, 1, 1, 1, 1, 1}}
+
+	err := tileset.PaintTerrain(layer, image.Rect(1, 1, 2, 2), 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []GID{
+		3, 4, 5,
+		6, 2, 7,
+		8, 9, 10,
+	}
+	for i := range want {
+		if layer.GID[i] != want[i] {
+			t.Fatalf("layer tiles %v, want %v", layer.GID, want)
+		}
+	}
+
+	// Painting grass back restores the grass tiles.
+	err = tileset.PaintTerrain(layer, image.Rect(1, 1, 2, 2), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, gid := range layer.GID {
+		if gid != 1 {
+			t.Fatalf("tile %d is %d after painting grass, want 1", i, gid)
+		}
+	}
+}
+
+func TestPaintTerrainKeepsOtherTiles(t *testing.T) {
+	tileset := loadTerrainTileset(t)
+
+	// The tile at the top-left is not part of the tileset, it is left alone.
+	layer := &Layer{Name: "ground", Width: 3, Height: 3, GID: []GID{99, 1, 1, 1, 1, 1, 1, 1, 1}}
+
+	err := tileset.PaintTerrain(layer, image.Rect(1, 1, 2, 2), 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer.GID[0] != 99 || layer.GID[4] != 2 {
+		t.Errorf("layer tiles %v, want 99 kept and water in the center", layer.GID)
+	}
+}
+
+func TestPaintTerrainErrors(t *testing.T) {
+	tileset := loadTerrainTileset(t)
+
+	layer := &Layer{Name: "ground", Width: 2, Height: 2}
+	if err := tileset.PaintTerrain(layer, image.Rect(0, 0, 1, 1), 2, nil); err == nil {
+		t.Error("painting a terrain the tileset does not have did not return an error")
+	}
+
+	layer.Data = &Data{Chunk: []*Chunk{{Width: 2, Height: 2}}}
+	if err := tileset.PaintTerrain(layer, image.Rect(0, 0, 1, 1), 0, nil); err == nil {
+		t.Error("painting a layer stored in chunks did not return an error")
+	}
+}
@@ -0,0 +1,103 @@
+package tmx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TerrainCorners indexes
+const (
+	TerrainTopLeft     int = 0
+	TerrainTopRight    int = 1
+	TerrainBottomLeft  int = 2
+	TerrainBottomRight int = 3
+)
+
+// TerrainCorners are the terrain types of the four corners of a tile, as indexes in TerrainTypes.Terrain in the order
+// of the TerrainCorners indexes. A corner without terrain is -1.
+type TerrainCorners [4]int
+
+// ParseTerrainCorners parses the terrain attribute of a tile, such as "0,0,,1". An empty string is a tile without
+// terrain, every corner is -1.
+func ParseTerrainCorners(s string) (TerrainCorners, error) {
+	c := TerrainCorners{-1, -1, -1, -1}
+
+	if s == "" {
+		return c, nil
+	}
+
+	corners := strings.Split(s, ",")
+	if len(corners) != len(c) {
+		return c, fmt.Errorf("error parsing terrain %q: found %d corners, not %d", s, len(corners), len(c))
+	}
+
+	for i, corner := range corners {
+		corner = strings.TrimSpace(corner)
+		if corner == "" {
+			continue
+		}
+
+		v, err := strconv.Atoi(corner)
+		if err != nil {
+			return c, fmt.Errorf("error parsing terrain %q: %w", s, err)
+		}
+		if v >= 0 {
+			c[i] = v
+		}
+	}
+
+	return c, nil
+}
+
+// TerrainCorners returns the parsed terrain types of the corners of the tile.
+func (t *Tile) TerrainCorners() (TerrainCorners, error) {
+	return ParseTerrainCorners(t.Terrain)
+}
+
+// SetTerrainCorners sets the terrain attribute of the tile from the terrain types of its corners.
+func (t *Tile) SetTerrainCorners(c TerrainCorners) {
+	if c.Empty() {
+		t.Terrain = ""
+		return
+	}
+	t.Terrain = c.String()
+}
+
+// Empty reports whether no corner has a terrain.
+func (c TerrainCorners) Empty() bool {
+	for _, corner := range c {
+		if corner >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// flip returns the corners as they are shown by a tile drawn with the flip flags of a global tile ID. The diagonal
+// flip is applied first, then the horizontal and vertical flips, the same order tiles are drawn in.
+func (c TerrainCorners) flip(gid GID) TerrainCorners {
+	if gid.FlippedDiagonally() {
+		c[TerrainTopRight], c[TerrainBottomLeft] = c[TerrainBottomLeft], c[TerrainTopRight]
+	}
+	if gid.FlippedHorizontally() {
+		c[TerrainTopLeft], c[TerrainTopRight] = c[TerrainTopRight], c[TerrainTopLeft]
+		c[TerrainBottomLeft], c[TerrainBottomRight] = c[TerrainBottomRight], c[TerrainBottomLeft]
+	}
+	if gid.FlippedVertically() {
+		c[TerrainTopLeft], c[TerrainBottomLeft] = c[TerrainBottomLeft], c[TerrainTopLeft]
+		c[TerrainTopRight], c[TerrainBottomRight] = c[TerrainBottomRight], c[TerrainTopRight]
+	}
+	return c
+}
+
+// String formats the corners the way the terrain attribute saves them, corners without terrain are left out.
+func (c TerrainCorners) String() string {
+	corners := make([]string, len(c))
+	for i, corner := range c {
+		if corner >= 0 {
+			corners[i] = strconv.Itoa(corner)
+		}
+	}
+	return strings.Join(corners, ",")
+}
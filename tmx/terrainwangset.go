@@ -0,0 +1,54 @@
+package tmx
+
+// terrainColors are the colors given to the Wang colors converted from terrain types, which have no color of their
+// own. They are used in turn when there are more terrain types.
+var terrainColors = []string{
+	"#ff0000", "#00ff00", "#0000ff", "#ff7700", "#00e9ff", "#ff00d8", "#ffef00", "#6a00ff",
+	"#35ff00", "#006fff", "#ff0057", "#a900ff",
+}
+
+// TerrainWangset converts the terrain types of the tileset and the terrain of its tiles to a corner Wang set, the way
+// Tiled 1.5 converts terrains when it loads an older tileset. Each terrain type becomes a Wang color, with its name,
+// tile and properties, and each tile with terrain becomes a Wang tile with the same corners. It returns nil when the
+// tileset has no terrain types.
+//
+// The tileset is not changed, append the result to Wangsets to keep it.
+func (t *Tileset) TerrainWangset() *Wangset {
+	if t.TerrainTypes == nil || len(t.TerrainTypes.Terrain) == 0 {
+		return nil
+	}
+
+	w := &Wangset{
+		Name: "Terrains",
+		Type: WangsetCorner,
+		Tile: -1,
+	}
+
+	for i, terrain := range t.TerrainTypes.Terrain {
+		w.WangColor = append(w.WangColor, &WangColor{
+			Name:        terrain.Name,
+			Color:       terrainColors[i%len(terrainColors)],
+			Tile:        terrain.Tile,
+			Probability: 1,
+			Properties:  terrain.Properties,
+		})
+	}
+
+	for _, tile := range t.Tile {
+		corners, err := tile.TerrainCorners()
+		if err != nil || corners.Empty() {
+			continue
+		}
+
+		// Wang colors are the terrain index plus one, a corner without terrain has no color.
+		var id WangID
+		id[WangTopLeft] = corners[TerrainTopLeft] + 1
+		id[WangTopRight] = corners[TerrainTopRight] + 1
+		id[WangBottomLeft] = corners[TerrainBottomLeft] + 1
+		id[WangBottomRight] = corners[TerrainBottomRight] + 1
+
+		w.WangTile = append(w.WangTile, &WangTile{TileID: tile.ID, WangID: id})
+	}
+
+	return w
+}
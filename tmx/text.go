@@ -44,6 +44,9 @@ type Text struct {
 	// Vertical alignment of the text within the object (top (default), center or bottom)
 	VAlign string `xml:"valign,attr"`
 
+	// The actual text, stored as character data.
+	Value string `xml:",chardata"`
+
 	// Used to mark an object as a text object. Contains the actual text as character data.
 
 	// For alignment purposes, the bottom of the text is the descender height of the font, and the top of the text is
@@ -54,6 +57,48 @@ type Text struct {
 	// If the text is larger than the object’s bounds, it is clipped to the bounds of the object.
 }
 
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (t *Text) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type text Text
+
+	t.setDefaults()
+
+	return decoder.DecodeElement((*text)(t), &startElement)
+}
+
+// setDefaults sets the values used when an attribute is left out.
+func (t *Text) setDefaults() {
+	t.FontFamily = "sans-serif"
+	t.PixelSize = 16
+	t.Color = "#000000"
+	t.Kerning = true
+	t.HAlign = "left"
+	t.VAlign = "top"
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (t *Text) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	def := &Text{}
+	def.setDefaults()
+
+	var a attrs
+	a.str("fontfamily", t.FontFamily, def.FontFamily)
+	a.int("pixelsize", t.PixelSize, def.PixelSize)
+	a.bool("wrap", t.Wrap, def.Wrap)
+	a.str("color", t.Color, def.Color)
+	a.bool("bold", t.Bold, def.Bold)
+	a.bool("italic", t.Italic, def.Italic)
+	a.bool("underline", t.Underline, def.Underline)
+	a.bool("strikeout", t.Strikeout, def.Strikeout)
+	a.bool("kerning", t.Kerning, def.Kerning)
+	a.str("halign", t.HAlign, def.HAlign)
+	a.str("valign", t.VAlign, def.VAlign)
+
+	return encoder.EncodeElement(struct {
+		Value string `xml:",chardata"`
+	}{t.Value}, startElement("text", a))
+}
+
 func (t *Text) String() string {
 	var b strings.Builder
 
@@ -69,6 +114,7 @@ func (t *Text) String() string {
 	fmt.Fprintf(&b, "\tKerning:    (%T) %t\n", t.Kerning, t.Kerning)
 	fmt.Fprintf(&b, "\tHAlign:     (%T) %q\n", t.HAlign, t.HAlign)
 	fmt.Fprintf(&b, "\tVAlign:     (%T) %q\n", t.VAlign, t.VAlign)
+	fmt.Fprintf(&b, "\tValue:      (%T) %q\n", t.Value, t.Value)
 
 	return b.String()
 }
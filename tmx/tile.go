@@ -3,6 +3,7 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -26,12 +27,49 @@ type Tile struct {
 	Probability float32 `xml:"probability,attr"`
 
 	// Can contain: <properties>, <image> (since 0.9), <objectgroup>, <animation>
-	Properties  []*Property    `xml:"properties>property"`
+	Properties  PropertySet    `xml:"properties>property"`
 	Image       *Image         `xml:"image"`
 	ObjectGroup []*ObjectGroup `xml:"objectgroup"`
 	Animation   *Animation     `xml:"animation"`
 }
 
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (t *Tile) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type tile Tile
+
+	// Tiles compete equally unless the probability attribute says otherwise.
+	t.Probability = 1
+
+	return decoder.DecodeElement((*tile)(t), &startElement)
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (t *Tile) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.add("id", strconv.Itoa(t.ID))
+	a.str("type", t.Type, "")
+	a.str("terrain", t.Terrain, "")
+	a.float32("probability", t.Probability, 1)
+
+	start = startElement("tile", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encodeElements(encoder, []interface{}{
+		propertyList(t.Properties),
+		t.Image,
+		t.ObjectGroup,
+		t.Animation,
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
 func (t *Tile) String() string {
 	var b strings.Builder
 
@@ -0,0 +1,85 @@
+package tmx
+
+import (
+	"image"
+)
+
+// TileGrid gives access to the tiles of a tile layer by their position in tiles, the same way for the layers of fixed
+// size maps and for the chunks of infinite maps, so code reading tiles does not depend on Map.Infinite.
+type TileGrid interface {
+	// GID returns the global tile ID at a position in tiles, with its flip flags. Positions can be negative on
+	// infinite maps, and 0 is returned for empty cells and positions outside the grid.
+	GID(x int, y int) GID
+
+	// Bounds returns the rectangle in tiles that contains every tile of the grid.
+	Bounds() image.Rectangle
+
+	// EachTile calls fn with the position in tiles and global tile ID of every tile that is not empty, row by row from
+	// the top of the grid and from left to right within each row. Iteration stops at the first error returned by fn,
+	// which is returned.
+	EachTile(fn func(x int, y int, gid GID) error) error
+}
+
+// TileGrid returns the tiles of the layer, a *ChunkGrid when the layer is stored in chunks. The grid is built once by
+// IndexTiles, and reads the tiles of Layer.GID and Chunk.GID as they are, so tiles can be changed in place.
+func (l *Layer) TileGrid() TileGrid {
+	if l.grid == nil {
+		l.IndexTiles()
+	}
+	return l.grid
+}
+
+// IndexTiles builds the tile grid returned by TileGrid. It is called when the layer is loaded, call it again after
+// adding, removing or moving the chunks of the layer, or filling an empty chunk.
+func (l *Layer) IndexTiles() {
+	if l.Data != nil && len(l.Data.Chunk) > 0 {
+		l.grid = NewChunkGrid(l.Data.Chunk)
+		return
+	}
+	l.grid = layerGrid{l}
+}
+
+// layerGrid is the TileGrid of a layer of a fixed size map, backed by Layer.GID.
+type layerGrid struct {
+	layer *Layer
+}
+
+func (g layerGrid) GID(x int, y int) GID {
+	l := g.layer
+	x -= l.X
+	y -= l.Y
+	if x < 0 || y < 0 || x >= l.Width || y >= l.Height {
+		return 0
+	}
+
+	i := y*l.Width + x
+	if i >= len(l.GID) {
+		return 0
+	}
+
+	return l.GID[i]
+}
+
+func (g layerGrid) Bounds() image.Rectangle {
+	l := g.layer
+	return image.Rect(l.X, l.Y, l.X+l.Width, l.Y+l.Height)
+}
+
+func (g layerGrid) EachTile(fn func(x int, y int, gid GID) error) error {
+	l := g.layer
+	if l.Width <= 0 {
+		return nil
+	}
+
+	for i, gid := range l.GID {
+		if gid.ID() == 0 {
+			continue
+		}
+		err := fn(l.X+i%l.Width, l.Y+i/l.Width, gid)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package tmx
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// TileRef is not listed in the spec, it is the result of resolving a global tile ID to the tileset that owns it.
+type TileRef struct {
+	// The tileset with the highest firstgid that is lower or equal than the global tile ID.
+	Tileset *Tileset
+
+	// The local tile ID within Tileset.
+	ID int
+
+	// The tile element of the local tile ID, nil when the tileset has no metadata for this tile.
+	Tile *Tile
+
+	// The image the tile is cut from, either the tileset image or the tile image of an image collection tileset.
+	Image *Image
+
+	// The source rectangle of the tile within Image, in pixels.
+	Rect image.Rectangle
+}
+
+func (t *TileRef) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "TileRef:\n")
+	fmt.Fprintf(&b, "\tTileset: (%T) %q\n", t.Tileset.Name, t.Tileset.Name)
+	fmt.Fprintf(&b, "\tID:      (%T) %d\n", t.ID, t.ID)
+	fmt.Fprintf(&b, "\tRect:    (%T) %v\n", t.Rect, t.Rect)
+
+	return b.String()
+}
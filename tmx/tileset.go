@@ -3,6 +3,8 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"image"
+	"strconv"
 	"strings"
 )
 
@@ -19,6 +21,16 @@ type Tileset struct {
 	// specific.)
 	Source string `xml:"source,attr"`
 
+	// The path of the external TSX file, Source resolved relative to the map file. Not part of the spec, it is set
+	// when the map is loaded.
+	Path string `xml:"-"`
+
+	// The TMX format version, only set on tilesets stored in a TSX file.
+	Version string `xml:"version,attr,omitempty"`
+
+	// The Tiled version used to save the TSX file, only set on tilesets stored in a TSX file.
+	TiledVersion string `xml:"tiledversion,attr,omitempty"`
+
 	// The name of this tileset.
 	Name string `xml:"name,attr"`
 
@@ -49,11 +61,157 @@ type Tileset struct {
 	// (since 1.1)
 	TileOffset   *TileOffset   `xml:"tileoffset"`
 	Grid         *Grid         `xml:"grid"`
-	Properties   []*Property   `xml:"properties>property"`
+	Properties   PropertySet   `xml:"properties>property"`
 	Image        *Image        `xml:"image"`
 	TerrainTypes *TerrainTypes `xml:"terraintypes"`
 	Tile         []*Tile       `xml:"tile"`
 	Wangsets     *Wangsets     `xml:"wangsets"`
+
+	// Index of Tile by local tile ID, built when the tileset is unmarshalled.
+	tiles map[int]*Tile
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (t *Tileset) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type tileset Tileset
+
+	err := decoder.DecodeElement((*tileset)(t), &startElement)
+	if err != nil {
+		return err
+	}
+
+	t.indexTiles()
+
+	return nil
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+// A tileset with a Source is written as a reference to its external TSX file.
+func (t *Tileset) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	if t.IsExternal() {
+		var a attrs
+		a.int("firstgid", t.FirstGID, 0)
+		a.add("source", t.Source)
+		return encoder.EncodeElement(struct{}{}, startElement("tileset", a))
+	}
+
+	return t.encode(encoder, true)
+}
+
+// IsExternal reports whether the tileset of a map is stored in an external TSX file, referenced by Source.
+func (t *Tileset) IsExternal() bool {
+	return t.Source != ""
+}
+
+// encode encodes the tileset with all of its elements. The firstgid attribute is only written for tilesets of a map.
+func (t *Tileset) encode(encoder *xml.Encoder, firstGID bool) error {
+	var a attrs
+	if firstGID {
+		a.int("firstgid", t.FirstGID, 0)
+	}
+	a.str("version", t.Version, "")
+	a.str("tiledversion", t.TiledVersion, "")
+	a.add("name", t.Name)
+	a.add("tilewidth", strconv.Itoa(t.TileWidth))
+	a.add("tileheight", strconv.Itoa(t.TileHeight))
+	a.int("spacing", t.Spacing, 0)
+	a.int("margin", t.Margin, 0)
+	a.add("tilecount", strconv.Itoa(t.TileCount))
+	a.add("columns", strconv.Itoa(t.Columns))
+
+	start := startElement("tileset", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = t.encodeElements(encoder)
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
+// encodeElements encodes the child elements of the tileset.
+func (t *Tileset) encodeElements(encoder *xml.Encoder) error {
+	return encodeElements(encoder, []interface{}{
+		t.TileOffset,
+		t.Grid,
+		propertyList(t.Properties),
+		t.Image,
+		t.TerrainTypes,
+		t.Tile,
+		t.Wangsets,
+	})
+}
+
+// indexTiles builds the index of Tile by local tile ID.
+func (t *Tileset) indexTiles() {
+	t.tiles = make(map[int]*Tile, len(t.Tile))
+	for _, tile := range t.Tile {
+		t.tiles[tile.ID] = tile
+	}
+}
+
+// TileByID returns the Tile with the local tile ID, or nil when the tile has no metadata in this tileset.
+func (t *Tileset) TileByID(id int) *Tile {
+	if t.tiles == nil {
+		for _, tile := range t.Tile {
+			if tile.ID == id {
+				return tile
+			}
+		}
+		return nil
+	}
+	return t.tiles[id]
+}
+
+// tileIDCount returns the number of global tile IDs used by the tileset, image collection tilesets may have gaps in
+// their local tile IDs.
+func (t *Tileset) tileIDCount() int {
+	count := t.TileCount
+	for _, tile := range t.Tile {
+		if tile.ID >= count {
+			count = tile.ID + 1
+		}
+	}
+	return count
+}
+
+// TileColumns returns the number of tile columns in the tileset image. Older tilesets do not store Columns, in that
+// case it is calculated from the image width, Margin and Spacing.
+func (t *Tileset) TileColumns() int {
+	if t.Columns > 0 {
+		return t.Columns
+	}
+	if t.Image == nil || t.TileWidth+t.Spacing <= 0 {
+		return 0
+	}
+	return (t.Image.Width - 2*t.Margin + t.Spacing) / (t.TileWidth + t.Spacing)
+}
+
+// TileRect returns the source rectangle of the local tile ID and the image it refers to. For tilesets based on a
+// single image, the rectangle is calculated from Margin, Spacing and Columns. For image collection tilesets the
+// rectangle covers the whole image of the tile.
+func (t *Tileset) TileRect(id int) (image.Rectangle, *Image) {
+	if t.Image == nil {
+		tile := t.TileByID(id)
+		if tile == nil || tile.Image == nil {
+			return image.Rectangle{}, nil
+		}
+		return image.Rect(0, 0, tile.Image.Width, tile.Image.Height), tile.Image
+	}
+
+	columns := t.TileColumns()
+	if columns <= 0 {
+		return image.Rectangle{}, t.Image
+	}
+
+	x := t.Margin + (id%columns)*(t.TileWidth+t.Spacing)
+	y := t.Margin + (id/columns)*(t.TileHeight+t.Spacing)
+
+	return image.Rect(x, y, x+t.TileWidth, y+t.TileHeight), t.Image
 }
 
 func (t *Tileset) String() string {
@@ -62,6 +220,9 @@ func (t *Tileset) String() string {
 	fmt.Fprintf(&b, "Tileset:\n")
 	fmt.Fprintf(&b, "\tFirstGID:   (%T) %d\n", t.FirstGID, t.FirstGID)
 	fmt.Fprintf(&b, "\tSource:     (%T) %q\n", t.Source, t.Source)
+	fmt.Fprintf(&b, "\tPath:       (%T) %q\n", t.Path, t.Path)
+	fmt.Fprintf(&b, "\tVersion:    (%T) %q\n", t.Version, t.Version)
+	fmt.Fprintf(&b, "\tTiledVer:   (%T) %q\n", t.TiledVersion, t.TiledVersion)
 	fmt.Fprintf(&b, "\tName:       (%T) %q\n", t.Name, t.Name)
 	fmt.Fprintf(&b, "\tTileWidth:  (%T) %d\n", t.TileWidth, t.TileWidth)
 	fmt.Fprintf(&b, "\tTileHeight: (%T) %d\n", t.TileHeight, t.TileHeight)
@@ -0,0 +1,151 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"image"
+	"testing"
+)
+
+// collectionTileset is an image collection tileset with a gap in its tile IDs, as saved by Tiled.
+const collectionTileset = `<tileset name="props" tilewidth="48" tileheight="64" tilecount="2" columns="0">
+ <grid orientation="orthogonal" width="1" height="1"/>
+ <tile id="0">
+  <image source="props/barrel.png" width="32" height="40"/>
+ </tile>
+ <tile id="4" type="tree">
+  <properties>
+   <property name="shade" type="bool" value="true"/>
+  </properties>
+  <image source="props/tree.png" width="48" height="64"/>
+ </tile>
+</tileset>`
+
+// unmarshalTileset unmarshals the xml of a tileset.
+func unmarshalTileset(t *testing.T, data string) *Tileset {
+	t.Helper()
+
+	tileset := &Tileset{}
+	err := xml.Unmarshal([]byte(data), tileset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tileset
+}
+
+func TestTileColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		tileset string
+		columns int
+	}{
+		{"columns", `<tileset tilewidth="16" tileheight="16" columns="3"><image width="64" height="16"/></tileset>`, 3},
+		{"image width", `<tileset tilewidth="16" tileheight="16"><image width="64" height="16"/></tileset>`, 4},
+		// Two 16px tiles between margins of 2px and with a spacing of 1px fill 2+16+1+16+2 pixels.
+		{"margin and spacing", `<tileset tilewidth="16" tileheight="16" margin="2" spacing="1">
+ <image width="37" height="37"/>
+</tileset>`, 2},
+		{"partial tile", `<tileset tilewidth="16" tileheight="16" margin="2" spacing="1">
+ <image width="36" height="36"/>
+</tileset>`, 1},
+		{"image collection", collectionTileset, 0},
+	}
+
+	for _, test := range tests {
+		tileset := unmarshalTileset(t, test.tileset)
+		if columns := tileset.TileColumns(); columns != test.columns {
+			t.Errorf("%s: TileColumns = %d, want %d", test.name, columns, test.columns)
+		}
+	}
+}
+
+func TestTileRect(t *testing.T) {
+	tileset := unmarshalTileset(t, `<tileset tilewidth="16" tileheight="8" tilecount="6" margin="2" spacing="1">
+ <image source="tiles.png" width="55" height="21"/>
+</tileset>`)
+
+	tests := []struct {
+		id   int
+		rect image.Rectangle
+	}{
+		{0, image.Rect(2, 2, 18, 10)},
+		{1, image.Rect(19, 2, 35, 10)},
+		{2, image.Rect(36, 2, 52, 10)},
+		{4, image.Rect(19, 11, 35, 19)},
+	}
+
+	for _, test := range tests {
+		rect, img := tileset.TileRect(test.id)
+		if rect != test.rect || img != tileset.Image {
+			t.Errorf("TileRect(%d) = %v of %v, want %v of the tileset image", test.id, rect, img, test.rect)
+		}
+	}
+}
+
+func TestTileRectImageCollection(t *testing.T) {
+	tileset := unmarshalTileset(t, collectionTileset)
+
+	// Every tile is a whole image of its own, whatever the tile size of the tileset.
+	rect, img := tileset.TileRect(4)
+	if rect != image.Rect(0, 0, 48, 64) || img == nil || img.Source != "props/tree.png" {
+		t.Errorf("TileRect(4) = %v of %v, want the whole tree image", rect, img)
+	}
+	rect, img = tileset.TileRect(0)
+	if rect != image.Rect(0, 0, 32, 40) || img == nil || img.Source != "props/barrel.png" {
+		t.Errorf("TileRect(0) = %v of %v, want the whole barrel image", rect, img)
+	}
+
+	// The gap in the tile IDs has no image.
+	rect, img = tileset.TileRect(2)
+	if !rect.Empty() || img != nil {
+		t.Errorf("TileRect(2) = %v of %v, want no image", rect, img)
+	}
+}
+
+func TestResolveGID(t *testing.T) {
+	m := &Map{Content: []Content{
+		{Value: unmarshalTileset(t, `<tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" tilecount="4"
+ columns="2">
+ <image source="tiles.png" width="32" height="32"/>
+ <tile id="3" type="wall"/>
+</tileset>`)},
+		{Value: unmarshalTileset(t, `<tileset firstgid="5" name="props" tilewidth="48" tileheight="64"
+ tilecount="2" columns="0">
+ <tile id="0"><image source="props/barrel.png" width="32" height="40"/></tile>
+ <tile id="4"><image source="props/tree.png" width="48" height="64"/></tile>
+</tileset>`)},
+	}}
+	m.IndexTilesets()
+
+	tests := []struct {
+		gid     GID
+		tileset string
+		id      int
+		rect    image.Rectangle
+	}{
+		{1, "tiles", 0, image.Rect(0, 0, 16, 16)},
+		{4 | FlippedVerticallyFlag, "tiles", 3, image.Rect(16, 16, 32, 32)},
+		{9, "props", 4, image.Rect(0, 0, 48, 64)},
+	}
+	for _, test := range tests {
+		ref, err := m.ResolveGID(test.gid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.Tileset.Name != test.tileset || ref.ID != test.id || ref.Rect != test.rect {
+			t.Errorf("gid %d is tile %d of %q at %v, want tile %d of %q at %v", test.gid, ref.ID, ref.Tileset.Name,
+				ref.Rect, test.id, test.tileset, test.rect)
+		}
+	}
+
+	ref, err := m.ResolveGID(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Tile == nil || ref.Tile.Type != "wall" {
+		t.Errorf("gid 4 has tile %v, want the wall tile", ref.Tile)
+	}
+
+	if _, err := m.ResolveGID(0); err == nil {
+		t.Error("ResolveGID(0) did not return an error")
+	}
+}
@@ -0,0 +1,865 @@
+package tmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// LoadTMJ loads a map saved in the JSON format (.tmj or .json) into a TMX struct. External tilesets, templates and
+// images are resolved relative to the directory of the map file.
+func LoadTMJ(source string) (*TMX, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadTMJResolver(OSResolver{}, absSource)
+}
+
+// LoadTMJBytes loads a map saved in the JSON format into a TMX struct. External tilesets, templates and images are
+// resolved relative to the current working directory.
+func LoadTMJBytes(bytes []byte) (*TMX, error) {
+	l := newLoader(OSResolver{}, "")
+
+	return l.loadTMJ(bytes)
+}
+
+// LoadTMJFS loads a map saved in the JSON format from a file system, such as an embed.FS or a zip.Reader, into a TMX
+// struct. External tilesets, templates and images are resolved relative to the map within the same file system.
+func LoadTMJFS(fsys fs.FS, name string) (*TMX, error) {
+	return LoadTMJResolver(FSResolver{FS: fsys}, name)
+}
+
+// LoadTMJResolver loads a map saved in the JSON format opened with a Resolver into a TMX struct. External tilesets,
+// templates and images are resolved and opened with the same Resolver.
+func LoadTMJResolver(resolver Resolver, name string) (*TMX, error) {
+	tmjBytes, err := readFile(resolver, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tmj file: %w", err)
+	}
+
+	l := newLoader(resolver, name)
+
+	return l.loadTMJ(tmjBytes)
+}
+
+// loadTMJ unmarshals the json bytes and resolves the tilesets, templates and images referenced by the map.
+func (l *loader) loadTMJ(data []byte) (*TMX, error) {
+	jm := &jsonMap{CompressionLevel: -1}
+
+	err := json.Unmarshal(data, jm)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling tmj bytes: %w", err)
+	}
+
+	m, err := jm.tmxMap()
+	if err != nil {
+		return nil, fmt.Errorf("error converting tmj map: %w", err)
+	}
+
+	err = l.loadMap(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TMX{Map: m, Resolver: l.resolver}, nil
+}
+
+// jsonMap structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#map
+type jsonMap struct {
+	Version          jsonVersion    `json:"version"`
+	TiledVersion     string         `json:"tiledversion"`
+	Orientation      string         `json:"orientation"`
+	RenderOrder      string         `json:"renderorder"`
+	CompressionLevel int            `json:"compressionlevel"`
+	Width            int            `json:"width"`
+	Height           int            `json:"height"`
+	TileWidth        int            `json:"tilewidth"`
+	TileHeight       int            `json:"tileheight"`
+	HexSideLength    int            `json:"hexsidelength"`
+	StaggerAxis      string         `json:"staggeraxis"`
+	StaggerIndex     string         `json:"staggerindex"`
+	BackgroundColor  string         `json:"backgroundcolor"`
+	Infinite         bool           `json:"infinite"`
+	NextLayerID      int            `json:"nextlayerid"`
+	NextObjectID     int            `json:"nextobjectid"`
+	Properties       jsonProperties `json:"properties"`
+	Tilesets         []*jsonTileset `json:"tilesets"`
+	Layers           []*jsonLayer   `json:"layers"`
+}
+
+// tmxMap converts the json map to a Map, in the same order the TMX format stores its elements.
+func (j *jsonMap) tmxMap() (*Map, error) {
+	m := &Map{
+		Version:          string(j.Version),
+		TiledVersion:     j.TiledVersion,
+		Orientation:      j.Orientation,
+		RenderOrder:      j.RenderOrder,
+		CompressionLevel: j.CompressionLevel,
+		Width:            j.Width,
+		Height:           j.Height,
+		TileWidth:        j.TileWidth,
+		TileHeight:       j.TileHeight,
+		HexSideLength:    j.HexSideLength,
+		StaggerAxis:      j.StaggerAxis,
+		StaggerIndex:     j.StaggerIndex,
+		BackgroundColor:  j.BackgroundColor,
+		NextLayerID:      j.NextLayerID,
+		NextObjectID:     j.NextObjectID,
+	}
+	if j.Infinite {
+		m.Infinite = 1
+	}
+
+	m.Properties = j.Properties.properties()
+
+	for _, jt := range j.Tilesets {
+		tileset, err := jt.tileset()
+		if err != nil {
+			return nil, err
+		}
+		m.Content = append(m.Content, Content{Type: "tileset", Value: tileset})
+	}
+
+	layers, err := jsonLayerContent(j.Layers)
+	if err != nil {
+		return nil, err
+	}
+	m.Content = append(m.Content, layers...)
+
+	m.IndexTilesets()
+
+	return m, nil
+}
+
+// jsonLayer structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#layer
+type jsonLayer struct {
+	Type             string         `json:"type"`
+	ID               int            `json:"id"`
+	Name             string         `json:"name"`
+	X                int            `json:"x"`
+	Y                int            `json:"y"`
+	Width            int            `json:"width"`
+	Height           int            `json:"height"`
+	Opacity          float32        `json:"opacity"`
+	Visible          bool           `json:"visible"`
+	Locked           bool           `json:"locked"`
+	OffsetX          float32        `json:"offsetx"`
+	OffsetY          float32        `json:"offsety"`
+	Color            string         `json:"color"`
+	DrawOrder        string         `json:"draworder"`
+	Encoding         string         `json:"encoding"`
+	Compression      string         `json:"compression"`
+	Data             jsonData       `json:"data"`
+	Chunks           []*jsonChunk   `json:"chunks"`
+	Image            string         `json:"image"`
+	ImageWidth       int            `json:"imagewidth"`
+	ImageHeight      int            `json:"imageheight"`
+	TransparentColor string         `json:"transparentcolor"`
+	Objects          []*jsonObject  `json:"objects"`
+	Layers           []*jsonLayer   `json:"layers"`
+	Properties       jsonProperties `json:"properties"`
+}
+
+// UnmarshalJSON is called by Unmarshal to produce the value from the json object.
+func (j *jsonLayer) UnmarshalJSON(data []byte) error {
+	type layer jsonLayer
+
+	// Layers are opaque and visible unless the values say otherwise.
+	j.Opacity = 1
+	j.Visible = true
+
+	return json.Unmarshal(data, (*layer)(j))
+}
+
+// jsonLayerContent converts json layers to Map.Content or Group.Content.
+func jsonLayerContent(layers []*jsonLayer) ([]Content, error) {
+	content := make([]Content, 0, len(layers))
+	for _, jl := range layers {
+		c, err := jl.content()
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, c)
+	}
+	return content, nil
+}
+
+// content converts the json layer to a Layer, ObjectGroup, ImageLayer or Group.
+func (j *jsonLayer) content() (Content, error) {
+	switch j.Type {
+	case "tilelayer":
+		layer, err := j.layer()
+		if err != nil {
+			return Content{}, err
+		}
+		return Content{Type: "layer", Value: layer}, nil
+	case "objectgroup":
+		return Content{Type: "objectgroup", Value: j.objectGroup()}, nil
+	case "imagelayer":
+		return Content{Type: "imagelayer", Value: j.imageLayer()}, nil
+	case "group":
+		group, err := j.group()
+		if err != nil {
+			return Content{}, err
+		}
+		return Content{Type: "group", Value: group}, nil
+	}
+	return Content{}, fmt.Errorf("unknown layer type: %q", j.Type)
+}
+
+// layer converts the json layer to a Layer and decodes its tile data.
+func (j *jsonLayer) layer() (*Layer, error) {
+	l := &Layer{
+		ID:         j.ID,
+		Name:       j.Name,
+		X:          j.X,
+		Y:          j.Y,
+		Width:      j.Width,
+		Height:     j.Height,
+		Opacity:    j.Opacity,
+		Visible:    j.Visible,
+		OffsetX:    j.OffsetX,
+		OffsetY:    j.OffsetY,
+		Properties: j.Properties.properties(),
+		Data:       &Data{Encoding: j.Encoding, Compression: j.Compression},
+	}
+
+	// Tile data saved as an array of numbers is stored as csv, the way the TMX format stores it.
+	if j.Encoding == "" || j.Encoding == EncodingCSV {
+		l.Data.Encoding = EncodingCSV
+	}
+
+	if len(j.Chunks) == 0 {
+		gids, err := j.Data.gids(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding layer %q: %w", l.Name, err)
+		}
+		if len(gids) != l.Width*l.Height {
+			return nil, fmt.Errorf("error decoding layer %q: expected %d tiles, found %d", l.Name, l.Width*l.Height, len(gids))
+		}
+		l.GID = gids
+		l.Data.InnerXML = j.Data.innerXML(l.Data, l.Width)
+		l.IndexTiles()
+		return l, nil
+	}
+
+	for _, jc := range j.Chunks {
+		chunk := &Chunk{X: jc.X, Y: jc.Y, Width: jc.Width, Height: jc.Height}
+
+		gids, err := jc.Data.gids(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding layer %q chunk %d,%d: %w", l.Name, chunk.X, chunk.Y, err)
+		}
+		if len(gids) != chunk.Width*chunk.Height {
+			return nil, fmt.Errorf("error decoding layer %q chunk %d,%d: expected %d tiles, found %d", l.Name,
+				chunk.X, chunk.Y, chunk.Width*chunk.Height, len(gids))
+		}
+		chunk.GID = gids
+		chunk.InnerXML = jc.Data.innerXML(l.Data, chunk.Width)
+
+		l.Data.Chunk = append(l.Data.Chunk, chunk)
+	}
+	l.IndexTiles()
+
+	return l, nil
+}
+
+// objectGroup converts the json layer to an ObjectGroup.
+func (j *jsonLayer) objectGroup() *ObjectGroup {
+	o := &ObjectGroup{
+		ID:         j.ID,
+		Name:       j.Name,
+		Color:      j.Color,
+		Opacity:    j.Opacity,
+		OffsetX:    j.OffsetX,
+		OffsetY:    j.OffsetY,
+		DrawOrder:  j.DrawOrder,
+		Properties: j.Properties.properties(),
+	}
+	if j.Visible {
+		o.Visible = 1
+	}
+
+	// Topdown is the default draw order, it is left out the same way as in the TMX format.
+	if o.DrawOrder == "topdown" {
+		o.DrawOrder = ""
+	}
+
+	for _, jo := range j.Objects {
+		o.Object = append(o.Object, jo.object())
+	}
+
+	return o
+}
+
+// imageLayer converts the json layer to an ImageLayer.
+func (j *jsonLayer) imageLayer() *ImageLayer {
+	i := &ImageLayer{
+		ID:         j.ID,
+		Name:       j.Name,
+		OffsetX:    j.OffsetX,
+		OffsetY:    j.OffsetY,
+		Opacity:    j.Opacity,
+		Properties: j.Properties.properties(),
+	}
+	if j.Visible {
+		i.Visible = 1
+	}
+	if j.Locked {
+		i.Locked = 1
+	}
+
+	if j.Image != "" {
+		i.Image = &Image{
+			Source: j.Image,
+			Trans:  strings.TrimPrefix(j.TransparentColor, "#"),
+			Width:  j.ImageWidth,
+			Height: j.ImageHeight,
+		}
+	}
+
+	return i
+}
+
+// group converts the json layer to a Group.
+func (j *jsonLayer) group() (*Group, error) {
+	g := &Group{
+		ID:      j.ID,
+		Name:    j.Name,
+		OffsetX: j.OffsetX,
+		OffsetY: j.OffsetY,
+		Opacity: j.Opacity,
+		Visible: j.Visible,
+	}
+
+	g.Properties = j.Properties.properties()
+
+	layers, err := jsonLayerContent(j.Layers)
+	if err != nil {
+		return nil, err
+	}
+	g.Content = append(g.Content, layers...)
+
+	return g, nil
+}
+
+// jsonChunk structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#chunk
+type jsonChunk struct {
+	X      int      `json:"x"`
+	Y      int      `json:"y"`
+	Width  int      `json:"width"`
+	Height int      `json:"height"`
+	Data   jsonData `json:"data"`
+}
+
+// jsonData is tile layer data, saved as an array of global tile IDs or as a base64 encoded string.
+type jsonData struct {
+	GID    []GID
+	Base64 string
+}
+
+// UnmarshalJSON accepts tile data stored as an array or as a string.
+func (d *jsonData) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &d.Base64)
+	}
+	return json.Unmarshal(data, &d.GID)
+}
+
+// gids returns the decoded global tile IDs of the data.
+func (d *jsonData) gids(data *Data) ([]GID, error) {
+	if data.Encoding == EncodingBase64 {
+		return decodeBase64(d.Base64, data.Compression)
+	}
+	return d.GID, nil
+}
+
+// innerXML returns the data the way the TMX format stores it.
+func (d *jsonData) innerXML(data *Data, width int) string {
+	if data.Encoding == EncodingBase64 {
+		return "\n" + d.Base64 + "\n"
+	}
+	return encodeCSV(d.GID, width)
+}
+
+// jsonObject structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#object
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"`
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float32        `json:"rotation"`
+	GID        GID            `json:"gid"`
+	Visible    bool           `json:"visible"`
+	Template   string         `json:"template"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []jsonPoint    `json:"polygon"`
+	Polyline   []jsonPoint    `json:"polyline"`
+	Text       *jsonText      `json:"text"`
+	Properties jsonProperties `json:"properties"`
+
+	// The keys saved in the json object, used to tell instance values from template values.
+	keys map[string]bool
+}
+
+// UnmarshalJSON is called by Unmarshal to produce the value from the json object.
+func (j *jsonObject) UnmarshalJSON(data []byte) error {
+	type object jsonObject
+
+	// Objects are visible unless the value says otherwise.
+	j.Visible = true
+
+	err := json.Unmarshal(data, (*object)(j))
+	if err != nil {
+		return err
+	}
+
+	j.keys, err = jsonKeys(data)
+
+	return err
+}
+
+// object converts the json object to an Object.
+func (j *jsonObject) object() *Object {
+	o := &Object{
+		ID:         j.ID,
+		Name:       j.Name,
+		Type:       j.Type,
+		X:          j.X,
+		Y:          j.Y,
+		Width:      j.Width,
+		Height:     j.Height,
+		Rotation:   j.Rotation,
+		GID:        j.GID,
+		Visible:    j.Visible,
+		Template:   j.Template,
+		Properties: j.Properties.properties(),
+		attrs:      j.keys,
+	}
+
+	// Tiled 1.9 renamed type to class.
+	if j.keys["class"] {
+		o.Type = j.Class
+		o.attrs["type"] = true
+	}
+
+	switch {
+	case j.Ellipse:
+		o.Ellipse = []*Ellipse{{}}
+	case j.Point:
+		o.Point = []*Point{{}}
+	case j.Polygon != nil:
+		o.Polygon = []*Polygon{{Points: jsonPointsPoints(j.Polygon)}}
+	case j.Polyline != nil:
+		o.Polyline = []*Polyline{{Points: jsonPointsPoints(j.Polyline)}}
+	case j.Text != nil:
+		o.Text = []*Text{j.Text.text()}
+	}
+
+	return o
+}
+
+// jsonText structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#text
+type jsonText struct {
+	Text       string `json:"text"`
+	FontFamily string `json:"fontfamily"`
+	PixelSize  int    `json:"pixelsize"`
+	Wrap       bool   `json:"wrap"`
+	Color      string `json:"color"`
+	Bold       bool   `json:"bold"`
+	Italic     bool   `json:"italic"`
+	Underline  bool   `json:"underline"`
+	Strikeout  bool   `json:"strikeout"`
+	Kerning    bool   `json:"kerning"`
+	HAlign     string `json:"halign"`
+	VAlign     string `json:"valign"`
+}
+
+// UnmarshalJSON is called by Unmarshal to produce the value from the json object.
+func (j *jsonText) UnmarshalJSON(data []byte) error {
+	type text jsonText
+
+	def := &Text{}
+	def.setDefaults()
+
+	j.FontFamily = def.FontFamily
+	j.PixelSize = def.PixelSize
+	j.Color = def.Color
+	j.Kerning = def.Kerning
+	j.HAlign = def.HAlign
+	j.VAlign = def.VAlign
+
+	return json.Unmarshal(data, (*text)(j))
+}
+
+// text converts the json text to a Text.
+func (j *jsonText) text() *Text {
+	return &Text{
+		FontFamily: j.FontFamily,
+		PixelSize:  j.PixelSize,
+		Wrap:       j.Wrap,
+		Color:      j.Color,
+		Bold:       j.Bold,
+		Italic:     j.Italic,
+		Underline:  j.Underline,
+		Strikeout:  j.Strikeout,
+		Kerning:    j.Kerning,
+		HAlign:     j.HAlign,
+		VAlign:     j.VAlign,
+		Value:      j.Text,
+	}
+}
+
+// WriteTMJ writes the map in the JSON format, readable by Tiled and other engines that load Tiled JSON maps.
+// Tilesets stored in external files stay external references.
+func (t *TMX) WriteTMJ(w io.Writer) error {
+	value, err := jsonMapValue(t.Map)
+	if err != nil {
+		return fmt.Errorf("error marshaling tmj: %w", err)
+	}
+
+	err = writeJSON(w, value)
+	if err != nil {
+		return fmt.Errorf("error writing tmj: %w", err)
+	}
+
+	return nil
+}
+
+// SaveTMJ writes the map in the JSON format to a file. Tileset, template and image sources are written as they were
+// loaded, so the file should be saved next to the original map to keep relative paths working.
+func (t *TMX) SaveTMJ(name string) error {
+	var b bytes.Buffer
+
+	err := t.WriteTMJ(&b)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(name, b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing tmj file: %w", err)
+	}
+
+	return nil
+}
+
+// jsonMapValue converts a map to a json map.
+func jsonMapValue(m *Map) (jsonObjectValue, error) {
+	v := jsonObjectValue{
+		"type":             "map",
+		"version":          m.Version,
+		"orientation":      m.Orientation,
+		"compressionlevel": m.CompressionLevel,
+		"width":            m.Width,
+		"height":           m.Height,
+		"tilewidth":        m.TileWidth,
+		"tileheight":       m.TileHeight,
+		"infinite":         m.Infinite != 0,
+		"nextlayerid":      m.NextLayerID,
+		"nextobjectid":     m.NextObjectID,
+	}
+	v.setNotEmpty("tiledversion", m.TiledVersion)
+	v.setNotEmpty("renderorder", m.RenderOrder)
+	v.setNotEmpty("hexsidelength", m.HexSideLength)
+	v.setNotEmpty("staggeraxis", m.StaggerAxis)
+	v.setNotEmpty("staggerindex", m.StaggerIndex)
+	v.setNotEmpty("backgroundcolor", m.BackgroundColor)
+
+	tilesets := []jsonObjectValue{}
+	for _, c := range m.Content {
+		if tileset, ok := c.Value.(*Tileset); ok {
+			tilesets = append(tilesets, jsonMapTilesetValue(tileset))
+		}
+	}
+	v["tilesets"] = tilesets
+
+	layers, err := jsonContentValue(m.Content, m.CompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	v["layers"] = layers
+	v.setNotEmpty("properties", jsonPropertiesValue(m.Properties))
+
+	return v, nil
+}
+
+// jsonContentValue converts Map.Content or Group.Content to json layers, tilesets are left out. Tile data is
+// compressed with the compression level of the map.
+func jsonContentValue(content []Content, level int) ([]jsonObjectValue, error) {
+	layers := []jsonObjectValue{}
+
+	for _, c := range content {
+		switch v := c.Value.(type) {
+		case *Layer:
+			layer, err := jsonLayerValue(v, level)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, layer)
+		case *ObjectGroup:
+			layers = append(layers, jsonObjectGroupValue(v))
+		case *ImageLayer:
+			layers = append(layers, jsonImageLayerValue(v))
+		case *Group:
+			group, err := jsonGroupValue(v, level)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, group)
+		}
+	}
+
+	return layers, nil
+}
+
+// jsonLayerAttrs returns the json values shared by every kind of layer.
+func jsonLayerAttrs(layerType string, id int, name string, opacity float32, visible bool, offsetX float32,
+	offsetY float32) jsonObjectValue {
+	v := jsonObjectValue{
+		"type":    layerType,
+		"id":      id,
+		"name":    name,
+		"opacity": opacity,
+		"visible": visible,
+		"x":       0,
+		"y":       0,
+	}
+	v.setNotEmpty("offsetx", offsetX)
+	v.setNotEmpty("offsety", offsetY)
+	return v
+}
+
+// jsonLayerValue converts a tile layer to a json layer, the tile data is written from the decoded global tile IDs in
+// the encoding and compression of the layer data.
+func jsonLayerValue(l *Layer, level int) (jsonObjectValue, error) {
+	v := jsonLayerAttrs("tilelayer", l.ID, l.Name, l.Opacity, l.Visible, l.OffsetX, l.OffsetY)
+	v["x"] = l.X
+	v["y"] = l.Y
+	v["width"] = l.Width
+	v["height"] = l.Height
+	v.setNotEmpty("properties", jsonPropertiesValue(l.Properties))
+
+	encoding, compression := "", ""
+	if l.Data != nil && l.Data.Encoding == EncodingBase64 {
+		encoding, compression = EncodingBase64, l.Data.Compression
+		v["encoding"] = encoding
+		v.setNotEmpty("compression", compression)
+	}
+
+	if l.Data == nil || len(l.Data.Chunk) == 0 {
+		data, err := jsonDataValue(l.GID, encoding, compression, level)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding layer %q: %w", l.Name, err)
+		}
+		v["data"] = data
+		return v, nil
+	}
+
+	chunks := make([]jsonObjectValue, 0, len(l.Data.Chunk))
+	startX, startY := l.Data.Chunk[0].X, l.Data.Chunk[0].Y
+	for _, chunk := range l.Data.Chunk {
+		data, err := jsonDataValue(chunk.GID, encoding, compression, level)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding layer %q chunk %d,%d: %w", l.Name, chunk.X, chunk.Y, err)
+		}
+		chunks = append(chunks, jsonObjectValue{
+			"x":      chunk.X,
+			"y":      chunk.Y,
+			"width":  chunk.Width,
+			"height": chunk.Height,
+			"data":   data,
+		})
+		if chunk.X < startX {
+			startX = chunk.X
+		}
+		if chunk.Y < startY {
+			startY = chunk.Y
+		}
+	}
+	v["chunks"] = chunks
+	v["startx"] = startX
+	v["starty"] = startY
+
+	return v, nil
+}
+
+// jsonDataValue converts global tile IDs to json tile data, an array or a base64 encoded string.
+func jsonDataValue(gids []GID, encoding string, compression string, level int) (interface{}, error) {
+	if encoding == EncodingBase64 {
+		data, err := encodeBase64(gids, compression, level)
+		return strings.TrimSpace(data), err
+	}
+
+	if gids == nil {
+		return []GID{}, nil
+	}
+	return gids, nil
+}
+
+// jsonObjectGroupValue converts an object group to a json layer.
+func jsonObjectGroupValue(o *ObjectGroup) jsonObjectValue {
+	v := jsonLayerAttrs("objectgroup", o.ID, o.Name, o.Opacity, o.Visible != 0, o.OffsetX, o.OffsetY)
+	v.setNotEmpty("color", o.Color)
+	v.setNotEmpty("properties", jsonPropertiesValue(o.PropertySet()))
+
+	v["draworder"] = "topdown"
+	if o.DrawOrder != "" {
+		v["draworder"] = o.DrawOrder
+	}
+
+	objects := make([]jsonObjectValue, 0, len(o.Object))
+	for _, object := range o.Object {
+		objects = append(objects, jsonObjectValueOf(object))
+	}
+	v["objects"] = objects
+
+	return v
+}
+
+// jsonObjectValueOf converts an object to a json object. For template instances, values that are the same as the
+// template are left out, so they are still inherited.
+func jsonObjectValueOf(o *Object) jsonObjectValue {
+	v := jsonObjectValue{
+		"id": o.ID,
+		"x":  o.X,
+		"y":  o.Y,
+	}
+
+	if o.TemplateRef != nil && o.TemplateRef.Object != nil {
+		def := o.TemplateRef.Object
+
+		v["template"] = o.Template
+		setIfChanged := func(key string, value interface{}, defValue interface{}) {
+			if value != defValue {
+				v[key] = value
+			}
+		}
+		setIfChanged("name", o.Name, def.Name)
+		setIfChanged("type", o.Type, def.Type)
+		setIfChanged("width", o.Width, def.Width)
+		setIfChanged("height", o.Height, def.Height)
+		setIfChanged("rotation", o.Rotation, def.Rotation)
+		setIfChanged("visible", o.Visible, def.Visible)
+		setIfChanged("gid", o.GID, o.TemplateRef.mapGID(def.GID))
+		v.setNotEmpty("properties", jsonPropertiesValue(o.instanceProperties()))
+		if o.shapeElement() != def.shapeElement() {
+			jsonSetShape(v, o)
+		}
+		return v
+	}
+
+	v["name"] = o.Name
+	v["type"] = o.Type
+	v["width"] = o.Width
+	v["height"] = o.Height
+	v["rotation"] = o.Rotation
+	v["visible"] = o.Visible
+	if o.GID != 0 {
+		v["gid"] = o.GID
+	}
+	v.setNotEmpty("template", o.Template)
+	v.setNotEmpty("properties", jsonPropertiesValue(o.Properties))
+	jsonSetShape(v, o)
+
+	return v
+}
+
+// jsonSetShape sets the shape of an object on a json object.
+func jsonSetShape(v jsonObjectValue, o *Object) {
+	switch {
+	case len(o.Ellipse) > 0:
+		v["ellipse"] = true
+	case len(o.Point) > 0:
+		v["point"] = true
+	case len(o.Polygon) > 0:
+		v["polygon"] = jsonPointsValue(o.Polygon[0].Points)
+	case len(o.Polyline) > 0:
+		v["polyline"] = jsonPointsValue(o.Polyline[0].Points)
+	case len(o.Text) > 0:
+		v["text"] = jsonTextValue(o.Text[0])
+	}
+}
+
+// jsonPointsValue converts polygon or polyline points to json points.
+func jsonPointsValue(points Points) []jsonPoint {
+	values := make([]jsonPoint, len(points))
+	for i, point := range points {
+		values[i] = jsonPoint{X: point.X, Y: point.Y}
+	}
+	return values
+}
+
+// jsonTextValue converts a text to a json text, leaving out values that have their default value.
+func jsonTextValue(t *Text) jsonObjectValue {
+	def := &Text{}
+	def.setDefaults()
+
+	v := jsonObjectValue{"text": t.Value}
+	setIfChanged := func(key string, value interface{}, defValue interface{}) {
+		if value != defValue {
+			v[key] = value
+		}
+	}
+	setIfChanged("fontfamily", t.FontFamily, def.FontFamily)
+	setIfChanged("pixelsize", t.PixelSize, def.PixelSize)
+	setIfChanged("wrap", t.Wrap, def.Wrap)
+	setIfChanged("color", t.Color, def.Color)
+	setIfChanged("bold", t.Bold, def.Bold)
+	setIfChanged("italic", t.Italic, def.Italic)
+	setIfChanged("underline", t.Underline, def.Underline)
+	setIfChanged("strikeout", t.Strikeout, def.Strikeout)
+	setIfChanged("kerning", t.Kerning, def.Kerning)
+	setIfChanged("halign", t.HAlign, def.HAlign)
+	setIfChanged("valign", t.VAlign, def.VAlign)
+
+	return v
+}
+
+// jsonImageLayerValue converts an image layer to a json layer.
+func jsonImageLayerValue(i *ImageLayer) jsonObjectValue {
+	v := jsonLayerAttrs("imagelayer", i.ID, i.Name, i.Opacity, i.Visible != 0, i.OffsetX, i.OffsetY)
+	v.setNotEmpty("properties", jsonPropertiesValue(i.PropertySet()))
+	if i.Locked != 0 {
+		v["locked"] = true
+	}
+
+	v["image"] = ""
+	if i.Image != nil {
+		v["image"] = i.Image.Source
+		v.setNotEmpty("imagewidth", i.Image.Width)
+		v.setNotEmpty("imageheight", i.Image.Height)
+		if i.Image.Trans != "" {
+			v["transparentcolor"] = "#" + strings.TrimPrefix(i.Image.Trans, "#")
+		}
+	}
+
+	return v
+}
+
+// jsonGroupValue converts a group to a json layer.
+func jsonGroupValue(g *Group, level int) (jsonObjectValue, error) {
+	v := jsonLayerAttrs("group", g.ID, g.Name, g.Opacity, g.Visible, g.OffsetX, g.OffsetY)
+
+	layers, err := jsonContentValue(g.Content, level)
+	if err != nil {
+		return nil, err
+	}
+	v["layers"] = layers
+	v.setNotEmpty("properties", jsonPropertiesValue(g.Properties))
+
+	return v, nil
+}
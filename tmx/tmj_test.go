@@ -0,0 +1,427 @@
+package tmx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// levelTMJ is maps/level.tmx of memoryFiles saved in the JSON format.
+const levelTMJ = `{
+ "compressionlevel": -1,
+ "height": 2,
+ "infinite": false,
+ "layers": [
+  {"data": [1, 2, 3, 4], "height": 2, "id": 1, "name": "ground", "opacity": 1, "type": "tilelayer", "visible": true,
+   "width": 2, "x": 0, "y": 0},
+  {"id": 2, "image": "bg.png", "imagewidth": 32, "imageheight": 32, "name": "background", "opacity": 1,
+   "type": "imagelayer", "visible": true, "x": 0, "y": 0},
+  {"draworder": "topdown", "id": 3, "name": "objects", "opacity": 1, "type": "objectgroup", "visible": true, "x": 0,
+   "y": 0, "objects": [
+    {"id": 1, "template": "../templates/chest.tx", "x": 8, "y": 24},
+    {"id": 2, "name": "open chest", "template": "../templates/chest.tx", "x": 24, "y": 24}
+   ]}
+ ],
+ "nextlayerid": 4,
+ "nextobjectid": 3,
+ "orientation": "orthogonal",
+ "properties": [{"name": "data", "type": "file", "value": "data/level.json"}],
+ "renderorder": "right-down",
+ "tiledversion": "1.10.2",
+ "tileheight": 16,
+ "tilesets": [{"firstgid": 1, "source": "../tilesets/terrain.tsx"}],
+ "tilewidth": 16,
+ "type": "map",
+ "version": "1.10",
+ "width": 2
+}`
+
+// tmjFiles returns memoryFiles with the map saved in the JSON format next to the TMX map.
+func tmjFiles() MapResolver {
+	files := memoryFiles()
+	files["maps/level.tmj"] = []byte(levelTMJ)
+	return files
+}
+
+func TestLoadTMJResolver(t *testing.T) {
+	files := tmjFiles()
+
+	want, err := LoadTMXResolver(files, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadTMJResolver(files, "maps/level.tmj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareMaps(t, got.Map, want.Map)
+
+	// LoadTMX loads maps in the JSON format by their extension.
+	got, err = LoadTMXResolver(files, "maps/level.tmj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareMaps(t, got.Map, want.Map)
+}
+
+func TestLoadTMJData(t *testing.T) {
+	for _, test := range dataLayers {
+		if test.encoding == "" || test.encoding == EncodingCSV {
+			continue
+		}
+
+		t.Run(test.encoding+" "+test.compression, func(t *testing.T) {
+			data := `{"width": 2, "height": 2, "tilewidth": 16, "tileheight": 16, "orientation": "orthogonal",
+"layers": [{"type": "tilelayer", "name": "ground", "width": 2, "height": 2, "encoding": "` + test.encoding +
+				`", "compression": "` + test.compression + `", "data": "` + strings.TrimSpace(test.data) + `"}]}`
+
+			tmx, err := LoadTMXBytes([]byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			layer, ok := tmx.Map.Content[0].Value.(*Layer)
+			if !ok {
+				t.Fatalf("content is %T, want a layer", tmx.Map.Content[0].Value)
+			}
+			if !equalGIDs(layer.GID, dataGIDs) {
+				t.Errorf("GID = %v, want %v", layer.GID, dataGIDs)
+			}
+		})
+	}
+}
+
+func TestLoadTMJTilesets(t *testing.T) {
+	files := tmjFiles()
+	files["maps/level.tmj"] = []byte(`{"width": 2, "height": 1, "tilewidth": 16, "tileheight": 16,
+"orientation": "orthogonal", "tilesets": [
+ {"firstgid": 1, "source": "../tilesets/terrain.tsj"},
+ {"firstgid": 5, "name": "embedded", "tilewidth": 16, "tileheight": 16, "tilecount": 2, "columns": 2,
+  "image": "embedded.png", "imagewidth": 32, "imageheight": 16}
+],
+"layers": [{"type": "tilelayer", "name": "ground", "width": 2, "height": 1, "data": [4, 6]}]}`)
+	files["tilesets/terrain.tsj"] = []byte(`{"name": "terrain", "tilewidth": 16, "tileheight": 16, "tilecount": 4,
+"columns": 2, "image": "terrain.png", "imagewidth": 32, "imageheight": 32, "type": "tileset"}`)
+
+	tmx, err := LoadTMXResolver(files, "maps/level.tmj")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		gid     GID
+		tileset string
+		id      int
+		image   string
+	}{
+		{4, "terrain", 3, "tilesets/terrain.png"},
+		{6, "embedded", 1, "maps/embedded.png"},
+	}
+	for _, test := range tests {
+		ref, err := tmx.ResolveGID(test.gid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.Tileset.Name != test.tileset || ref.ID != test.id || ref.Image.Path != test.image {
+			t.Errorf("gid %d is tile %d of %q with image %q, want tile %d of %q with image %q", test.gid, ref.ID,
+				ref.Tileset.Name, ref.Image.Path, test.id, test.tileset, test.image)
+		}
+	}
+}
+
+func TestLoadTMJErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `{"width": 2,`},
+		{"unknown layer type", `{"layers": [{"type": "shapelayer"}]}`},
+		{"too few tiles", `{"layers": [{"type": "tilelayer", "width": 2, "height": 2, "data": [1, 2, 3]}]}`},
+		{"bad base64", `{"layers": [{"type": "tilelayer", "width": 1, "height": 1, "encoding": "base64",
+"data": "!!"}]}`},
+	}
+
+	for _, test := range tests {
+		_, err := LoadTMJBytes([]byte(test.data))
+		if err == nil {
+			t.Errorf("%s: LoadTMJBytes did not return an error", test.name)
+		}
+	}
+}
+
+func TestWriteTMJRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		files MapResolver
+		path  string
+	}{
+		{"testdata", testdataFiles(t), "map.tmx"},
+		{"templates", memoryFiles(), "maps/level.tmx"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original, err := LoadTMXResolver(test.files, test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var b bytes.Buffer
+			err = original.WriteTMJ(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The written map is saved next to the original so its tilesets, templates and images resolve the same way.
+			name := strings.TrimSuffix(test.path, ".tmx") + ".tmj"
+			test.files[name] = b.Bytes()
+			loaded, err := LoadTMXResolver(test.files, name)
+			if err != nil {
+				t.Fatalf("loading the written map: %v\n%s", err, b.String())
+			}
+			compareMaps(t, loaded.Map, original.Map)
+
+			// Writing the loaded map again produces the same json.
+			var again bytes.Buffer
+			err = loaded.WriteTMJ(&again)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.String() != b.String() {
+				t.Errorf("written twice, the maps differ:\n%s\n%s", b.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestWriteTMJData(t *testing.T) {
+	m := `<map version="1.10" orientation="orthogonal" width="2" height="2" tilewidth="16" tileheight="16" infinite="1">
+ <layer id="1" name="fixed" width="2" height="2">
+  <data encoding="base64" compression="zlib">eJxjZGBgYGKAAGYGhgYAAMQAhw==</data>
+ </layer>
+ <layer id="2" name="chunks" width="2" height="2">
+  <data encoding="csv">
+   <chunk x="-16" y="0" width="2" height="2">1,2,0,2147483651</chunk>
+   <chunk x="0" y="16" width="2" height="2">0,0,0,4</chunk>
+  </data>
+ </layer>
+</map>`
+
+	original, err := LoadTMXBytes([]byte(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = original.WriteTMJ(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Base64 data keeps its compression, csv data is written as arrays.
+	for _, want := range []string{`"compression": "zlib"`, `"startx": -16`, `"data": [`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("written map does not contain %s:\n%s", want, b.String())
+		}
+	}
+
+	loaded, err := LoadTMXBytes(b.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	compareMaps(t, loaded.Map, original.Map)
+
+	chunks := loaded.Map.Content[1].Value.(*Layer).Data.Chunk
+	if len(chunks) != 2 || chunks[0].X != -16 || !equalGIDs(chunks[0].GID, dataGIDs) || chunks[1].GID[3] != 4 {
+		t.Errorf("chunks were not written back")
+	}
+}
+
+func TestWriteTSJRoundTrip(t *testing.T) {
+	original, err := LoadTSJBytes([]byte(detailedTSJ))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = original.WriteTSJ(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadTSJBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("loading the written tileset: %v\n%s", err, b.String())
+	}
+
+	if loaded.Name != original.Name || loaded.TileCount != original.TileCount || loaded.Columns != original.Columns ||
+		loaded.Margin != original.Margin || loaded.Spacing != original.Spacing || loaded.Image == nil ||
+		loaded.Image.Source != original.Image.Source || loaded.Image.Trans != original.Image.Trans {
+		t.Errorf("tileset %q with %d tiles in %d columns, want %q with %d tiles in %d columns", loaded.Name,
+			loaded.TileCount, loaded.Columns, original.Name, original.TileCount, original.Columns)
+	}
+	if loaded.TileOffset == nil || *loaded.TileOffset != *original.TileOffset {
+		t.Errorf("tile offset %v, want %v", loaded.TileOffset, original.TileOffset)
+	}
+
+	// The content of every tile is kept.
+	water := loaded.TileByID(1)
+	if water == nil || water.Type != "water" || water.Probability != 0.5 || len(water.Properties) != 1 ||
+		len(water.ObjectGroup) != 1 || water.Animation == nil || len(water.Animation.Frame) != 2 {
+		t.Errorf("tile 1 is %v, want the water tile", water)
+	}
+	if grass := loaded.TileByID(2); grass == nil || grass.Type != "grass" {
+		t.Errorf("tile 2 is %v, want grass", grass)
+	}
+
+	var again bytes.Buffer
+	err = loaded.WriteTSJ(&again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != b.String() {
+		t.Errorf("written twice, the tilesets differ:\n%s\n%s", b.String(), again.String())
+	}
+}
+
+func TestWriteTSJTerrain(t *testing.T) {
+	original := loadTerrainTileset(t)
+
+	var b bytes.Buffer
+	err := original.WriteTSJ(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadTSJBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("loading the written tileset: %v\n%s", err, b.String())
+	}
+
+	if loaded.Name != original.Name || loaded.TileCount != original.TileCount || loaded.Columns != original.Columns ||
+		loaded.Image == nil || loaded.Image.Source != original.Image.Source {
+		t.Errorf("tileset %q with %d tiles in %d columns, want %q with %d tiles in %d columns", loaded.Name,
+			loaded.TileCount, loaded.Columns, original.Name, original.TileCount, original.Columns)
+	}
+
+	// The terrain of every tile is kept.
+	got, want := loaded.TerrainWangset(), original.TerrainWangset()
+	if got == nil || len(got.WangTile) != len(want.WangTile) {
+		t.Fatalf("terrain tiles were not written")
+	}
+	for i := range want.WangTile {
+		if got.WangTile[i].TileID != want.WangTile[i].TileID || got.WangTile[i].WangID != want.WangTile[i].WangID {
+			t.Errorf("terrain tile %d is %v, want %v", i, got.WangTile[i], want.WangTile[i])
+		}
+	}
+}
+
+func TestWriteTMJShapes(t *testing.T) {
+	original, err := LoadTMXBytes([]byte(shapesMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = original.WriteTMJ(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Text attributes with their default value are left out.
+	for _, want := range []string{`"ellipse": true`, `"point": true`, `"polygon": [`, `"polyline": [`,
+		`"halign": "center"`, `"kerning": false`, `"text": "Hello \u0026 welcome"`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("written map does not contain %s:\n%s", want, b.String())
+		}
+	}
+
+	loaded, err := LoadTMXBytes(b.Bytes())
+	if err != nil {
+		t.Fatalf("loading the written map: %v\n%s", err, b.String())
+	}
+	compareMaps(t, loaded.Map, original.Map)
+
+	objects := loaded.Map.Content[2].Value.(*ObjectGroup).Object
+	shapes := []ShapeKind{ShapeText, ShapeText, ShapePolygon, ShapePolyline, ShapeEllipse, ShapePoint}
+	if len(objects) != len(shapes) {
+		t.Fatalf("loaded %d objects, want %d", len(objects), len(shapes))
+	}
+	for i, o := range objects {
+		if o.Shape() != shapes[i] {
+			t.Errorf("object %d is a %v, want a %v", o.ID, o.Shape(), shapes[i])
+		}
+	}
+
+	want := original.Map.Content[2].Value.(*ObjectGroup).Object
+	for i := 0; i < 2; i++ {
+		got := *objects[i].Text[0]
+		got.XMLName = want[i].Text[0].XMLName
+		if got != *want[i].Text[0] {
+			t.Errorf("text %+v, want %+v", got, *want[i].Text[0])
+		}
+	}
+	if got := objects[2].Polygon[0].Points; !equalVertices(got, want[2].Polygon[0].Points) {
+		t.Errorf("polygon points %v, want %v", got, want[2].Polygon[0].Points)
+	}
+	if got := objects[3].Polyline[0].Points; !equalVertices(got, want[3].Polyline[0].Points) {
+		t.Errorf("polyline points %v, want %v", got, want[3].Polyline[0].Points)
+	}
+
+	var again bytes.Buffer
+	err = loaded.WriteTMJ(&again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != b.String() {
+		t.Errorf("written twice, the maps differ:\n%s\n%s", b.String(), again.String())
+	}
+}
+
+func TestWriteTMJCompressionLevel(t *testing.T) {
+	layer := &Layer{Width: 32, Height: 32, GID: make([]GID, 32*32)}
+	for i := range layer.GID {
+		layer.GID[i] = GID((i*i/5 + i/3) % 11)
+	}
+	err := layer.EncodeData(EncodingBase64, CompressionZlib, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := `<map version="1.10" orientation="orthogonal" width="32" height="32" tilewidth="16" tileheight="16"
+ compressionlevel="%d">
+ <layer id="1" name="ground" width="32" height="32">
+  <data encoding="base64" compression="zlib">%s</data>
+ </layer>
+</map>`
+
+	// The tile data is compressed with the level of the map.
+	sizes := map[int]int{}
+	for _, level := range []int{1, 9} {
+		original, err := LoadTMXBytes([]byte(fmt.Sprintf(m, level, layer.Data.InnerXML)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b bytes.Buffer
+		err = original.WriteTMJ(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = b.Len()
+
+		loaded, err := LoadTMXBytes(b.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Map.CompressionLevel != level {
+			t.Errorf("compression level %d, want %d", loaded.Map.CompressionLevel, level)
+		}
+		compareMaps(t, loaded.Map, original.Map)
+	}
+
+	if sizes[1] <= sizes[9] {
+		t.Errorf("the map is %d bytes at level 1 and %d bytes at level 9", sizes[1], sizes[9])
+	}
+}
@@ -20,6 +20,9 @@
 package tmx
 
 import (
+	"bytes"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"path/filepath"
 
@@ -30,106 +33,121 @@ import (
 // TMX structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#tmx-map-format
 type TMX struct {
 	Map *Map
-}
 
-var (
-	tmxDir  string
-	tmxFile string
-)
+	// The resolver the map was loaded with, used to open the images and files referenced by the map.
+	Resolver Resolver
+}
 
-// LoadTMX loads the xml of a tmx file into a TMX struct.
+// LoadTMX loads the xml of a tmx file into a TMX struct. External tilesets and images are resolved relative to the
+// directory of the tmx file. Maps saved in the JSON format (.tmj or .json) are loaded with LoadTMJ, so callers do not
+// need to know which format a map was saved in.
 func LoadTMX(source string) (*TMX, error) {
-	var err error
-
-	t := new(TMX)
-
-	// Get tmx directory and filename and create a safe path.
-	// tmxDir, tmxFile = filepath.Split(source)
-	// tmxPath := filepath.Join(tmxDir, tmxFile)
-
-	// fmt.Println(tmxPath)
-
 	absSource, err := filepath.Abs(source)
 	if err != nil {
 		return nil, err
 	}
 
-	// fmt.Println("tmx:", absSource)
+	return LoadTMXResolver(OSResolver{}, absSource)
+}
 
-	// Unmarshal the tmx path.
-	tmxBytes, err := ioutil.ReadFile(absSource)
-	if err != nil {
-		return nil, fmt.Errorf("error reading tmx file: %w", err)
+// LoadTMXBytes loads the xml of a tmx file into a TMX struct. External tilesets and images are resolved relative to
+// the current working directory. Bytes holding a JSON object are loaded as a map in the JSON format.
+func LoadTMXBytes(data []byte) (*TMX, error) {
+	l := newLoader(OSResolver{}, "")
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return l.loadTMJ(data)
 	}
 
-	//fmt.Println(string(tmxBytes))
+	return l.loadTMX(data)
+}
 
-	err = xml.Unmarshal(tmxBytes, &t.Map)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling tmx bytes: %w", err)
-	}
+// LoadTMXFS loads the xml of a tmx file from a file system, such as an embed.FS or a zip.Reader, into a TMX struct.
+// External tilesets and images are resolved relative to the tmx file within the same file system. Maps in the JSON
+// format are loaded with LoadTMJFS.
+func LoadTMXFS(fsys fs.FS, name string) (*TMX, error) {
+	return LoadTMXResolver(FSResolver{FS: fsys}, name)
+}
 
-	// // Process each tileset in the tmx file.
-	// for _, tileset := range t.Map.Tileset {
+// LoadTMXResolver loads the xml of a tmx file opened with a Resolver into a TMX struct. External tilesets and images
+// are resolved and opened with the same Resolver. Maps in the JSON format are loaded with LoadTMJResolver.
+func LoadTMXResolver(resolver Resolver, name string) (*TMX, error) {
+	if isJSONFile(name) {
+		return LoadTMJResolver(resolver, name)
+	}
 
-	// 	// Update any image sources that are embedded the tmx file.
-	// 	if tileset.Image != nil {
+	tmxBytes, err := readFile(resolver, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tmx file: %w", err)
+	}
 
-	// 		// Get image directory and filename and create a safe path.
-	// 		imgDir, imgFile := filepath.Split(tileset.Image.Source)
-	// 		imgPath := filepath.Join(tmxDir, imgDir, imgFile)
+	l := newLoader(resolver, name)
 
-	// 		// Update image source with a safe path.
-	// 		tileset.Image.Source = imgPath
+	return l.loadTMX(tmxBytes)
+}
 
-	// 	}
+// loadTMX unmarshals the tmx bytes and resolves the tilesets and images referenced by the map.
+func (l *loader) loadTMX(bytes []byte) (*TMX, error) {
+	t := &TMX{Resolver: l.resolver}
 
-	// 	// External Tileset
-	// 	// Update any tileset and image sources that come from external tsx files.
-	// 	if tileset.Source != "" {
+	err := xml.Unmarshal(bytes, &t.Map)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling tmx bytes: %w", err)
+	}
 
-	// 		// Get tsx directory and filename and create a safe path.
-	// 		tsxDir, tsxFile := filepath.Split(tileset.Source)
-	// 		tsxPath := filepath.Join(tmxDir, tsxDir, tsxFile)
+	err = l.loadMap(t.Map)
+	if err != nil {
+		return nil, err
+	}
 
-	// 		// Update tileset source with a safe path.
-	// 		tileset.Source = tsxPath
+	return t, nil
+}
 
-	// 		// Unmarshal a tsx path.
-	// 		tsxBytes, err := ioutil.ReadFile(tsxPath)
-	// 		if err != nil {
-	// 			return nil, fmt.Errorf("error reading tsx file: %w", err)
-	// 		}
-	// 		err = xml.Unmarshal(tsxBytes, &tileset)
-	// 		if err != nil {
-	// 			return nil, fmt.Errorf("error unmarshaling tsx bytes: %w", err)
-	// 		}
+// WriteTMX writes the map as tmx xml. Content keeps its order and attributes with default values are left out, the
+// same way Tiled saves maps.
+func (t *TMX) WriteTMX(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("error writing tmx: %w", err)
+	}
 
-	// 		// Get image directory and filename and create a safe path.
-	// 		imgDir, imgFile := filepath.Split(tileset.Image.Source)
-	// 		imgPath := filepath.Join(tmxDir, tsxDir, imgDir, imgFile)
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", " ")
 
-	// 		// Update image source with a safe path.
-	// 		tileset.Image.Source = imgPath
+	err = encoder.Encode(t.Map)
+	if err != nil {
+		return fmt.Errorf("error marshaling tmx: %w", err)
+	}
 
-	// 	}
-	// }
+	_, err = io.WriteString(w, "\n")
+	if err != nil {
+		return fmt.Errorf("error writing tmx: %w", err)
+	}
 
-	return t, nil
+	return nil
 }
 
-// LoadTMXBytes loads the xml of a tmx file into a TMX struct.
-func LoadTMXBytes(bytes []byte) (*TMX, error) {
-	var err error
+// SaveTMX writes the map as tmx xml to a file. Tileset, template and image sources are written as they were loaded,
+// so the file should be saved next to the original map to keep relative paths working.
+func (t *TMX) SaveTMX(name string) error {
+	var b bytes.Buffer
 
-	t := new(TMX)
+	err := t.WriteTMX(&b)
+	if err != nil {
+		return err
+	}
 
-	err = xml.Unmarshal(bytes, &t.Map)
+	err = ioutil.WriteFile(name, b.Bytes(), 0644)
 	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling tmx bytes: %w", err)
+		return fmt.Errorf("error writing tmx file: %w", err)
 	}
 
-	return t, nil
+	return nil
+}
+
+// Open opens a resolved path, such as Image.Path or Property.Path, with the Resolver the map was loaded with.
+func (t *TMX) Open(name string) (io.ReadCloser, error) {
+	return t.Resolver.Open(name)
 }
 
 // TilesetCount ranges Map.Content to get a count of Tilesets.
@@ -175,6 +193,11 @@ func (t *TMX) LayerCount(count int, content []Content) int {
 	return count
 }
 
+// ResolveGID resolves a global tile ID to its Tileset and local Tile, see Map.ResolveGID.
+func (t *TMX) ResolveGID(gid GID) (*TileRef, error) {
+	return t.Map.ResolveGID(gid)
+}
+
 func (t *TMX) String() string {
 	return t.Map.String()
 }
@@ -0,0 +1,296 @@
+package tmx
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// testdataFiles returns a MapResolver holding the example map and its tileset, so maps written by the tests can be
+// loaded next to them.
+func testdataFiles(t *testing.T) MapResolver {
+	t.Helper()
+
+	files := MapResolver{}
+	for _, name := range []string{"map.tmx", "tileset.tsx"} {
+		data, err := ioutil.ReadFile("../examples/testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = data
+	}
+	return files
+}
+
+// summarizeContent describes the content of a map or group one line per layer, object and property, with the decoded
+// tiles of each layer, so maps loaded from different formats can be compared.
+func summarizeContent(content []Content) []string {
+	var lines []string
+
+	for _, c := range content {
+		switch v := c.Value.(type) {
+		case *Tileset:
+			lines = append(lines, fmt.Sprintf("tileset %d %q %q", v.FirstGID, v.Name, v.Path))
+		case *Layer:
+			lines = append(lines, fmt.Sprintf("layer %d %q %dx%d %v", v.ID, v.Name, v.Width, v.Height, v.GID))
+			lines = append(lines, summarizeProperties(v.Properties)...)
+		case *ImageLayer:
+			line := fmt.Sprintf("imagelayer %d %q", v.ID, v.Name)
+			if v.Image != nil {
+				line += fmt.Sprintf(" %q", v.Image.Path)
+			}
+			lines = append(lines, line)
+			lines = append(lines, summarizeProperties(v.Properties)...)
+		case *ObjectGroup:
+			lines = append(lines, fmt.Sprintf("objectgroup %d %q", v.ID, v.Name))
+			for _, o := range v.Object {
+				lines = append(lines, fmt.Sprintf("object %d %q %q %d %v,%v %vx%v", o.ID, o.Name, o.Type, o.GID, o.X,
+					o.Y, o.Width, o.Height))
+				lines = append(lines, summarizeProperties(o.Properties)...)
+			}
+		case *Group:
+			lines = append(lines, fmt.Sprintf("group %d %q", v.ID, v.Name))
+			lines = append(lines, summarizeContent(v.Content)...)
+			lines = append(lines, "end group")
+		}
+	}
+
+	return lines
+}
+
+// summarizeProperties describes properties one line each.
+func summarizeProperties(properties PropertySet) []string {
+	var lines []string
+	for _, p := range properties {
+		lines = append(lines, fmt.Sprintf("property %q %q %q %q", p.Name, p.Type, p.Value, p.Path))
+	}
+	return lines
+}
+
+// compareMaps reports the first difference between the summaries of two maps.
+func compareMaps(t *testing.T, got *Map, want *Map) {
+	t.Helper()
+
+	if got.Width != want.Width || got.Height != want.Height || got.TileWidth != want.TileWidth ||
+		got.Orientation != want.Orientation || got.NextObjectID != want.NextObjectID {
+		t.Errorf("map %s %dx%d, want %s %dx%d", got.Orientation, got.Width, got.Height, want.Orientation, want.Width,
+			want.Height)
+	}
+
+	gotLines := append(summarizeProperties(got.Properties), summarizeContent(got.Content)...)
+	wantLines := append(summarizeProperties(want.Properties), summarizeContent(want.Content)...)
+	for i := range wantLines {
+		if i >= len(gotLines) {
+			t.Errorf("missing %s", wantLines[i])
+			return
+		}
+		if gotLines[i] != wantLines[i] {
+			t.Errorf("found %s, want %s", gotLines[i], wantLines[i])
+			return
+		}
+	}
+	if len(gotLines) > len(wantLines) {
+		t.Errorf("found %s, want nothing more", gotLines[len(wantLines)])
+	}
+}
+
+func TestWriteTMXRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		files MapResolver
+		path  string
+	}{
+		{"testdata", testdataFiles(t), "map.tmx"},
+		{"templates", memoryFiles(), "maps/level.tmx"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original, err := LoadTMXResolver(test.files, test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var b bytes.Buffer
+			err = original.WriteTMX(&b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The written map replaces the original so its tilesets, templates and images resolve the same way.
+			test.files[test.path] = b.Bytes()
+			loaded, err := LoadTMXResolver(test.files, test.path)
+			if err != nil {
+				t.Fatalf("loading the written map: %v\n%s", err, b.String())
+			}
+			compareMaps(t, loaded.Map, original.Map)
+
+			// Writing the loaded map again produces the same xml.
+			var again bytes.Buffer
+			err = loaded.WriteTMX(&again)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.String() != b.String() {
+				t.Errorf("written twice, the maps differ:\n%s\n%s", b.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestWriteTMXTemplateInstances(t *testing.T) {
+	tmx, err := LoadTMXResolver(memoryFiles(), "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = tmx.WriteTMX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Template instances only save what differs from the template, the template values are not copied into the map.
+	want := `<object id="1" template="../templates/chest.tx" x="8" y="24"></object>`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("written map does not contain %s:\n%s", want, b.String())
+	}
+	if strings.Contains(b.String(), "loot") || strings.Contains(b.String(), " gid=") {
+		t.Errorf("written map contains values of the template:\n%s", b.String())
+	}
+}
+
+func TestWriteTMXObjectGID(t *testing.T) {
+	m := `<map version="1.10" orientation="orthogonal" width="1" height="1" tilewidth="16" tileheight="16">
+ <objectgroup id="1" name="objects">
+  <object id="1" gid="2147483654" x="0" y="16" width="16" height="16"/>
+ </objectgroup>
+</map>`
+
+	tmx, err := LoadTMXResolver(MapResolver{"map.tmx": []byte(m)}, "map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = tmx.WriteTMX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flipped tiles are written unsigned, the way Tiled saves them.
+	if !strings.Contains(b.String(), `gid="2147483654"`) {
+		t.Errorf("flipped object gid is not written unsigned:\n%s", b.String())
+	}
+}
+
+// shapesMap has an embedded tileset with tile content, and objects with text and point lists.
+const shapesMap = `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" tiledversion="1.10.2" orientation="orthogonal" renderorder="right-down" width="2" height="2" tilewidth="16" tileheight="16" infinite="0" nextlayerid="3" nextobjectid="7">
+ <tileset firstgid="1" name="embedded" tilewidth="16" tileheight="16" spacing="1" tilecount="2" columns="2">
+  <tileoffset x="0" y="4"/>
+  <image source="embedded.png" width="33" height="16"/>
+  <tile id="1" type="door" probability="0.25">
+   <properties>
+    <property name="locked" type="bool" value="true"/>
+   </properties>
+   <objectgroup draworder="index" id="2">
+    <object id="1" x="2" y="0" width="12" height="16"/>
+   </objectgroup>
+   <animation>
+    <frame tileid="0" duration="100"/>
+    <frame tileid="1" duration="100"/>
+   </animation>
+  </tile>
+ </tileset>
+ <layer id="1" name="ground" width="2" height="2">
+  <data encoding="csv">1,2,0,2</data>
+ </layer>
+ <objectgroup id="2" name="objects">
+  <object id="1" name="sign" x="0" y="0" width="64" height="20">
+   <text fontfamily="serif" pixelsize="12" wrap="1" color="#ff102030" bold="1" kerning="0" halign="center" valign="bottom">Hello &amp; welcome</text>
+  </object>
+  <object id="2" name="plain" x="0" y="20" width="32" height="16">
+   <text>Defaults</text>
+  </object>
+  <object id="3" name="wall" x="4" y="8">
+   <polygon points="0,0 16,0 16,-8.5"/>
+  </object>
+  <object id="4" name="path" x="4" y="8">
+   <polyline points="0,0 -3,7.25"/>
+  </object>
+  <object id="5" name="pond" x="0" y="0" width="20" height="10">
+   <ellipse/>
+  </object>
+  <object id="6" name="spawn" x="5" y="6">
+   <point/>
+  </object>
+ </objectgroup>
+</map>`
+
+func TestWriteTMXShapes(t *testing.T) {
+	original, err := LoadTMXResolver(MapResolver{"map.tmx": []byte(shapesMap)}, "map.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	err = original.WriteTMX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Attributes with their default value are left out, the others are written back.
+	for _, want := range []string{
+		`<text fontfamily="serif" pixelsize="12" wrap="1" color="#ff102030" bold="1" kerning="0" halign="center" ` +
+			`valign="bottom">Hello &amp; welcome</text>`,
+		`<text>Defaults</text>`,
+		`<polygon points="0,0 16,0 16,-8.5"></polygon>`,
+		`<polyline points="0,0 -3,7.25"></polyline>`,
+		`<ellipse></ellipse>`,
+		`<point></point>`,
+		`<tile id="1" type="door" probability="0.25">`,
+		`<tileoffset x="0" y="4"></tileoffset>`,
+		`<frame tileid="1" duration="100"></frame>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("written map does not contain %s:\n%s", want, b.String())
+		}
+	}
+
+	files := MapResolver{"map.tmx": b.Bytes()}
+	loaded, err := LoadTMXResolver(files, "map.tmx")
+	if err != nil {
+		t.Fatalf("loading the written map: %v\n%s", err, b.String())
+	}
+	compareMaps(t, loaded.Map, original.Map)
+
+	objects := loaded.Map.Content[2].Value.(*ObjectGroup).Object
+	sign, plain := objects[0].Text[0], objects[1].Text[0]
+	if sign.FontFamily != "serif" || sign.PixelSize != 12 || !sign.Wrap || sign.Color != "#ff102030" || !sign.Bold ||
+		sign.Kerning || sign.HAlign != "center" || sign.VAlign != "bottom" || sign.Value != "Hello & welcome" {
+		t.Errorf("text is %+v", sign)
+	}
+	if plain.FontFamily != "sans-serif" || plain.PixelSize != 16 || plain.Color != "#000000" || !plain.Kerning ||
+		plain.HAlign != "left" || plain.VAlign != "top" || plain.Value != "Defaults" {
+		t.Errorf("text without attributes is %+v, want the defaults", plain)
+	}
+
+	tileset := loaded.Map.Content[0].Value.(*Tileset)
+	tile := tileset.TileByID(1)
+	if tileset.IsExternal() || tileset.Spacing != 1 || tile == nil || tile.Type != "door" ||
+		len(tile.Properties) != 1 || len(tile.ObjectGroup) != 1 || tile.Animation == nil {
+		t.Errorf("embedded tileset %q lost its tile content", tileset.Name)
+	}
+
+	var again bytes.Buffer
+	err = loaded.WriteTMX(&again)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != b.String() {
+		t.Errorf("written twice, the maps differ:\n%s\n%s", b.String(), again.String())
+	}
+}
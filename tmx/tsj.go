@@ -0,0 +1,551 @@
+package tmx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// LoadTSJ loads a tileset saved in the JSON format (.tsj or .json) into a Tileset struct. Images are resolved
+// relative to the directory of the tileset file.
+func LoadTSJ(source string) (*Tileset, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadTSJResolver(OSResolver{}, absSource)
+}
+
+// LoadTSJBytes loads a tileset saved in the JSON format into a Tileset struct. Images are resolved relative to the
+// current working directory.
+func LoadTSJBytes(bytes []byte) (*Tileset, error) {
+	l := newLoader(OSResolver{}, "")
+
+	return l.loadTSJ(bytes)
+}
+
+// LoadTSJFS loads a tileset saved in the JSON format from a file system, such as an embed.FS or a zip.Reader, into a
+// Tileset struct. Images are resolved relative to the tileset within the same file system.
+func LoadTSJFS(fsys fs.FS, name string) (*Tileset, error) {
+	return LoadTSJResolver(FSResolver{FS: fsys}, name)
+}
+
+// LoadTSJResolver loads a tileset saved in the JSON format opened with a Resolver into a Tileset struct. Images are
+// resolved with the same Resolver.
+func LoadTSJResolver(resolver Resolver, name string) (*Tileset, error) {
+	tsjBytes, err := readFile(resolver, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tsj file: %w", err)
+	}
+
+	l := newLoader(resolver, name)
+
+	tileset, err := l.loadTSJ(tsjBytes)
+	if err != nil {
+		return nil, err
+	}
+	tileset.Path = name
+
+	return tileset, nil
+}
+
+// loadTSJ unmarshals the json bytes and resolves the images referenced by the tileset.
+func (l *loader) loadTSJ(data []byte) (*Tileset, error) {
+	tileset, err := unmarshalTSJ(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.resolveTileset(tileset, l.base)
+	if err != nil {
+		return nil, err
+	}
+
+	return tileset, nil
+}
+
+// unmarshalTSJ unmarshals the json bytes of a tileset.
+func unmarshalTSJ(data []byte) (*Tileset, error) {
+	jt := &jsonTileset{}
+
+	err := json.Unmarshal(data, jt)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling tsj bytes: %w", err)
+	}
+
+	tileset, err := jt.tileset()
+	if err != nil {
+		return nil, fmt.Errorf("error converting tsj tileset: %w", err)
+	}
+
+	return tileset, nil
+}
+
+// unmarshalTJ unmarshals the json bytes of an object template.
+func unmarshalTJ(data []byte) (*Template, error) {
+	jt := &jsonTemplate{}
+
+	err := json.Unmarshal(data, jt)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling template bytes: %w", err)
+	}
+
+	template := &Template{}
+
+	if jt.Tileset != nil {
+		template.Tileset, err = jt.Tileset.tileset()
+		if err != nil {
+			return nil, fmt.Errorf("error converting template tileset: %w", err)
+		}
+	}
+
+	if jt.Object != nil {
+		template.Object = jt.Object.object()
+	}
+
+	return template, nil
+}
+
+// jsonTemplate structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#object-template
+type jsonTemplate struct {
+	Tileset *jsonTileset `json:"tileset"`
+	Object  *jsonObject  `json:"object"`
+}
+
+// jsonTileset structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#tileset
+type jsonTileset struct {
+	FirstGID         int            `json:"firstgid"`
+	Source           string         `json:"source"`
+	Version          jsonVersion    `json:"version"`
+	TiledVersion     string         `json:"tiledversion"`
+	Name             string         `json:"name"`
+	TileWidth        int            `json:"tilewidth"`
+	TileHeight       int            `json:"tileheight"`
+	Spacing          int            `json:"spacing"`
+	Margin           int            `json:"margin"`
+	TileCount        int            `json:"tilecount"`
+	Columns          int            `json:"columns"`
+	Image            string         `json:"image"`
+	ImageWidth       int            `json:"imagewidth"`
+	ImageHeight      int            `json:"imageheight"`
+	TransparentColor string         `json:"transparentcolor"`
+	TileOffset       *TileOffset    `json:"tileoffset"`
+	Grid             *Grid          `json:"grid"`
+	Terrains         []*jsonTerrain `json:"terrains"`
+	Tiles            []*jsonTile    `json:"tiles"`
+	Wangsets         []*jsonWangset `json:"wangsets"`
+	Properties       jsonProperties `json:"properties"`
+}
+
+// tileset converts the json tileset to a Tileset.
+func (j *jsonTileset) tileset() (*Tileset, error) {
+	t := &Tileset{
+		FirstGID:     j.FirstGID,
+		Source:       j.Source,
+		Version:      string(j.Version),
+		TiledVersion: j.TiledVersion,
+		Name:         j.Name,
+		TileWidth:    j.TileWidth,
+		TileHeight:   j.TileHeight,
+		Spacing:      j.Spacing,
+		Margin:       j.Margin,
+		TileCount:    j.TileCount,
+		Columns:      j.Columns,
+		TileOffset:   j.TileOffset,
+		Grid:         j.Grid,
+		Properties:   j.Properties.properties(),
+	}
+
+	if j.Image != "" {
+		t.Image = &Image{
+			Source: j.Image,
+			Trans:  strings.TrimPrefix(j.TransparentColor, "#"),
+			Width:  j.ImageWidth,
+			Height: j.ImageHeight,
+		}
+	}
+
+	if len(j.Terrains) > 0 {
+		t.TerrainTypes = &TerrainTypes{}
+		for _, jt := range j.Terrains {
+			t.TerrainTypes.Terrain = append(t.TerrainTypes.Terrain, &Terrain{
+				Name:       jt.Name,
+				Tile:       jt.Tile,
+				Properties: jt.Properties.properties(),
+			})
+		}
+	}
+
+	for _, jt := range j.Tiles {
+		t.Tile = append(t.Tile, jt.tile())
+	}
+
+	if len(j.Wangsets) > 0 {
+		t.Wangsets = &Wangsets{}
+		for _, jw := range j.Wangsets {
+			t.Wangsets.Wangset = append(t.Wangsets.Wangset, jw.wangset())
+		}
+	}
+
+	t.indexTiles()
+
+	return t, nil
+}
+
+// jsonTerrain structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#terrain
+type jsonTerrain struct {
+	Name       string         `json:"name"`
+	Tile       int            `json:"tile"`
+	Properties jsonProperties `json:"properties"`
+}
+
+// jsonWangset structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#wang-set
+type jsonWangset struct {
+	Name         string           `json:"name"`
+	Class        string           `json:"class"`
+	Type         string           `json:"type"`
+	Tile         int              `json:"tile"`
+	Colors       []*jsonWangColor `json:"colors"`
+	CornerColors []*jsonWangColor `json:"cornercolors"`
+	EdgeColors   []*jsonWangColor `json:"edgecolors"`
+	WangTiles    []*jsonWangTile  `json:"wangtiles"`
+	Properties   jsonProperties   `json:"properties"`
+}
+
+// jsonWangColor structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#wang-color
+type jsonWangColor struct {
+	Name        string         `json:"name"`
+	Class       string         `json:"class"`
+	Color       string         `json:"color"`
+	Tile        int            `json:"tile"`
+	Probability float64        `json:"probability"`
+	Properties  jsonProperties `json:"properties"`
+}
+
+// UnmarshalJSON is called by Unmarshal to produce the value from the json object.
+func (j *jsonWangColor) UnmarshalJSON(data []byte) error {
+	type wangColor jsonWangColor
+
+	// Colors have no tile and compete equally unless the object says otherwise.
+	j.Tile = -1
+	j.Probability = 1
+
+	return json.Unmarshal(data, (*wangColor)(j))
+}
+
+// jsonWangTile structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#wang-tile
+type jsonWangTile struct {
+	TileID int   `json:"tileid"`
+	WangID []int `json:"wangid"`
+	HFlip  bool  `json:"hflip"`
+	VFlip  bool  `json:"vflip"`
+	DFlip  bool  `json:"dflip"`
+}
+
+// wangset converts the json Wang set to a Wangset, upgrading Wang sets saved before Tiled 1.5 the same way the TMX
+// format is upgraded.
+func (j *jsonWangset) wangset() *Wangset {
+	w := &Wangset{
+		Name:       j.Name,
+		Class:      j.Class,
+		Type:       j.Type,
+		Tile:       j.Tile,
+		Properties: j.Properties.properties(),
+	}
+
+	for _, jc := range j.Colors {
+		w.WangColor = append(w.WangColor, &WangColor{
+			Name:        jc.Name,
+			Class:       jc.Class,
+			Color:       jc.Color,
+			Tile:        jc.Tile,
+			Probability: jc.Probability,
+			Properties:  jc.Properties.properties(),
+		})
+	}
+	for _, jc := range j.CornerColors {
+		w.WangCornerColor = append(w.WangCornerColor, &WangCornerColor{
+			Name:        jc.Name,
+			Color:       jc.Color,
+			Tile:        jc.Tile,
+			Probability: jc.Probability,
+		})
+	}
+	for _, jc := range j.EdgeColors {
+		w.WangEdgeColor = append(w.WangEdgeColor, &WangEdgeColor{
+			Name:        jc.Name,
+			Color:       jc.Color,
+			Tile:        jc.Tile,
+			Probability: jc.Probability,
+		})
+	}
+
+	for _, jt := range j.WangTiles {
+		tile := &WangTile{TileID: jt.TileID, HFlip: jt.HFlip, VFlip: jt.VFlip, DFlip: jt.DFlip}
+		copy(tile.WangID[:], jt.WangID)
+		w.WangTile = append(w.WangTile, tile)
+	}
+
+	w.upgrade()
+
+	return w
+}
+
+// jsonTile structure: https://doc.mapeditor.org/en/stable/reference/json-map-format/#tile-definition
+type jsonTile struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Class       string         `json:"class"`
+	Terrain     []int          `json:"terrain"`
+	Probability float32        `json:"probability"`
+	Image       string         `json:"image"`
+	ImageWidth  int            `json:"imagewidth"`
+	ImageHeight int            `json:"imageheight"`
+	ObjectGroup *jsonLayer     `json:"objectgroup"`
+	Animation   []*Frame       `json:"animation"`
+	Properties  jsonProperties `json:"properties"`
+}
+
+// UnmarshalJSON is called by Unmarshal to produce the value from the json object.
+func (j *jsonTile) UnmarshalJSON(data []byte) error {
+	type tile jsonTile
+
+	// Tiles compete equally unless the probability says otherwise.
+	j.Probability = 1
+
+	return json.Unmarshal(data, (*tile)(j))
+}
+
+// tile converts the json tile to a Tile.
+func (j *jsonTile) tile() *Tile {
+	t := &Tile{
+		ID:          j.ID,
+		Type:        j.Type,
+		Probability: j.Probability,
+		Properties:  j.Properties.properties(),
+	}
+
+	// Tiled 1.9 renamed type to class.
+	if j.Class != "" {
+		t.Type = j.Class
+	}
+
+	// Terrain corners are saved as indexes with -1 for no terrain, the TMX format leaves those out.
+	if len(j.Terrain) > 0 {
+		corners := TerrainCorners{-1, -1, -1, -1}
+		copy(corners[:], j.Terrain)
+		t.SetTerrainCorners(corners)
+	}
+
+	if j.Image != "" {
+		t.Image = &Image{Source: j.Image, Width: j.ImageWidth, Height: j.ImageHeight}
+	}
+
+	if j.ObjectGroup != nil {
+		t.ObjectGroup = []*ObjectGroup{j.ObjectGroup.objectGroup()}
+	}
+
+	if len(j.Animation) > 0 {
+		t.Animation = &Animation{Frame: j.Animation}
+	}
+
+	return t
+}
+
+// WriteTSJ writes the tileset in the JSON format, without the firstgid and source values that only belong in a map.
+func (t *Tileset) WriteTSJ(w io.Writer) error {
+	err := writeJSON(w, jsonTilesetValue(t))
+	if err != nil {
+		return fmt.Errorf("error writing tsj: %w", err)
+	}
+
+	return nil
+}
+
+// SaveTSJ writes the tileset in the JSON format to a file. Image sources are written as they were loaded, so the file
+// should be saved next to the original tileset to keep relative paths working.
+func (t *Tileset) SaveTSJ(name string) error {
+	var b bytes.Buffer
+
+	err := t.WriteTSJ(&b)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(name, b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing tsj file: %w", err)
+	}
+
+	return nil
+}
+
+// jsonMapTilesetValue converts a tileset of a map to a json tileset, a tileset stored in an external file is written
+// as a reference to that file.
+func jsonMapTilesetValue(t *Tileset) jsonObjectValue {
+	if t.IsExternal() {
+		return jsonObjectValue{
+			"firstgid": t.FirstGID,
+			"source":   t.Source,
+		}
+	}
+
+	v := jsonTilesetValue(t)
+	v["firstgid"] = t.FirstGID
+	delete(v, "type")
+	delete(v, "version")
+	delete(v, "tiledversion")
+
+	return v
+}
+
+// jsonTilesetValue converts a tileset to a json tileset.
+func jsonTilesetValue(t *Tileset) jsonObjectValue {
+	v := jsonObjectValue{
+		"type":       "tileset",
+		"name":       t.Name,
+		"tilewidth":  t.TileWidth,
+		"tileheight": t.TileHeight,
+		"spacing":    t.Spacing,
+		"margin":     t.Margin,
+		"tilecount":  t.TileCount,
+		"columns":    t.Columns,
+	}
+	v.setNotEmpty("version", t.Version)
+	v.setNotEmpty("tiledversion", t.TiledVersion)
+	v.setNotEmpty("properties", jsonPropertiesValue(t.Properties))
+
+	if t.Image != nil {
+		v["image"] = t.Image.Source
+		v["imagewidth"] = t.Image.Width
+		v["imageheight"] = t.Image.Height
+		if t.Image.Trans != "" {
+			v["transparentcolor"] = "#" + strings.TrimPrefix(t.Image.Trans, "#")
+		}
+	}
+
+	if t.TileOffset != nil {
+		v["tileoffset"] = jsonObjectValue{"x": t.TileOffset.X, "y": t.TileOffset.Y}
+	}
+
+	if t.Grid != nil {
+		v["grid"] = jsonObjectValue{
+			"orientation": t.Grid.Orientation,
+			"width":       t.Grid.Width,
+			"height":      t.Grid.Height,
+		}
+	}
+
+	if t.TerrainTypes != nil && len(t.TerrainTypes.Terrain) > 0 {
+		terrains := make([]jsonObjectValue, 0, len(t.TerrainTypes.Terrain))
+		for _, terrain := range t.TerrainTypes.Terrain {
+			tv := jsonObjectValue{"name": terrain.Name, "tile": terrain.Tile}
+			tv.setNotEmpty("properties", jsonPropertiesValue(terrain.Properties))
+			terrains = append(terrains, tv)
+		}
+		v["terrains"] = terrains
+	}
+
+	if len(t.Tile) > 0 {
+		tiles := make([]jsonObjectValue, 0, len(t.Tile))
+		for _, tile := range t.Tile {
+			tiles = append(tiles, jsonTileValue(tile))
+		}
+		v["tiles"] = tiles
+	}
+
+	if t.Wangsets != nil && len(t.Wangsets.Wangset) > 0 {
+		wangsets := make([]jsonObjectValue, 0, len(t.Wangsets.Wangset))
+		for _, wangset := range t.Wangsets.Wangset {
+			wangsets = append(wangsets, jsonWangsetValue(wangset))
+		}
+		v["wangsets"] = wangsets
+	}
+
+	return v
+}
+
+// jsonWangsetValue converts a Wang set to a json Wang set, always in the format of Tiled 1.5 and later.
+func jsonWangsetValue(w *Wangset) jsonObjectValue {
+	v := jsonObjectValue{
+		"name": w.Name,
+		"type": w.Type,
+		"tile": w.Tile,
+	}
+	v.setNotEmpty("class", w.Class)
+	v.setNotEmpty("properties", jsonPropertiesValue(w.Properties))
+
+	colors := make([]jsonObjectValue, 0, len(w.WangColor))
+	for _, color := range w.WangColor {
+		cv := jsonObjectValue{
+			"name":        color.Name,
+			"color":       color.Color,
+			"tile":        color.Tile,
+			"probability": color.Probability,
+		}
+		cv.setNotEmpty("class", color.Class)
+		cv.setNotEmpty("properties", jsonPropertiesValue(color.Properties))
+		colors = append(colors, cv)
+	}
+	v["colors"] = colors
+
+	tiles := make([]jsonObjectValue, 0, len(w.WangTile))
+	for _, tile := range w.WangTile {
+		tv := jsonObjectValue{"tileid": tile.TileID, "wangid": tile.WangID[:]}
+		if tile.HFlip {
+			tv["hflip"] = true
+		}
+		if tile.VFlip {
+			tv["vflip"] = true
+		}
+		if tile.DFlip {
+			tv["dflip"] = true
+		}
+		tiles = append(tiles, tv)
+	}
+	v["wangtiles"] = tiles
+
+	return v
+}
+
+// jsonTileValue converts a tile to a json tile.
+func jsonTileValue(t *Tile) jsonObjectValue {
+	v := jsonObjectValue{"id": t.ID}
+	v.setNotEmpty("type", t.Type)
+	v.setNotEmpty("properties", jsonPropertiesValue(t.Properties))
+
+	if t.Probability != 1 {
+		v["probability"] = t.Probability
+	}
+
+	// Terrain corners left out in the TMX format are saved as -1.
+	if corners, err := t.TerrainCorners(); err == nil && !corners.Empty() {
+		v["terrain"] = corners[:]
+	}
+
+	if t.Image != nil {
+		v["image"] = t.Image.Source
+		v["imagewidth"] = t.Image.Width
+		v["imageheight"] = t.Image.Height
+	}
+
+	if len(t.ObjectGroup) > 0 {
+		v["objectgroup"] = jsonObjectGroupValue(t.ObjectGroup[0])
+	}
+
+	if t.Animation != nil && len(t.Animation.Frame) > 0 {
+		frames := make([]jsonObjectValue, 0, len(t.Animation.Frame))
+		for _, frame := range t.Animation.Frame {
+			frames = append(frames, jsonObjectValue{"tileid": frame.TileID, "duration": frame.Duration})
+		}
+		v["animation"] = frames
+	}
+
+	return v
+}
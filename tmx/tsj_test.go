@@ -0,0 +1,224 @@
+package tmx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+// detailedTSJ is a tileset in the JSON format with tile content, a terrain and a Wang set, as saved by Tiled.
+const detailedTSJ = `{
+ "columns": 2,
+ "grid": {"height": 1, "orientation": "orthogonal", "width": 1},
+ "image": "details.png",
+ "imageheight": 37,
+ "imagewidth": 37,
+ "margin": 2,
+ "name": "details",
+ "properties": [{"name": "biome", "type": "string", "value": "forest"}],
+ "spacing": 1,
+ "tilecount": 4,
+ "tiledversion": "1.10.2",
+ "tileheight": 16,
+ "tileoffset": {"x": 2, "y": -4},
+ "tiles": [
+  {"id": 1, "type": "water", "probability": 0.5,
+   "properties": [{"name": "deep", "type": "bool", "value": true}],
+   "objectgroup": {"draworder": "index", "id": 2, "name": "", "opacity": 1, "type": "objectgroup", "visible": true,
+    "x": 0, "y": 0, "objects": [{"id": 1, "x": 0, "y": 8, "width": 16, "height": 8, "rotation": 0, "name": "",
+    "type": "", "visible": true}]},
+   "animation": [{"tileid": 1, "duration": 100}, {"tileid": 3, "duration": 200}]},
+  {"id": 2, "class": "grass"}
+ ],
+ "tilewidth": 16,
+ "transparentcolor": "#ff00ff",
+ "type": "tileset",
+ "version": "1.10",
+ "wangsets": [
+  {"name": "terrain", "class": "ground", "type": "corner", "tile": 2,
+   "colors": [
+    {"name": "grass", "color": "#00ff00", "tile": 2, "probability": 1},
+    {"name": "water", "color": "#0000ff", "probability": 0.5,
+     "properties": [{"name": "swim", "type": "bool", "value": true}]}
+   ],
+   "wangtiles": [
+    {"tileid": 0, "wangid": [0, 1, 0, 1, 0, 1, 0, 1]},
+    {"tileid": 1, "wangid": [0, 2, 0, 2, 0, 2, 0, 2], "hflip": true}
+   ]}
+ ]
+}`
+
+func TestLoadTSJ(t *testing.T) {
+	files := MapResolver{"tilesets/details.tsj": []byte(detailedTSJ)}
+
+	tileset, err := LoadTSJResolver(files, "tilesets/details.tsj")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tileset.Path != "tilesets/details.tsj" || tileset.Name != "details" || tileset.Version != "1.10" ||
+		tileset.TileCount != 4 || tileset.Columns != 2 || tileset.Margin != 2 || tileset.Spacing != 1 {
+		t.Errorf("tileset %q at %q version %q with %d tiles", tileset.Name, tileset.Path, tileset.Version,
+			tileset.TileCount)
+	}
+	if tileset.Image == nil || tileset.Image.Path != "tilesets/details.png" || tileset.Image.Trans != "ff00ff" ||
+		tileset.Image.Width != 37 {
+		t.Errorf("tileset image %v, want details.png with a transparent color", tileset.Image)
+	}
+	if tileset.TileOffset == nil || tileset.TileOffset.Y != -4 || tileset.Grid == nil || tileset.Grid.Width != 1 {
+		t.Errorf("tile offset %v and grid %v", tileset.TileOffset, tileset.Grid)
+	}
+	if biome := tileset.Properties.Property("biome"); biome == nil || biome.Value != "forest" {
+		t.Errorf("tileset property biome %v, want forest", biome)
+	}
+
+	water := tileset.TileByID(1)
+	if water == nil || water.Type != "water" || water.Probability != 0.5 {
+		t.Fatalf("tile 1 is %v, want the water tile", water)
+	}
+	if deep := water.Properties.Property("deep"); deep == nil || deep.Type != PropertyTypeBool || deep.Value != "true" {
+		t.Errorf("tile property deep %v, want a bool", deep)
+	}
+	if len(water.ObjectGroup) != 1 || len(water.ObjectGroup[0].Object) != 1 ||
+		water.ObjectGroup[0].Object[0].Height != 8 || water.ObjectGroup[0].DrawOrder != "index" {
+		t.Errorf("tile collision objects were not loaded")
+	}
+	if water.Animation == nil || len(water.Animation.Frame) != 2 || water.Animation.Frame[1].TileID != 3 {
+		t.Errorf("tile animation %v, want two frames", water.Animation)
+	}
+
+	// Tiled 1.9 renamed the type of tiles to class, tiles without a probability compete equally.
+	grass := tileset.TileByID(2)
+	if grass == nil || grass.Type != "grass" || grass.Probability != 1 {
+		t.Errorf("tile 2 is %v, want grass with probability 1", grass)
+	}
+}
+
+func TestLoadTSJWangsets(t *testing.T) {
+	tileset, err := LoadTSJBytes([]byte(detailedTSJ))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tileset.Wangsets == nil || len(tileset.Wangsets.Wangset) != 1 {
+		t.Fatal("tileset does not have a Wang set")
+	}
+	w := tileset.Wangsets.Wangset[0]
+	if w.Name != "terrain" || w.Class != "ground" || w.Type != WangsetCorner || w.Tile != 2 || len(w.WangColor) != 2 {
+		t.Fatalf("Wang set %q of class %q and type %q with %d colors", w.Name, w.Class, w.Type, len(w.WangColor))
+	}
+
+	// Colors without a tile or probability get the defaults of the TMX format.
+	grass, water := w.WangColor[0], w.WangColor[1]
+	if grass.Name != "grass" || grass.Color != "#00ff00" || grass.Tile != 2 || grass.Probability != 1 {
+		t.Errorf("color 1 is %+v, want grass", grass)
+	}
+	if water.Tile != -1 || water.Probability != 0.5 || water.Properties.Property("swim") == nil {
+		t.Errorf("color 2 is %+v, want water without a tile", water)
+	}
+
+	if len(w.WangTile) != 2 {
+		t.Fatalf("Wang set has %d tiles, want 2", len(w.WangTile))
+	}
+	if tile := w.WangTile[1]; tile.TileID != 1 || tile.WangID != (WangID{0, 2, 0, 2, 0, 2, 0, 2}) || !tile.HFlip {
+		t.Errorf("Wang tile 1 is %+v, want tile 1 with water corners flipped horizontally", tile)
+	}
+}
+
+func TestLoadTSJFiles(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "details.tsj")
+	err := os.WriteFile(name, []byte(detailedTSJ), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Images are resolved next to the tileset file.
+	tileset, err := LoadTSJ(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tileset.Path != name || tileset.Image.Path != filepath.Join(dir, "details.png") {
+		t.Errorf("tileset at %q has image %q", tileset.Path, tileset.Image.Path)
+	}
+
+	fsys := fstest.MapFS{"tilesets/details.tsj": &fstest.MapFile{Data: []byte(detailedTSJ)}}
+	tileset, err = LoadTSJFS(fsys, "tilesets/details.tsj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tileset.Image.Path != "tilesets/details.png" {
+		t.Errorf("tileset image %q, want tilesets/details.png", tileset.Image.Path)
+	}
+
+	for _, data := range []string{`{"name": `, `{"tilecount": "4"}`} {
+		if _, err := LoadTSJBytes([]byte(data)); err == nil {
+			t.Errorf("loading %s did not return an error", data)
+		}
+	}
+	if _, err := LoadTSJFS(fsys, "tilesets/missing.tsj"); err == nil {
+		t.Error("loading a missing tileset did not return an error")
+	}
+}
+
+func TestLoadTMJTemplates(t *testing.T) {
+	files := tmjFiles()
+	files["templates/chest.tj"] = []byte(`{
+ "type": "template",
+ "tileset": {"firstgid": 1, "source": "../tilesets/items.tsj"},
+ "object": {"gid": 2, "height": 16, "id": 0, "name": "chest", "rotation": 0, "type": "container", "visible": true,
+  "width": 16, "properties": [{"name": "loot", "type": "string", "value": "gold"}]}
+}`)
+	files["templates/zone.tj"] = []byte(`{
+ "type": "template",
+ "object": {"name": "zone", "width": 0, "height": 0, "rotation": 0, "visible": true,
+  "polygon": [{"x": 0, "y": 0}, {"x": 32, "y": 0}, {"x": 32, "y": 16}]}
+}`)
+	files["tilesets/items.tsj"] = []byte(`{"name": "items", "tilewidth": 16, "tileheight": 16, "tilecount": 4,
+"columns": 4, "image": "items.png", "imagewidth": 64, "imageheight": 16, "type": "tileset"}`)
+	files["maps/level.tmx"] = []byte(`<map version="1.10" orientation="orthogonal" width="2" height="2" tilewidth="16"
+ tileheight="16">
+ <tileset firstgid="1" source="../tilesets/terrain.tsx"/>
+ <objectgroup id="1" name="objects">
+  <object id="1" template="../templates/chest.tj" x="8" y="24"/>
+  <object id="2" template="../templates/zone.tj" x="0" y="0"/>
+ </objectgroup>
+</map>`)
+
+	tmx, err := LoadTMXResolver(files, "maps/level.tmx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objects := tmx.Map.Content[1].Value.(*ObjectGroup).Object
+	chest, zone := objects[0], objects[1]
+	if chest.Name != "chest" || chest.Type != "container" || chest.Width != 16 || chest.X != 8 {
+		t.Errorf("template instance %q %q %vx%v at %v, want the values of the template", chest.Name, chest.Type,
+			chest.Width, chest.Height, chest.X)
+	}
+	if loot := chest.Properties.Property("loot"); loot == nil || loot.Value != "gold" {
+		t.Errorf("template property loot %v, want gold", loot)
+	}
+	if chest.TemplateRef == nil || chest.TemplateRef.Path != "templates/chest.tj" {
+		t.Error("template instance does not link to the template loaded from templates/chest.tj")
+	}
+
+	// The json tileset of the template follows the tilesets of the map in its GID space.
+	ref, err := tmx.ResolveGID(chest.GID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chest.GID != 6 || ref.Tileset.Name != "items" || ref.ID != 1 || ref.Image.Path != "tilesets/items.png" {
+		t.Errorf("template gid %d is tile %d of %q, want gid 6, tile 1 of items", chest.GID, ref.ID, ref.Tileset.Name)
+	}
+
+	if zone.Name != "zone" || len(zone.Polygon) != 1 || len(zone.Polygon[0].Points) != 3 {
+		t.Errorf("template instance %q has polygon %v, want the polygon of the template", zone.Name, zone.Polygon)
+	}
+
+	files["templates/zone.tj"] = []byte(`{"object": `)
+	if _, err := LoadTMXResolver(files, "maps/level.tmx"); err == nil {
+		t.Error("loading a map with a broken json template did not return an error")
+	}
+}
@@ -0,0 +1,118 @@
+package tmx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// LoadTSX loads the xml of a tsx file into a Tileset struct. Images are resolved relative to the directory of the tsx
+// file.
+func LoadTSX(source string) (*Tileset, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadTSXResolver(OSResolver{}, absSource)
+}
+
+// LoadTSXBytes loads the xml of a tsx file into a Tileset struct. Images are resolved relative to the current working
+// directory.
+func LoadTSXBytes(bytes []byte) (*Tileset, error) {
+	l := newLoader(OSResolver{}, "")
+
+	return l.loadTSX(bytes)
+}
+
+// LoadTSXFS loads the xml of a tsx file from a file system, such as an embed.FS or a zip.Reader, into a Tileset
+// struct. Images are resolved relative to the tsx file within the same file system.
+func LoadTSXFS(fsys fs.FS, name string) (*Tileset, error) {
+	return LoadTSXResolver(FSResolver{FS: fsys}, name)
+}
+
+// LoadTSXResolver loads the xml of a tsx file opened with a Resolver into a Tileset struct. Images are resolved with
+// the same Resolver.
+func LoadTSXResolver(resolver Resolver, name string) (*Tileset, error) {
+	tsxBytes, err := readFile(resolver, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading tsx file: %w", err)
+	}
+
+	l := newLoader(resolver, name)
+
+	tileset, err := l.loadTSX(tsxBytes)
+	if err != nil {
+		return nil, err
+	}
+	tileset.Path = name
+
+	return tileset, nil
+}
+
+// loadTSX unmarshals the tsx bytes and resolves the images referenced by the tileset.
+func (l *loader) loadTSX(bytes []byte) (*Tileset, error) {
+	tileset := &Tileset{}
+
+	err := xml.Unmarshal(bytes, tileset)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling tsx bytes: %w", err)
+	}
+
+	err = l.resolveTileset(tileset, l.base)
+	if err != nil {
+		return nil, err
+	}
+
+	return tileset, nil
+}
+
+// WriteTSX writes the tileset as tsx xml, without the firstgid and source attributes that only belong in a map.
+func (t *Tileset) WriteTSX(w io.Writer) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("error writing tsx: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", " ")
+
+	err = t.encode(encoder, false)
+	if err != nil {
+		return fmt.Errorf("error marshaling tsx: %w", err)
+	}
+
+	err = encoder.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing tsx: %w", err)
+	}
+
+	_, err = io.WriteString(w, "\n")
+	if err != nil {
+		return fmt.Errorf("error writing tsx: %w", err)
+	}
+
+	return nil
+}
+
+// SaveTSX writes the tileset as tsx xml to a file. Image sources are written as they were loaded, so the file should
+// be saved next to the original tileset to keep relative paths working.
+func (t *Tileset) SaveTSX(name string) error {
+	var b bytes.Buffer
+
+	err := t.WriteTSX(&b)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(name, b.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing tsx file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package tmx
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// detailedTileset has a tile offset, properties and tiles with properties, collision objects and an animation.
+const detailedTileset = `<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="details" tilewidth="16" tileheight="16" spacing="1" margin="2"
+ tilecount="4" columns="2">
+ <tileoffset x="2" y="-4"/>
+ <properties>
+  <property name="biome" value="forest"/>
+ </properties>
+ <image source="details.png" trans="ff00ff" width="37" height="37"/>
+ <tile id="1" type="water" probability="0.5">
+  <properties>
+   <property name="deep" type="bool" value="true"/>
+  </properties>
+  <objectgroup draworder="index" id="2">
+   <object id="1" x="0" y="8" width="16" height="8"/>
+  </objectgroup>
+  <animation>
+   <frame tileid="1" duration="100"/>
+   <frame tileid="3" duration="200"/>
+  </animation>
+ </tile>
+</tileset>`
+
+// roundTripTSX writes a tileset with WriteTSX and loads the written tsx next to the original.
+func roundTripTSX(t *testing.T, files MapResolver, name string, original *Tileset) (*Tileset, string) {
+	t.Helper()
+
+	var b bytes.Buffer
+	err := original.WriteTSX(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files[name] = b.Bytes()
+	loaded, err := LoadTSXResolver(files, name)
+	if err != nil {
+		t.Fatalf("loading the written tileset: %v\n%s", err, b.String())
+	}
+	return loaded, b.String()
+}
+
+func TestWriteTSXRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   MapResolver
+		path    string
+		images  []string
+		written []string
+	}{
+		{
+			name:   "testdata",
+			files:  testdataFiles(t),
+			path:   "tileset.tsx",
+			images: []string{"peacock-feathers-3013486_640.jpg"},
+		},
+		{
+			name:   "details",
+			files:  MapResolver{"tilesets/details.tsx": []byte(detailedTileset)},
+			path:   "tilesets/details.tsx",
+			images: []string{"tilesets/details.png"},
+			written: []string{`<tileoffset x="2" y="-4">`, `trans="ff00ff"`, `probability="0.5"`,
+				`<frame tileid="3" duration="200">`, `<objectgroup id="2" draworder="index">`},
+		},
+		{
+			name:    "image collection",
+			files:   MapResolver{"tilesets/props.tsx": []byte(collectionTileset)},
+			path:    "tilesets/props.tsx",
+			images:  []string{"tilesets/props/barrel.png", "tilesets/props/tree.png"},
+			written: []string{`columns="0"`, `<tile id="4" type="tree">`, `source="props/tree.png"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original, err := LoadTSXResolver(test.files, test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The written tileset replaces the original so its images resolve the same way.
+			loaded, written := roundTripTSX(t, test.files, test.path, original)
+			if strings.Contains(written, "firstgid") || strings.Contains(written, " source=\"tileset") {
+				t.Errorf("tsx is written as the tileset of a map:\n%s", written)
+			}
+			for _, want := range test.written {
+				if !strings.Contains(written, want) {
+					t.Errorf("written tileset does not contain %s:\n%s", want, written)
+				}
+			}
+
+			if loaded.Name != original.Name || loaded.TileWidth != original.TileWidth ||
+				loaded.TileCount != original.TileCount || loaded.Columns != original.Columns ||
+				loaded.Margin != original.Margin || loaded.Spacing != original.Spacing ||
+				len(loaded.Tile) != len(original.Tile) {
+				t.Errorf("loaded %q with %d tiles in %d columns, want %q with %d tiles in %d columns", loaded.Name,
+					loaded.TileCount, loaded.Columns, original.Name, original.TileCount, original.Columns)
+			}
+
+			var images []string
+			if loaded.Image != nil {
+				images = append(images, loaded.Image.Path)
+			}
+			for _, tile := range loaded.Tile {
+				if tile.Image != nil {
+					images = append(images, tile.Image.Path)
+				}
+			}
+			if strings.Join(images, ",") != strings.Join(test.images, ",") {
+				t.Errorf("images %v, want %v", images, test.images)
+			}
+
+			// Writing the loaded tileset again produces the same xml.
+			_, again := roundTripTSX(t, test.files, test.path, loaded)
+			if again != written {
+				t.Errorf("written twice, the tilesets differ:\n%s\n%s", written, again)
+			}
+		})
+	}
+}
+
+func TestWriteTSXTiles(t *testing.T) {
+	tileset, err := LoadTSXBytes([]byte(detailedTileset))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := roundTripTSX(t, MapResolver{}, "details.tsx", tileset)
+
+	tile := loaded.TileByID(1)
+	if tile == nil || tile.Type != "water" || tile.Probability != 0.5 {
+		t.Fatalf("tile 1 is %v, want the water tile", tile)
+	}
+	if deep, err := tile.Properties.Bool("deep"); err != nil || !deep {
+		t.Errorf("tile property deep = %t, %v, want true", deep, err)
+	}
+	if tile.Animation == nil || len(tile.Animation.Frame) != 2 || tile.Animation.Frame[1].Duration != 200 {
+		t.Errorf("tile animation %v, want two frames", tile.Animation)
+	}
+	if len(tile.ObjectGroup) != 1 || len(tile.ObjectGroup[0].Object) != 1 || tile.ObjectGroup[0].Object[0].Y != 8 {
+		t.Errorf("tile collision objects were not written")
+	}
+	if loaded.TileOffset == nil || loaded.TileOffset.X != 2 || loaded.TileOffset.Y != -4 {
+		t.Errorf("tile offset %v, want 2,-4", loaded.TileOffset)
+	}
+	if biome, err := loaded.Properties.StringValue("biome"); err != nil || biome != "forest" {
+		t.Errorf("tileset property biome = %q, %v, want forest", biome, err)
+	}
+}
+
+func TestSaveTSX(t *testing.T) {
+	original, err := LoadTSX("../examples/testdata/tileset.tsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := filepath.FromSlash("examples/testdata/peacock-feathers-3013486_640.jpg")
+	if !strings.HasSuffix(original.Image.Path, image) {
+		t.Errorf("image path %q, want the image next to the tileset", original.Image.Path)
+	}
+
+	name := filepath.Join(t.TempDir(), "tileset.tsx")
+	err = original.SaveTSX(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Images are resolved next to the saved file.
+	loaded, err := LoadTSX(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Path != name || loaded.TileCount != 1040 || loaded.Columns != 40 ||
+		loaded.Image.Path != filepath.Join(filepath.Dir(name), "peacock-feathers-3013486_640.jpg") {
+		t.Errorf("saved tileset %q has %d tiles in %d columns and image %q", loaded.Path, loaded.TileCount,
+			loaded.Columns, loaded.Image.Path)
+	}
+
+	err = original.SaveTSX(filepath.Join(t.TempDir(), "missing", "tileset.tsx"))
+	if err == nil {
+		t.Error("saving into a missing directory did not return an error")
+	}
+}
+
+func TestLoadTSXFS(t *testing.T) {
+	fsys := fstest.MapFS{"tilesets/props.tsx": &fstest.MapFile{Data: []byte(collectionTileset)}}
+
+	tileset, err := LoadTSXFS(fsys, "tilesets/props.tsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tileset.Path != "tilesets/props.tsx" || tileset.TileByID(4) == nil ||
+		tileset.TileByID(4).Image.Path != "tilesets/props/tree.png" {
+		t.Errorf("tileset %q was not loaded with its images resolved in the file system", tileset.Path)
+	}
+
+	_, err = LoadTSXFS(fsys, "tilesets/missing.tsx")
+	if err == nil {
+		t.Error("loading a missing tileset did not return an error")
+	}
+	_, err = LoadTSXBytes([]byte("<tileset"))
+	if err == nil {
+		t.Error("loading broken xml did not return an error")
+	}
+}
@@ -0,0 +1,29 @@
+package tmx
+
+import (
+	"math"
+)
+
+// Vertex is a position in pixels, such as a point of a polygon or a corner of an object.
+type Vertex struct {
+	X float64
+	Y float64
+}
+
+// rotate rotates the vertex clockwise around the origin by degrees, clockwise since the y axis points down.
+func (v Vertex) rotate(degrees float64) Vertex {
+	if degrees == 0 {
+		return v
+	}
+
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+
+	return Vertex{
+		X: v.X*cos - v.Y*sin,
+		Y: v.X*sin + v.Y*cos,
+	}
+}
+
+func (v Vertex) String() string {
+	return formatFloat(v.X) + "," + formatFloat(v.Y)
+}
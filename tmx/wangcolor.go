@@ -0,0 +1,88 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WangColor structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#wangcolor
+type WangColor struct {
+
+	// A color that can be used to define the corner and/or edge of a Wang tile. (since 1.5)
+
+	XMLName xml.Name `xml:"wangcolor"`
+
+	// The name of this color.
+	Name string `xml:"name,attr"`
+
+	// The class of this color. (since 1.9)
+	Class string `xml:"class,attr"`
+
+	// The color in #RRGGBB format (example: #c17d11).
+	Color string `xml:"color,attr"`
+
+	// The tile ID of the tile representing this color, -1 for none.
+	Tile int `xml:"tile,attr"`
+
+	// The relative probability that this color is chosen over others in case of multiple options. Defaults to 1.
+	Probability float64 `xml:"probability,attr"`
+
+	// Can contain: <properties>
+	Properties PropertySet `xml:"properties>property"`
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (w *WangColor) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type wangColor WangColor
+
+	// Colors have no tile and compete equally unless the attributes say otherwise.
+	w.Tile = -1
+	w.Probability = 1
+
+	return decoder.DecodeElement((*wangColor)(w), &startElement)
+}
+
+// MarshalXML is called by Marshal to produce the XML element, the tile and probability are always written the way
+// Tiled writes them.
+func (w *WangColor) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.add("name", w.Name)
+	a.str("class", w.Class, "")
+	a.add("color", w.Color)
+	a.add("tile", strconv.Itoa(w.Tile))
+	a.add("probability", formatFloat(w.Probability))
+
+	start = startElement("wangcolor", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = encodeElements(encoder, []interface{}{
+		propertyList(w.Properties),
+	})
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
+func (w *WangColor) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "WangColor:\n")
+	fmt.Fprintf(&b, "\tName:        (%T) %q\n", w.Name, w.Name)
+	fmt.Fprintf(&b, "\tClass:       (%T) %q\n", w.Class, w.Class)
+	fmt.Fprintf(&b, "\tColor:       (%T) %q\n", w.Color, w.Color)
+	fmt.Fprintf(&b, "\tTile:        (%T) %d\n", w.Tile, w.Tile)
+	fmt.Fprintf(&b, "\tProbability: (%T) %f\n", w.Probability, w.Probability)
+
+	for _, property := range w.Properties {
+		fmt.Fprintf(&b, property.String())
+	}
+
+	return b.String()
+}
@@ -22,8 +22,18 @@ type WangEdgeColor struct {
 	// The tile ID of the tile representing this color.
 	Tile int `xml:"tile,attr"`
 
-	// The relative probability that this color is chosen over others in case of multiple options.
-	Probability string `xml:"probability,attr"`
+	// The relative probability that this color is chosen over others in case of multiple options. Defaults to 1.
+	Probability float64 `xml:"probability,attr"`
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element.
+func (w *WangEdgeColor) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type wangEdgeColor WangEdgeColor
+
+	// Colors compete equally unless the probability attribute says otherwise.
+	w.Probability = 1
+
+	return decoder.DecodeElement((*wangEdgeColor)(w), &startElement)
 }
 
 func (w *WangEdgeColor) String() string {
@@ -33,7 +43,7 @@ func (w *WangEdgeColor) String() string {
 	fmt.Fprintf(&b, "\tName:        (%T) %q\n", w.Name, w.Name)
 	fmt.Fprintf(&b, "\tColor:       (%T) %q\n", w.Color, w.Color)
 	fmt.Fprintf(&b, "\tTile:        (%T) %d\n", w.Tile, w.Tile)
-	fmt.Fprintf(&b, "\tProbability: (%T) %q\n", w.Probability, w.Probability)
+	fmt.Fprintf(&b, "\tProbability: (%T) %f\n", w.Probability, w.Probability)
 
 	return b.String()
 }
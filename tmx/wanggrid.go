@@ -0,0 +1,117 @@
+package tmx
+
+// WangGrid holds the colors to paint with a Wang set, for the corners and edges of every cell of a rectangle of tiles.
+// Corners and edges are shared by the cells next to them, so painting a cell also changes the corners and edges of
+// its neighbours. Colors are the index of a Wangset.WangColor plus one, 0 means any color is allowed.
+type WangGrid struct {
+	// The size of the grid in cells.
+	Width  int
+	Height int
+
+	// The colors of the (Width+1) * (Height+1) corners, row by row.
+	corners []int
+
+	// The colors of the Width * (Height+1) edges at the top and bottom of the cells, row by row.
+	hEdges []int
+
+	// The colors of the (Width+1) * Height edges at the left and right of the cells, row by row.
+	vEdges []int
+}
+
+// NewWangGrid returns a grid of width by height cells where every corner and edge allows any color.
+func NewWangGrid(width int, height int) *WangGrid {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+
+	return &WangGrid{
+		Width:   width,
+		Height:  height,
+		corners: make([]int, (width+1)*(height+1)),
+		hEdges:  make([]int, width*(height+1)),
+		vEdges:  make([]int, (width+1)*height),
+	}
+}
+
+// SetCell paints the four corners and four edges of a cell with a color.
+func (g *WangGrid) SetCell(x int, y int, color int) {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return
+	}
+
+	g.SetCorner(x, y, color)
+	g.SetCorner(x+1, y, color)
+	g.SetCorner(x, y+1, color)
+	g.SetCorner(x+1, y+1, color)
+	g.SetHorizontalEdge(x, y, color)
+	g.SetHorizontalEdge(x, y+1, color)
+	g.SetVerticalEdge(x, y, color)
+	g.SetVerticalEdge(x+1, y, color)
+}
+
+// SetCorner sets the color of the corner at the top-left of the cell at x, y. Corners go from 0 to Width and Height.
+func (g *WangGrid) SetCorner(x int, y int, color int) {
+	if x < 0 || y < 0 || x > g.Width || y > g.Height {
+		return
+	}
+	g.corners[y*(g.Width+1)+x] = color
+}
+
+// Corner returns the color of the corner at the top-left of the cell at x, y, 0 outside the grid.
+func (g *WangGrid) Corner(x int, y int) int {
+	if x < 0 || y < 0 || x > g.Width || y > g.Height {
+		return 0
+	}
+	return g.corners[y*(g.Width+1)+x]
+}
+
+// SetHorizontalEdge sets the color of the edge at the top of the cell at x, y. The bottom edges of the last row are at
+// y = Height.
+func (g *WangGrid) SetHorizontalEdge(x int, y int, color int) {
+	if x < 0 || y < 0 || x >= g.Width || y > g.Height {
+		return
+	}
+	g.hEdges[y*g.Width+x] = color
+}
+
+// HorizontalEdge returns the color of the edge at the top of the cell at x, y, 0 outside the grid.
+func (g *WangGrid) HorizontalEdge(x int, y int) int {
+	if x < 0 || y < 0 || x >= g.Width || y > g.Height {
+		return 0
+	}
+	return g.hEdges[y*g.Width+x]
+}
+
+// SetVerticalEdge sets the color of the edge at the left of the cell at x, y. The right edges of the last column are
+// at x = Width.
+func (g *WangGrid) SetVerticalEdge(x int, y int, color int) {
+	if x < 0 || y < 0 || x > g.Width || y >= g.Height {
+		return
+	}
+	g.vEdges[y*(g.Width+1)+x] = color
+}
+
+// VerticalEdge returns the color of the edge at the left of the cell at x, y, 0 outside the grid.
+func (g *WangGrid) VerticalEdge(x int, y int) int {
+	if x < 0 || y < 0 || x > g.Width || y >= g.Height {
+		return 0
+	}
+	return g.vEdges[y*(g.Width+1)+x]
+}
+
+// WangID returns the colors around the cell at x, y as a Wang ID.
+func (g *WangGrid) WangID(x int, y int) WangID {
+	var id WangID
+	id[WangTop] = g.HorizontalEdge(x, y)
+	id[WangTopRight] = g.Corner(x+1, y)
+	id[WangRight] = g.VerticalEdge(x+1, y)
+	id[WangBottomRight] = g.Corner(x+1, y+1)
+	id[WangBottom] = g.HorizontalEdge(x, y+1)
+	id[WangBottomLeft] = g.Corner(x, y+1)
+	id[WangLeft] = g.VerticalEdge(x, y)
+	id[WangTopLeft] = g.Corner(x, y)
+	return id
+}
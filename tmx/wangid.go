@@ -0,0 +1,89 @@
+package tmx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WangID indexes
+const (
+	WangTop         int = 0
+	WangTopRight    int = 1
+	WangRight       int = 2
+	WangBottomRight int = 3
+	WangBottom      int = 4
+	WangBottomLeft  int = 5
+	WangLeft        int = 6
+	WangTopLeft     int = 7
+)
+
+// WangID structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#wangtile
+type WangID [8]int
+
+// The Wang ID is the color of each edge and corner of a Wang tile, clockwise starting with the top edge, in the order
+// of the WangID indexes. Colors are the index of a Wangset.WangColor plus one, 0 means the edge or corner has no color.
+//
+// Since Tiled 1.5 the Wang ID is saved as the 8 colors separated by commas. Before, it was saved as a 32-bit unsigned
+// integer in the format 0xCECECECE, where each C is a corner color and each E is an edge color, from right to left
+// clockwise, starting with the top edge.
+
+// IsEdge reports whether an index of a Wang ID is an edge, the other indexes are corners.
+func IsEdge(index int) bool {
+	return index%2 == 0
+}
+
+// UnmarshalXMLAttr is called by Unmarshal to produce the value from the XML attribute.
+func (id *WangID) UnmarshalXMLAttr(attr xml.Attr) error {
+	v, err := parseWangID(attr.Value)
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
+
+// MarshalXMLAttr is called by Marshal to produce the XML attribute, the colors separated by commas.
+func (id WangID) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: id.String()}, nil
+}
+
+// parseWangID parses a Wang ID saved as colors separated by commas, or as a 32-bit hexadecimal integer.
+func parseWangID(s string) (WangID, error) {
+	var id WangID
+
+	if !strings.Contains(s, ",") {
+		v, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(s), "0x"), 16, 32)
+		if err != nil {
+			return id, fmt.Errorf("error parsing wang id %q: %w", s, err)
+		}
+		for i := range id {
+			id[i] = int(v>>(4*i)) & 0xf
+		}
+		return id, nil
+	}
+
+	colors := strings.Split(s, ",")
+	if len(colors) != len(id) {
+		return id, fmt.Errorf("error parsing wang id %q: found %d colors, not %d", s, len(colors), len(id))
+	}
+
+	for i, color := range colors {
+		v, err := strconv.Atoi(strings.TrimSpace(color))
+		if err != nil {
+			return id, fmt.Errorf("error parsing wang id %q: %w", s, err)
+		}
+		id[i] = v
+	}
+
+	return id, nil
+}
+
+func (id WangID) String() string {
+	colors := make([]string, len(id))
+	for i, color := range id {
+		colors[i] = strconv.Itoa(color)
+	}
+	return strings.Join(colors, ",")
+}
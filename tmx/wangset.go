@@ -3,35 +3,168 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+// Wang set types
+const (
+	WangsetCorner string = "corner"
+	WangsetEdge   string = "edge"
+	WangsetMixed  string = "mixed"
+)
+
 // Wangset structure: https://doc.mapeditor.org/en/stable/reference/tmx-map-format/#wangset
 type Wangset struct {
 
-	// Defines a list of corner colors and a list of edge colors, and any number of Wang tiles using these colors.
+	// Defines a list of colors and any number of Wang tiles using these colors.
 
 	XMLName xml.Name `xml:"wangset"`
 
 	// The name of the Wang set.
 	Name string `xml:"name,attr"`
 
-	// The tile ID of the tile representing this Wang set.
+	// The class of the Wang set. (since 1.9)
+	Class string `xml:"class,attr"`
+
+	// The type of the Wang set: corner, edge or mixed. (since 1.5)
+	Type string `xml:"type,attr"`
+
+	// The tile ID of the tile representing this Wang set, -1 for none.
 	Tile int `xml:"tile,attr"`
 
-	// Can contain: <wangcornercolor>, <wangedgecolor>, <wangtile>
-	WangCornerColor WangCornerColor `xml:"wangcornercolor"`
-	WangEdgeColor   WangEdgeColor   `xml:"wangedgecolor"`
-	WangTile        WangTile        `xml:"wangtile"`
+	// Can contain: <properties>, <wangcolor>, <wangtile>
+	Properties PropertySet  `xml:"properties>property"`
+	WangColor  []*WangColor `xml:"wangcolor"`
+	WangTile   []*WangTile  `xml:"wangtile"`
+
+	// Wang sets saved before Tiled 1.5 have separate corner and edge colors, they are moved to WangColor when the set
+	// is loaded and are not written back.
+	WangCornerColor []*WangCornerColor `xml:"wangcornercolor"`
+	WangEdgeColor   []*WangEdgeColor   `xml:"wangedgecolor"`
+}
+
+// UnmarshalXML is called by Unmarshal to produce the value from the XML element. Wang sets saved before Tiled 1.5 are
+// upgraded to the current format.
+func (w *Wangset) UnmarshalXML(decoder *xml.Decoder, startElement xml.StartElement) error {
+	type wangset Wangset
+
+	w.Tile = -1
+
+	err := decoder.DecodeElement((*wangset)(w), &startElement)
+	if err != nil {
+		return err
+	}
+
+	w.upgrade()
+
+	return nil
+}
+
+// upgrade moves the corner and edge colors of a Wang set saved before Tiled 1.5 to WangColor, the way Tiled upgrades
+// them. The edge colors come first and the corner colors after them, so the corners of the Wang IDs are moved past the
+// edge colors.
+func (w *Wangset) upgrade() {
+	if len(w.WangCornerColor) == 0 && len(w.WangEdgeColor) == 0 {
+		return
+	}
+
+	w.WangColor = w.WangColor[:0]
+	for _, c := range w.WangEdgeColor {
+		w.WangColor = append(w.WangColor, &WangColor{
+			Name:        c.Name,
+			Color:       c.Color,
+			Tile:        c.Tile,
+			Probability: c.Probability,
+		})
+	}
+	for _, c := range w.WangCornerColor {
+		w.WangColor = append(w.WangColor, &WangColor{
+			Name:        c.Name,
+			Color:       c.Color,
+			Tile:        c.Tile,
+			Probability: c.Probability,
+		})
+	}
+
+	for _, tile := range w.WangTile {
+		for i := WangTopRight; i < len(tile.WangID); i += 2 {
+			if tile.WangID[i] != 0 {
+				tile.WangID[i] += len(w.WangEdgeColor)
+			}
+		}
+	}
+
+	if w.Type == "" {
+		switch {
+		case len(w.WangEdgeColor) == 0:
+			w.Type = WangsetCorner
+		case len(w.WangCornerColor) == 0:
+			w.Type = WangsetEdge
+		default:
+			w.Type = WangsetMixed
+		}
+	}
+}
+
+// MarshalXML is called by Marshal to produce the XML element, always in the format of Tiled 1.5 and later.
+func (w *Wangset) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.add("name", w.Name)
+	a.str("class", w.Class, "")
+	a.add("type", w.Type)
+	a.add("tile", strconv.Itoa(w.Tile))
+
+	start = startElement("wangset", a)
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	elements := []interface{}{propertyList(w.Properties)}
+	for _, color := range w.WangColor {
+		elements = append(elements, color)
+	}
+	for _, tile := range w.WangTile {
+		elements = append(elements, tile)
+	}
+
+	err = encodeElements(encoder, elements)
+	if err != nil {
+		return err
+	}
+
+	return encodeEnd(encoder, start)
+}
+
+// Color returns the color of a color index of a Wang ID, nil for 0 and indexes without a color.
+func (w *Wangset) Color(index int) *WangColor {
+	if index < 1 || index > len(w.WangColor) {
+		return nil
+	}
+	return w.WangColor[index-1]
 }
 
 func (w *Wangset) String() string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "Wangset:\n")
-	fmt.Fprintf(&b, w.WangCornerColor.String())
-	fmt.Fprintf(&b, w.WangEdgeColor.String())
-	fmt.Fprintf(&b, w.WangTile.String())
+	fmt.Fprintf(&b, "\tName:  (%T) %q\n", w.Name, w.Name)
+	fmt.Fprintf(&b, "\tClass: (%T) %q\n", w.Class, w.Class)
+	fmt.Fprintf(&b, "\tType:  (%T) %q\n", w.Type, w.Type)
+	fmt.Fprintf(&b, "\tTile:  (%T) %d\n", w.Tile, w.Tile)
+
+	for _, property := range w.Properties {
+		fmt.Fprintf(&b, property.String())
+	}
+
+	for _, color := range w.WangColor {
+		fmt.Fprintf(&b, color.String())
+	}
+
+	for _, tile := range w.WangTile {
+		fmt.Fprintf(&b, tile.String())
+	}
 
 	return b.String()
 }
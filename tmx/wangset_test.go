@@ -0,0 +1,149 @@
+package tmx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestParseWangID(t *testing.T) {
+	tests := []struct {
+		s   string
+		id  WangID
+		err bool
+	}{
+		{"1,2,3,4,5,6,7,8", WangID{1, 2, 3, 4, 5, 6, 7, 8}, false},
+		{"0, 1, 0,2,0,1,0,1", WangID{0, 1, 0, 2, 0, 1, 0, 1}, false},
+		// Before Tiled 1.5 the top edge is in the lowest 4 bits, followed clockwise by the others.
+		{"0x87654321", WangID{1, 2, 3, 4, 5, 6, 7, 8}, false},
+		{"0X00002011", WangID{1, 1, 0, 2, 0, 0, 0, 0}, false},
+		{"20", WangID{0, 2, 0, 0, 0, 0, 0, 0}, false},
+		{"1,2,3", WangID{}, true},
+		{"1,2,3,4,5,6,7,x", WangID{}, true},
+		{"0x123456789", WangID{}, true},
+		{"0xzz", WangID{}, true},
+	}
+
+	for _, test := range tests {
+		id, err := parseWangID(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("parseWangID(%q) error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if !test.err && id != test.id {
+			t.Errorf("parseWangID(%q) = %v, want %v", test.s, id, test.id)
+		}
+	}
+}
+
+func TestWangsetUpgrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		wangset string
+		typ     string
+		colors  []string
+		wangIDs []WangID
+	}{
+		{
+			name: "mixed",
+			wangset: `<wangset name="paths" tile="-1">
+ <wangedgecolor name="road" color="#ff0000" tile="-1" probability="1"/>
+ <wangcornercolor name="grass" color="#00ff00" tile="0" probability="1"/>
+ <wangcornercolor name="sand" color="#0000ff" tile="1" probability="0.5"/>
+ <wangtile tileid="0" wangid="0x00002011"/>
+ <wangtile tileid="1" wangid="0x20202020" hflip="true"/>
+</wangset>`,
+			typ:    WangsetMixed,
+			colors: []string{"road", "grass", "sand"},
+			// Corner colors come after the edge colors.
+			wangIDs: []WangID{{1, 2, 0, 3, 0, 0, 0, 0}, {0, 3, 0, 3, 0, 3, 0, 3}},
+		},
+		{
+			name: "corners",
+			wangset: `<wangset name="terrain" tile="-1">
+ <wangcornercolor name="grass" color="#00ff00" tile="-1" probability="1"/>
+ <wangcornercolor name="water" color="#0000ff" tile="-1" probability="1"/>
+ <wangtile tileid="3" wangid="0x10201020"/>
+</wangset>`,
+			typ:     WangsetCorner,
+			colors:  []string{"grass", "water"},
+			wangIDs: []WangID{{0, 2, 0, 1, 0, 2, 0, 1}},
+		},
+		{
+			name: "edges",
+			wangset: `<wangset name="fences" tile="-1">
+ <wangedgecolor name="fence" color="#ff0000" tile="-1" probability="1"/>
+ <wangtile tileid="2" wangid="0x00000101"/>
+</wangset>`,
+			typ:     WangsetEdge,
+			colors:  []string{"fence"},
+			wangIDs: []WangID{{1, 0, 1, 0, 0, 0, 0, 0}},
+		},
+		{
+			name: "current",
+			wangset: `<wangset name="terrain" type="corner" tile="-1">
+ <wangcolor name="grass" color="#00ff00" tile="-1" probability="1"/>
+ <wangtile tileid="0" wangid="0,1,0,1,0,1,0,1"/>
+</wangset>`,
+			typ:     WangsetCorner,
+			colors:  []string{"grass"},
+			wangIDs: []WangID{{0, 1, 0, 1, 0, 1, 0, 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := &Wangset{}
+			err := xml.Unmarshal([]byte(test.wangset), w)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Type != test.typ {
+				t.Errorf("type %q, want %q", w.Type, test.typ)
+			}
+			var colors []string
+			for _, color := range w.WangColor {
+				colors = append(colors, color.Name)
+			}
+			if strings.Join(colors, ",") != strings.Join(test.colors, ",") {
+				t.Errorf("colors %v, want %v", colors, test.colors)
+			}
+			for i, tile := range w.WangTile {
+				if tile.WangID != test.wangIDs[i] {
+					t.Errorf("tile %d wang id %v, want %v", tile.TileID, tile.WangID, test.wangIDs[i])
+				}
+			}
+
+			// Upgraded sets are written in the current format.
+			var b bytes.Buffer
+			err = xml.NewEncoder(&b).Encode(w)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(b.String(), "wangcornercolor") || strings.Contains(b.String(), "wangedgecolor") ||
+				!strings.Contains(b.String(), `wangid="`+test.wangIDs[0].String()+`"`) {
+				t.Errorf("wang set is not written in the current format:\n%s", b.String())
+			}
+		})
+	}
+}
+
+func TestWangsetUpgradeProbability(t *testing.T) {
+	w := &Wangset{}
+	err := xml.Unmarshal([]byte(`<wangset name="terrain" tile="-1">
+ <wangcornercolor name="grass" color="#00ff00" tile="4" probability="0.25"/>
+</wangset>`), w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := w.Color(1)
+	if c == nil || c.Tile != 4 || c.Probability != 0.25 || c.Color != "#00ff00" {
+		t.Errorf("color 1 is %v, want the upgraded corner color", c)
+	}
+	if w.Color(0) != nil || w.Color(2) != nil {
+		t.Error("colors 0 and 2 are not nil")
+	}
+}
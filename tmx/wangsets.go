@@ -13,7 +13,7 @@ type Wangsets struct {
 	// Contains the list of Wang sets defined for this tileset.
 
 	// Can contain: <wangset>
-	Wangset []Wangset `xml:"wangset"`
+	Wangset []*Wangset `xml:"wangset"`
 }
 
 func (w *Wangsets) String() string {
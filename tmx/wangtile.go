@@ -3,6 +3,7 @@ package tmx
 import (
 	"encoding/xml"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -16,9 +17,41 @@ type WangTile struct {
 	// The tile ID.
 	TileID int `xml:"tileid,attr"`
 
-	// The Wang ID, which is a 32-bit unsigned integer stored in the format 0xCECECECE (where each C is a corner color
-	// and each E is an edge color, from right to left clockwise, starting with the top edge)
-	WangID int `xml:"wangid,attr"`
+	// The Wang ID, saved as 8 colors separated by commas since Tiled 1.5 and as a 32-bit unsigned integer in the format
+	// 0xCECECECE before.
+	WangID WangID `xml:"wangid,attr"`
+
+	// Whether the tile is flipped horizontally, vertically or diagonally. (only before 1.5)
+	HFlip bool `xml:"hflip,attr"`
+	VFlip bool `xml:"vflip,attr"`
+	DFlip bool `xml:"dflip,attr"`
+}
+
+// MarshalXML is called by Marshal to produce the XML element, leaving out attributes that have their default value.
+func (w *WangTile) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	var a attrs
+	a.add("tileid", strconv.Itoa(w.TileID))
+	a.add("wangid", w.WangID.String())
+	a.bool("hflip", w.HFlip, false)
+	a.bool("vflip", w.VFlip, false)
+	a.bool("dflip", w.DFlip, false)
+
+	return encoder.EncodeElement(struct{}{}, startElement("wangtile", a))
+}
+
+// GID returns the global tile ID of the Wang tile in a tileset of a map, with the flip flags of the tile.
+func (w *WangTile) GID(tileset *Tileset) GID {
+	gid := GID(tileset.FirstGID + w.TileID)
+	if w.HFlip {
+		gid |= FlippedHorizontallyFlag
+	}
+	if w.VFlip {
+		gid |= FlippedVerticallyFlag
+	}
+	if w.DFlip {
+		gid |= FlippedDiagonallyFlag
+	}
+	return gid
 }
 
 func (w *WangTile) String() string {
@@ -26,7 +59,10 @@ func (w *WangTile) String() string {
 
 	fmt.Fprintf(&b, "WangTile:\n")
 	fmt.Fprintf(&b, "\tTileID: (%T) %d\n", w.TileID, w.TileID)
-	fmt.Fprintf(&b, "\tWangID: (%T) %d\n", w.WangID, w.WangID)
+	fmt.Fprintf(&b, "\tWangID: (%T) %v\n", w.WangID, w.WangID)
+	fmt.Fprintf(&b, "\tHFlip:  (%T) %t\n", w.HFlip, w.HFlip)
+	fmt.Fprintf(&b, "\tVFlip:  (%T) %t\n", w.VFlip, w.VFlip)
+	fmt.Fprintf(&b, "\tDFlip:  (%T) %t\n", w.DFlip, w.DFlip)
 
 	return b.String()
 }